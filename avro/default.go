@@ -0,0 +1,158 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+// defaultValue validates a default value decoded from JSON against the type, and returns it as a typed value.
+// int and long are int64, float and double are float64, and bytes and fixed are strings of the code points.
+// Defaults of unions are of the first type, and record defaults are filled with defaults of their fields.
+func (n *NamedTypes) defaultValue(t AvroType, v interface{}, namespace string) (interface{}, error) {
+	switch {
+	case t.PrimitiveType != nil:
+		return primitiveDefaultValue(string(*t.PrimitiveType), v)
+
+	case t.LogicalType != nil:
+		return primitiveDefaultValue(t.LogicalType.Type, v)
+
+	case t.RecordType != nil:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, invalidDefaultError(v)
+		}
+		ns := NamespaceOf(FullName(t.RecordType.Name, t.RecordType.Namespace, namespace))
+		record := make(map[string]interface{}, len(t.RecordType.Fields))
+		for _, f := range t.RecordType.Fields {
+			fv, ok := m[f.Name]
+			if !ok {
+				if !f.HasDefault {
+					return nil, fmt.Errorf("default of record %s doesn't have field %s: %w", t.RecordType.Name, f.Name, ErrInvalidAvroSchema)
+				}
+				// already validated with the record type
+				record[f.Name] = f.Default
+				continue
+			}
+			vv, err := n.defaultValue(f.Type, fv, ns)
+			if err != nil {
+				return nil, err
+			}
+			record[f.Name] = vv
+		}
+		return record, nil
+
+	case t.EnumsType != nil:
+		if s, ok := v.(string); ok && hasSymbol(t.EnumsType.Symbols, s) {
+			return s, nil
+		}
+		return nil, invalidDefaultError(v)
+
+	case t.FixedType != nil:
+		s, err := primitiveDefaultValue(AvroPrimitiveType_Bytes, v)
+		if err != nil {
+			return nil, err
+		}
+		if int64(utf8.RuneCountInString(s.(string))) != t.FixedType.Size {
+			return nil, invalidDefaultError(v)
+		}
+		return s, nil
+
+	case t.ArrayType != nil:
+		vs, ok := v.([]interface{})
+		if !ok {
+			return nil, invalidDefaultError(v)
+		}
+		array := make([]interface{}, len(vs))
+		for i, e := range vs {
+			ee, err := n.defaultValue(t.ArrayType.Items, e, namespace)
+			if err != nil {
+				return nil, err
+			}
+			array[i] = ee
+		}
+		return array, nil
+
+	case t.MapsType != nil:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, invalidDefaultError(v)
+		}
+		values := make(map[string]interface{}, len(m))
+		for k, e := range m {
+			ee, err := n.defaultValue(t.MapsType.Values, e, namespace)
+			if err != nil {
+				return nil, err
+			}
+			values[k] = ee
+		}
+		return values, nil
+
+	case t.UnionType != nil:
+		if len(*t.UnionType) == 0 {
+			return nil, invalidDefaultError(v)
+		}
+		return n.defaultValue((*t.UnionType)[0], v, namespace)
+
+	case t.DefinedType != nil:
+		dt, fullName, err := n.Lookup(string(*t.DefinedType), namespace)
+		if err != nil {
+			return nil, err
+		}
+		return n.defaultValue(dt, v, NamespaceOf(fullName))
+	}
+
+	return nil, invalidDefaultError(v)
+}
+
+func primitiveDefaultValue(t string, v interface{}) (interface{}, error) {
+	switch t {
+	case AvroPrimitiveType_Null:
+		if v == nil {
+			return nil, nil
+		}
+
+	case AvroPrimitiveType_Boolean:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+
+	case AvroPrimitiveType_Int, AvroPrimitiveType_Long:
+		if n, ok := v.(json.Number); ok {
+			i, err := n.Int64()
+			if err == nil && (t == AvroPrimitiveType_Long || (i >= math.MinInt32 && i <= math.MaxInt32)) {
+				return i, nil
+			}
+		}
+
+	case AvroPrimitiveType_Float, AvroPrimitiveType_Double:
+		if n, ok := v.(json.Number); ok {
+			if f, err := n.Float64(); err == nil {
+				return f, nil
+			}
+		}
+
+	case AvroPrimitiveType_String:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+
+	case AvroPrimitiveType_Bytes:
+		// bytes are strings whose code points are 0-255, which are kept to be written in JSON
+		if s, ok := v.(string); ok {
+			for _, r := range s {
+				if r > 0xff {
+					return nil, fmt.Errorf("invalid default %q of %s: %w", s, t, ErrInvalidAvroSchema)
+				}
+			}
+			return s, nil
+		}
+	}
+
+	return nil, fmt.Errorf("invalid default %v of %s: %w", v, t, ErrInvalidAvroSchema)
+}
+
+func invalidDefaultError(v interface{}) error {
+	return fmt.Errorf("invalid default %v: %w", v, ErrInvalidAvroSchema)
+}
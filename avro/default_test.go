@@ -0,0 +1,69 @@
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewNamedTypes_Defaults(t *testing.T) {
+	cases := []struct {
+		field    string
+		expected interface{}
+		err      error
+	}{
+		{field: `{"name": "f", "type": "null", "default": null}`, expected: nil},
+		{field: `{"name": "f", "type": "boolean", "default": true}`, expected: true},
+		{field: `{"name": "f", "type": "int", "default": -1}`, expected: int64(-1)},
+		{field: `{"name": "f", "type": "long", "default": 9007199254740993}`, expected: int64(9007199254740993)},
+		{field: `{"name": "f", "type": "double", "default": 1.5}`, expected: 1.5},
+		{field: `{"name": "f", "type": "float", "default": 1}`, expected: 1.0},
+		{field: `{"name": "f", "type": "string", "default": "foo"}`, expected: "foo"},
+		{field: `{"name": "f", "type": "bytes", "default": "ÿ\u0000"}`, expected: "\u00ff\x00"},
+		{field: `{"name": "f", "type": {"type": "fixed", "name": "F", "size": 2}, "default": "ab"}`, expected: "ab"},
+		{field: `{"name": "f", "type": {"type": "fixed", "name": "F", "size": 2}, "default": "ÿ\u0000"}`, expected: "\u00ff\x00"},
+		{field: `{"name": "f", "type": {"type": "enum", "name": "E", "symbols": ["A", "B"]}, "default": "B"}`, expected: "B"},
+		{field: `{"name": "f", "type": {"type": "array", "items": "long"}, "default": [1, 2]}`, expected: []interface{}{int64(1), int64(2)}},
+		{field: `{"name": "f", "type": {"type": "map", "values": "string"}, "default": {"k": "v"}}`, expected: map[string]interface{}{"k": "v"}},
+		{field: `{"name": "f", "type": ["null", "string"], "default": null}`, expected: nil},
+		{field: `{"name": "f", "type": ["string", "null"], "default": "foo"}`, expected: "foo"},
+		{field: `{"name": "f", "type": {"type": "long", "logicalType": "timestamp-millis"}, "default": 0}`, expected: int64(0)},
+		// fields missing in the default are filled with their defaults
+		{
+			field:    `{"name": "f", "type": {"type": "record", "name": "R", "fields": [{"name": "a", "type": "int", "default": 1}, {"name": "b", "type": "string"}]}, "default": {"b": "x"}}`,
+			expected: map[string]interface{}{"a": int64(1), "b": "x"},
+		},
+
+		{field: `{"name": "f", "type": "int", "default": 2147483648}`, err: ErrInvalidAvroSchema},
+		{field: `{"name": "f", "type": "int", "default": 1.5}`, err: ErrInvalidAvroSchema},
+		{field: `{"name": "f", "type": "string", "default": 1}`, err: ErrInvalidAvroSchema},
+		{field: `{"name": "f", "type": "null", "default": "null"}`, err: ErrInvalidAvroSchema},
+		{field: `{"name": "f", "type": "bytes", "default": "Ā"}`, err: ErrInvalidAvroSchema},
+		{field: `{"name": "f", "type": {"type": "fixed", "name": "F", "size": 2}, "default": "a"}`, err: ErrInvalidAvroSchema},
+		{field: `{"name": "f", "type": {"type": "enum", "name": "E", "symbols": ["A"]}, "default": "C"}`, err: ErrInvalidAvroSchema},
+		{field: `{"name": "f", "type": {"type": "array", "items": "long"}, "default": ["a"]}`, err: ErrInvalidAvroSchema},
+		{field: `{"name": "f", "type": ["null", "string"], "default": "foo"}`, err: ErrInvalidAvroSchema},
+		{field: `{"name": "f", "type": {"type": "record", "name": "R", "fields": [{"name": "a", "type": "int"}]}, "default": {}}`, err: ErrInvalidAvroSchema},
+	}
+
+	for _, c := range cases {
+		var rt RecordType
+		if err := json.Unmarshal([]byte(`{"type": "record", "name": "Root", "fields": [`+c.field+`]}`), &rt); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := NewNamedTypes(rt)
+		if !errors.Is(err, c.err) {
+			t.Errorf("expected: %v, but actual: %v\n", c.err, err)
+			continue
+		}
+
+		if err == nil && !reflect.DeepEqual(rt.Fields[0].Default, c.expected) {
+			t.Errorf("expected: %#v, but actual: %#v\n", c.expected, rt.Fields[0].Default)
+		}
+		if !rt.Fields[0].HasDefault {
+			t.Errorf("expected: %v, but actual: %v\n", true, rt.Fields[0].HasDefault)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package avro
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUndefinedAvroType = errors.New("undefined avro type")
+	ErrRecursiveAvroType = errors.New("recursive avro type")
+)
+
+// NamedTypes is a symbol table of named types, records, enums and fixed, by their full names.
+type NamedTypes struct {
+	types map[string]AvroType
+}
+
+// NewNamedTypes collects named types defined in the record recursively, and checks that all type references are
+// resolved to types defined before them. Types referring to themselves are rejected because they can't be
+// converted to columns. Default values of fields are validated and replaced with typed values.
+func NewNamedTypes(rt RecordType) (*NamedTypes, error) {
+	n := &NamedTypes{
+		types: make(map[string]AvroType),
+	}
+
+	if err := n.collect(AvroType{RecordType: &rt}, "", nil); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// Lookup resolves a type name referred in the namespace, and returns the type with its full name.
+// Names without dots are looked up in the namespace first, and then in the null namespace.
+func (n *NamedTypes) Lookup(name, namespace string) (AvroType, string, error) {
+	candidates := []string{name}
+	if !strings.Contains(name, ".") && namespace != "" {
+		candidates = []string{namespace + "." + name, name}
+	}
+
+	for _, c := range candidates {
+		if t, ok := n.types[c]; ok {
+			return t, c, nil
+		}
+	}
+
+	return AvroType{}, "", fmt.Errorf("type %s in namespace %q: %w", name, namespace, ErrUndefinedAvroType)
+}
+
+func (n *NamedTypes) collect(t AvroType, namespace string, enclosing []string) error {
+	switch {
+	case t.RecordType != nil:
+		fullName, err := n.register(t, t.RecordType.Name, t.RecordType.Namespace, namespace)
+		if err != nil {
+			return err
+		}
+		enclosing = append(enclosing, fullName)
+		for i, f := range t.RecordType.Fields {
+			if err := n.collect(f.Type, NamespaceOf(fullName), enclosing); err != nil {
+				return err
+			}
+			if f.HasDefault {
+				d, err := n.defaultValue(f.Type, f.Default, NamespaceOf(fullName))
+				if err != nil {
+					return fmt.Errorf("field %s: %w", f.Name, err)
+				}
+				t.RecordType.Fields[i].Default = d
+			}
+		}
+
+	case t.EnumsType != nil:
+		if d := t.EnumsType.Default; d != "" && !hasSymbol(t.EnumsType.Symbols, d) {
+			return fmt.Errorf("default %s of enum %s isn't a symbol: %w", d, t.EnumsType.Name, ErrInvalidAvroSchema)
+		}
+		_, err := n.register(t, t.EnumsType.Name, t.EnumsType.Namespace, namespace)
+		return err
+
+	case t.FixedType != nil:
+		_, err := n.register(t, t.FixedType.Name, t.FixedType.Namespace, namespace)
+		return err
+
+	case t.ArrayType != nil:
+		return n.collect(t.ArrayType.Items, namespace, enclosing)
+
+	case t.MapsType != nil:
+		return n.collect(t.MapsType.Values, namespace, enclosing)
+
+	case t.UnionType != nil:
+		for _, tt := range *t.UnionType {
+			if err := n.collect(tt, namespace, enclosing); err != nil {
+				return err
+			}
+		}
+
+	case t.DefinedType != nil:
+		_, fullName, err := n.Lookup(string(*t.DefinedType), namespace)
+		if err != nil {
+			return err
+		}
+		for _, e := range enclosing {
+			if e == fullName {
+				return fmt.Errorf("type %s refers itself: %w", fullName, ErrRecursiveAvroType)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (n *NamedTypes) register(t AvroType, name, namespace, enclosingNamespace string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("named type without name: %w", ErrInvalidAvroSchema)
+	}
+
+	fullName := FullName(name, namespace, enclosingNamespace)
+	if _, ok := n.types[fullName]; ok {
+		return "", fmt.Errorf("type %s is defined twice: %w", fullName, ErrInvalidAvroSchema)
+	}
+	n.types[fullName] = t
+
+	return fullName, nil
+}
+
+// FullName returns the full name of a named type by the namespace rules. Names with dots are already full names,
+// and others are qualified by the namespace attribute, or the namespace of the most tightly enclosing named type.
+func FullName(name, namespace, enclosingNamespace string) string {
+	if strings.Contains(name, ".") {
+		return name
+	}
+	if namespace == "" {
+		namespace = enclosingNamespace
+	}
+	if namespace == "" {
+		return name
+	}
+
+	return namespace + "." + name
+}
+
+// NamespaceOf returns the namespace part of a full name.
+func NamespaceOf(fullName string) string {
+	if i := strings.LastIndex(fullName, "."); i >= 0 {
+		return fullName[:i]
+	}
+
+	return ""
+}
+
+func hasSymbol(symbols []string, symbol string) bool {
+	for _, s := range symbols {
+		if s == symbol {
+			return true
+		}
+	}
+
+	return false
+}
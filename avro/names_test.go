@@ -0,0 +1,192 @@
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestNewNamedTypes(t *testing.T) {
+	cases := []struct {
+		schema string
+		names  []string
+		err    error
+	}{
+		// namespaces are inherited from enclosing types, and overwritten by attributes or full names
+		{
+			schema: `
+{
+  "type": "record",
+  "name": "Root",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "child", "type": {"type": "record", "name": "Child", "fields": [
+      {"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["A", "B"]}}
+    ]}},
+    {"name": "other", "type": {"type": "record", "name": "Other", "namespace": "org.example", "fields": [
+      {"name": "hash", "type": {"type": "fixed", "name": "Hash", "size": 16}}
+    ]}},
+    {"name": "full", "type": {"type": "enum", "name": "net.example.Full", "namespace": "ignored", "symbols": ["C"]}},
+    {"name": "children", "type": {"type": "array", "items": "Child"}},
+    {"name": "hashes", "type": {"type": "map", "values": "org.example.Hash"}},
+    {"name": "nullable", "type": ["null", "com.example.Status"]}
+  ]
+}
+`,
+			names: []string{"com.example.Root", "com.example.Child", "com.example.Status", "org.example.Other", "org.example.Hash", "net.example.Full"},
+			err:   nil,
+		},
+
+		// types in the null namespace are also visible
+		{
+			schema: `
+{
+  "type": "record",
+  "name": "Root",
+  "fields": [
+    {"name": "a", "type": {"type": "fixed", "name": "Id", "size": 4}},
+    {"name": "b", "type": {"type": "record", "name": "Nested", "namespace": "ns", "fields": [
+      {"name": "id", "type": "Id"}
+    ]}}
+  ]
+}
+`,
+			names: []string{"Root", "Id", "ns.Nested"},
+			err:   nil,
+		},
+
+		// referred before definition
+		{
+			schema: `
+{
+  "type": "record",
+  "name": "Root",
+  "fields": [
+    {"name": "a", "type": "Child"},
+    {"name": "b", "type": {"type": "record", "name": "Child", "fields": []}}
+  ]
+}
+`,
+			err: ErrUndefinedAvroType,
+		},
+
+		// hidden by the namespace
+		{
+			schema: `
+{
+  "type": "record",
+  "name": "Root",
+  "fields": [
+    {"name": "a", "type": {"type": "record", "name": "Child", "namespace": "ns", "fields": []}},
+    {"name": "b", "type": "Child"}
+  ]
+}
+`,
+			err: ErrUndefinedAvroType,
+		},
+
+		// defined twice
+		{
+			schema: `
+{
+  "type": "record",
+  "name": "Root",
+  "fields": [
+    {"name": "a", "type": {"type": "enum", "name": "Status", "symbols": ["A"]}},
+    {"name": "b", "type": {"type": "enum", "name": "Status", "symbols": ["B"]}}
+  ]
+}
+`,
+			err: ErrInvalidAvroSchema,
+		},
+
+		// enum default which isn't a symbol
+		{
+			schema: `
+{
+  "type": "record",
+  "name": "Root",
+  "fields": [
+    {"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["A", "B"], "default": "C"}}
+  ]
+}
+`,
+			err: ErrInvalidAvroSchema,
+		},
+
+		// recursive
+		{
+			schema: `
+{
+  "type": "record",
+  "name": "LinkedList",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "value", "type": "long"},
+    {"name": "next", "type": ["null", "LinkedList"]}
+  ]
+}
+`,
+			err: ErrRecursiveAvroType,
+		},
+
+		// recursive via another record
+		{
+			schema: `
+{
+  "type": "record",
+  "name": "Tree",
+  "fields": [
+    {"name": "children", "type": {"type": "array", "items": {"type": "record", "name": "Node", "fields": [
+      {"name": "tree", "type": "Tree"}
+    ]}}}
+  ]
+}
+`,
+			err: ErrRecursiveAvroType,
+		},
+	}
+
+	for _, c := range cases {
+		var rt RecordType
+		if err := json.Unmarshal([]byte(c.schema), &rt); err != nil {
+			t.Fatal(err)
+		}
+
+		actual, err := NewNamedTypes(rt)
+		if !errors.Is(err, c.err) {
+			t.Errorf("expected: %v, but actual: %v\n", c.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		if len(actual.types) != len(c.names) {
+			t.Errorf("expected: %v, but actual: %v\n", c.names, actual.types)
+		}
+		for _, n := range c.names {
+			if _, _, err := actual.Lookup(n, ""); err != nil {
+				t.Errorf("expected: %v, but actual: %v\n", n, err)
+			}
+		}
+	}
+}
+
+func TestFullName(t *testing.T) {
+	cases := []struct {
+		name, namespace, enclosing string
+		expected                   string
+	}{
+		{name: "A", expected: "A"},
+		{name: "A", enclosing: "x", expected: "x.A"},
+		{name: "A", namespace: "y", enclosing: "x", expected: "y.A"},
+		{name: "z.A", namespace: "y", enclosing: "x", expected: "z.A"},
+	}
+
+	for _, c := range cases {
+		if actual := FullName(c.name, c.namespace, c.enclosing); actual != c.expected {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
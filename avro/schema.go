@@ -7,6 +7,7 @@
 package avro
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 )
@@ -34,6 +35,13 @@ const (
 	AvroLogicalType_TimestampMillis = "timestamp-millis"
 	AvroLogicalType_TimestampMicros = "timestamp-micros"
 	AvroLogicalType_Duration        = "duration"
+
+	// Avro 1.10 and later
+	AvroLogicalType_UUID                 = "uuid"
+	AvroLogicalType_LocalTimestampMillis = "local-timestamp-millis"
+	AvroLogicalType_LocalTimestampMicros = "local-timestamp-micros"
+	AvroLogicalType_TimestampNanos       = "timestamp-nanos"
+	AvroLogicalType_LocalTimestampNanos  = "local-timestamp-nanos"
 )
 
 var (
@@ -50,6 +58,8 @@ var (
 		AvroPrimitiveType_String,
 	}
 
+	recordFieldAttributes = []string{"name", "doc", "type", "default", "order", "aliases"}
+
 	avroValidTypesForLogicalType = map[string][]string{
 		AvroLogicalType_Decimal:         {AvroPrimitiveType_Bytes, AvroComplexType_Fixed},
 		AvroLogicalType_Date:            {AvroPrimitiveType_Int},
@@ -58,6 +68,12 @@ var (
 		AvroLogicalType_TimestampMillis: {AvroPrimitiveType_Long},
 		AvroLogicalType_TimestampMicros: {AvroPrimitiveType_Long},
 		AvroLogicalType_Duration:        {AvroComplexType_Fixed},
+
+		AvroLogicalType_UUID:                 {AvroPrimitiveType_String, AvroComplexType_Fixed},
+		AvroLogicalType_LocalTimestampMillis: {AvroPrimitiveType_Long},
+		AvroLogicalType_LocalTimestampMicros: {AvroPrimitiveType_Long},
+		AvroLogicalType_TimestampNanos:       {AvroPrimitiveType_Long},
+		AvroLogicalType_LocalTimestampNanos:  {AvroPrimitiveType_Long},
 	}
 )
 
@@ -76,9 +92,17 @@ type RecordField struct {
 	Name    string   `json:"name"`
 	Doc     string   `json:"doc"`
 	Type    AvroType `json:"type"`
-	Default string   `json:"default"`
 	Order   string   `json:"order"`
 	Aliases []string `json:"aliases"`
+
+	// Default is the default value as a typed JSON value, numbers are json.Number until NewNamedTypes validates it.
+	// HasDefault distinguishes a null default from no default.
+	Default    interface{} `json:"default"`
+	HasDefault bool        `json:"-"`
+
+	// Properties holds additional attributes not defined in the spec.
+	// String values are kept as is, and others are kept as JSON texts.
+	Properties map[string]string `json:"-"`
 }
 
 type EnumsType struct {
@@ -88,6 +112,8 @@ type EnumsType struct {
 	Aliases   []string `json:"aliases"`
 	Doc       string   `json:"doc"`
 	Symbols   []string `json:"symbols"`
+	// Default is the symbol used for symbols unknown to the schema, which is optional
+	Default string `json:"default,omitempty"`
 }
 
 type ArrayType struct {
@@ -108,6 +134,9 @@ type FixedType struct {
 	Namespace string   `json:"namespace"`
 	Aliases   []string `json:"aliases"`
 	Size      int64    `json:"size"`
+
+	// LogicalType annotates the fixed like uuid, which is ignored if it's invalid for the size
+	LogicalType string `json:"logicalType,omitempty"`
 }
 
 type LogicalType struct {
@@ -134,6 +163,57 @@ type AvroType struct {
 	DefinedType   *DefinedType
 }
 
+// UnmarshalJSON gets the record field and its additional properties.
+func (f *RecordField) UnmarshalJSON(b []byte) error {
+	type recordField RecordField
+	var rf recordField
+	if err := json.Unmarshal(b, &rf); err != nil {
+		return err
+	}
+
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(b, &attrs); err != nil {
+		return err
+	}
+	for k, v := range attrs {
+		if k == "default" {
+			d := json.NewDecoder(bytes.NewReader(v))
+			d.UseNumber()
+			if err := d.Decode(&rf.Default); err != nil {
+				return err
+			}
+			rf.HasDefault = true
+		}
+		if isRecordFieldAttribute(k) {
+			continue
+		}
+
+		if rf.Properties == nil {
+			rf.Properties = make(map[string]string)
+		}
+		var str string
+		if err := json.Unmarshal(v, &str); err == nil {
+			rf.Properties[k] = str
+		} else {
+			rf.Properties[k] = string(v)
+		}
+	}
+
+	*f = RecordField(rf)
+
+	return nil
+}
+
+func isRecordFieldAttribute(k string) bool {
+	for _, a := range recordFieldAttributes {
+		if k == a {
+			return true
+		}
+	}
+
+	return false
+}
+
 // UnmarshalJSON gets actual Avro type(s) recursively.
 func (t *AvroType) UnmarshalJSON(b []byte) error {
 	var pt PrimitiveType
@@ -198,10 +278,17 @@ func (t *AvroType) UnmarshalJSON(b []byte) error {
 		}
 	}
 
+	// primitive types can be also objects with properties like {"type": "string", "avro.java.string": "String"},
+	// and unknown logical types are ignored
+	if err := json.Unmarshal(b, &lt); err == nil && isValidPrimitiveType(PrimitiveType(lt.Type)) {
+		pt := PrimitiveType(lt.Type)
+		t.PrimitiveType = &pt
+		return nil
+	}
+
 	var dt DefinedType
 	if err := json.Unmarshal(b, &dt); err == nil {
-		// NOTE no validation to ensure the type name was defined
-		// TODO check the type name is already defined
+		// references are resolved by NamedTypes after the whole schema is parsed
 		t.DefinedType = &dt
 		return nil
 	}
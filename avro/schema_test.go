@@ -2,6 +2,7 @@ package avro
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -574,6 +575,15 @@ func TestUnmarshal(t *testing.T) {
         "type": "long",
         "logicalType": "timestamp-micros"
       }
+    },
+    {
+      "name": "duration",
+      "type": {
+        "type": "fixed",
+        "name": "Duration",
+        "size": 12,
+        "logicalType": "duration"
+      }
     }
   ]
 }
@@ -638,7 +648,50 @@ func TestUnmarshal(t *testing.T) {
 							},
 						},
 					},
-					// TODO duration
+					{
+						Name: "duration",
+						Type: AvroType{
+							FixedType: &FixedType{
+								Type:        AvroComplexType_Fixed,
+								Name:        "Duration",
+								Size:        12,
+								LogicalType: AvroLogicalType_Duration,
+							},
+						},
+					},
+				},
+			},
+			err: nil,
+		},
+
+		// Primitives in object form
+		{
+			schema: `
+{
+  "type": "record",
+  "name": "ObjectPrimitives",
+  "fields" : [
+    {"name": "string", "type": {"type": "string", "avro.java.string": "String"}},
+    {"name": "long",   "type": {"type": "long", "logicalType": "unknown"}}
+  ]
+}
+`,
+			expected: RecordType{
+				Type: AvroComplexType_Record,
+				Name: "ObjectPrimitives",
+				Fields: []RecordField{
+					{
+						Name: "string",
+						Type: AvroType{
+							PrimitiveType: ToPrimitiveType("string"),
+						},
+					},
+					{
+						Name: "long",
+						Type: AvroType{
+							PrimitiveType: ToPrimitiveType("long"),
+						},
+					},
 				},
 			},
 			err: nil,
@@ -658,3 +711,33 @@ func TestUnmarshal(t *testing.T) {
 		}
 	}
 }
+
+func TestUnmarshalRecordFieldProperties(t *testing.T) {
+	cases := []struct {
+		field    string
+		expected map[string]string
+	}{
+		{
+			field:    `{"name": "f", "type": "string", "doc": "a field"}`,
+			expected: nil,
+		},
+		{
+			field: `{"name": "f", "type": "string", "comment": "foo", "fixedwidth": {"start": 0, "length": 4}}`,
+			expected: map[string]string{
+				"comment":    "foo",
+				"fixedwidth": `{"start": 0, "length": 4}`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		var actual RecordField
+		if err := json.Unmarshal([]byte(c.field), &actual); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(actual.Properties, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual.Properties)
+		}
+	}
+}
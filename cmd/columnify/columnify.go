@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/reproio/columnify/columnifier"
+	"github.com/reproio/columnify/record"
+	"github.com/reproio/columnify/schema"
 )
 
 func printUsage() {
@@ -19,6 +22,28 @@ func printUsage() {
 	flag.PrintDefaults()
 }
 
+// keyValueFlag accumulates repeated key=value flags.
+type keyValueFlag map[string]string
+
+func (f keyValueFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for k, v := range f {
+		pairs = append(pairs, k+"="+v)
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (f keyValueFlag) Set(s string) error {
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return fmt.Errorf("expected key=value, but actual %s", s)
+	}
+	f[kv[0]] = kv[1]
+
+	return nil
+}
+
 func columnify(c columnifier.Columnifier, files []string) (err error) {
 	defer func() {
 		if cerr := c.Close(); cerr != nil {
@@ -39,29 +64,113 @@ func columnify(c columnifier.Columnifier, files []string) (err error) {
 func main() {
 	flag.Usage = printUsage
 
-	schemaType := flag.String("schemaType", "", "schema type, [avro|bigquery]")
+	schemaType := flag.String("schemaType", "", "schema type, [avro|bigquery|sqlite]; sqlite derives the schema from the database given as schemaFile")
 	schemaFile := flag.String("schemaFile", "", "path to schema file")
-	recordType := flag.String("recordType", "jsonl", "record data format type, [avro|csv|jsonl|ltsv|msgpack|tsv]")
+	recordType := flag.String("recordType", "jsonl", "record data format type, [avro|avro_single_object|avro_confluent|bson|cbor|csv|fixedwidth|influx|ion|json|jsonl|ltsv|msgpack|ods|prometheus|regex|sqlite|tsv|xlsx|xml]")
 	output := flag.String("output", "", "path to output file; default: stdout")
 
+	// schema registry options, used instead of schemaFile
+	schemaRegistryURL := flag.String("schemaRegistryURL", "", "URL of Confluent-compatible schema registry to fetch the schema instead of schemaFile")
+	schemaRegistrySubject := flag.String("schemaRegistrySubject", "", "subject of the schema in the schema registry")
+	schemaRegistryVersion := flag.String("schemaRegistryVersion", "latest", "version of the subject in the schema registry, default: latest")
+	schemaRegistryId := flag.Int("schemaRegistryId", 0, "id of the schema in the schema registry, used instead of the subject")
+	schemaRegistryCacheDir := flag.String("schemaRegistryCacheDir", "", "path to directory to cache fetched schemas for offline reruns")
+
 	// parquet specific options
 	parquetPageSize := flag.Int64("parquetPageSize", 8*1024, "parquet file page size, default: 8kB")
 	parquetRowGroupSize := flag.Int64("parquetRowGroupSize", 128*1024*1024, "parquet file row group size, default: 128MB")
 	parquetCompressionCodec := flag.String("parquetCompressionCodec", "SNAPPY", "parquet compression codec, default: SNAPPY")
+	parquetColumnOptionsFile := flag.String("parquetColumnOptionsFile", "", "path to JSON of column specific codec, dictionary and encoding keyed by column paths; default: parquet properties of schema fields")
+	parquetPageIndex := flag.Bool("parquetPageIndex", false, "write the page index (ColumnIndex and OffsetIndex) for readers to skip pages")
+	bloomFilterColumns := flag.String("bloomFilterColumns", "", "comma separated column paths to write Bloom filters, e.g. user_id,session_id")
+	parquetLegacyTypes := flag.Bool("parquetLegacyTypes", false, "write only ConvertedType annotations without LogicalType for old readers like Hive 2")
+	parquetLegacyLists := flag.Bool("parquetLegacyLists", false, "write lists as repeated fields, the legacy two-level encoding, instead of the standard three-level one")
+	parquetInt96Timestamps := flag.Bool("parquetInt96Timestamps", false, "write timestamps as INT96 for old Hive and Impala")
+	int96TimestampColumns := flag.String("int96TimestampColumns", "", "comma separated column paths of timestamps written as INT96, e.g. created_at,updated_at")
+	parquetCreatedBy := flag.String("parquetCreatedBy", "", "application name written as created_by in the footer; default: columnify with its version")
+	metadata := keyValueFlag{}
+	flag.Var(metadata, "metadata", "key=value pair added to parquet key-value metadata, can be repeated")
+
+	// record specific options
+	jsonPointer := flag.String("jsonPointer", "", "JSON pointer to the array of records for json record type, e.g. /Records")
+
+	fillDefaults := flag.Bool("fillDefaults", false, "fill fields missing in records with default values of the schema, instead of failing or writing nulls")
+	binaryEncodings := keyValueFlag{}
+	flag.Var(binaryEncodings, "binaryEncoding", "path=encoding pair of string values of a binary column, [raw|base64|hex], e.g. payload=base64, can be repeated; default: raw")
+
+	avroSchemaDir := flag.String("avroSchemaDir", "", "path to directory of writer schemas (.avsc) for framed avro record types; keyed by fingerprint or numeric file name as schema id")
+
+	regexPattern := flag.String("regexPattern", "", "pattern for regex record type; builtin [apache_common|apache_combined|nginx|syslog_rfc3164|syslog_rfc5424] or regular expression with grok references like %{INT:status}")
+	regexPatternsFile := flag.String("regexPatternsFile", "", "path to additional grok pattern definitions for regex record type")
+
+	fixedWidthSpecFile := flag.String("fixedWidthSpecFile", "", "path to JSON column specs for fixedwidth record type; default: fixedwidth properties of schema fields")
+
+	xmlRecordPath := flag.String("xmlRecordPath", "", "absolute path of record elements for xml record type, e.g. /feed/entry")
+
+	sheet := flag.String("sheet", "", "sheet name or 0-origin index for xlsx and ods record types; default: the first sheet")
+
+	sqliteTable := flag.String("sqliteTable", "", "table to read for sqlite record type; default: the only table in the database")
+	sqliteQuery := flag.String("sqliteQuery", "", "SQL query to read records for sqlite record type, used instead of sqliteTable")
+
+	influxPrecision := flag.String("influxPrecision", "ns", "timestamp precision for influx record type, [ns|us|ms|s]")
 
 	flag.Parse()
 
 	files := flag.Args()
 
-	if *schemaType == "" || *schemaFile == "" || len(files) == 0 {
+	if *recordType == record.RecordTypeSqlite && *schemaType == "" && *schemaFile == "" && *schemaRegistryURL == "" && len(files) > 0 {
+		// derive the schema from column declarations of the input database
+		*schemaType = schema.SchemaTypeSqlite
+		*schemaFile = files[0]
+	}
+
+	if *schemaType == "" || (*schemaFile == "" && *schemaRegistryURL == "") || len(files) == 0 {
 		printUsage()
 		log.Fatalf("Missed required parameter(s)")
 	}
+	if *schemaFile != "" && *schemaRegistryURL != "" {
+		printUsage()
+		log.Fatalf("-schemaFile and -schemaRegistryURL are mutually exclusive")
+	}
 
 	config, err := columnifier.NewConfig(*parquetPageSize, *parquetRowGroupSize, *parquetCompressionCodec)
 	if err != nil {
 		log.Fatalf("Failed to init: %v\n", err)
 	}
+	if *parquetCreatedBy != "" {
+		config.Parquet.CreatedBy = *parquetCreatedBy
+	}
+	config.Parquet.Metadata = metadata
+	config.Parquet.ColumnOptionsFile = *parquetColumnOptionsFile
+	config.Parquet.PageIndex = *parquetPageIndex
+	config.Parquet.LegacyTypes = *parquetLegacyTypes
+	config.Parquet.LegacyLists = *parquetLegacyLists
+	if *bloomFilterColumns != "" {
+		config.Parquet.BloomFilterColumns = strings.Split(*bloomFilterColumns, ",")
+	}
+	config.Parquet.Int96Timestamps = *parquetInt96Timestamps
+	if *int96TimestampColumns != "" {
+		config.Parquet.Int96TimestampColumns = strings.Split(*int96TimestampColumns, ",")
+	}
+	config.Record.JsonPointer = *jsonPointer
+	config.Record.AvroSchemaDir = *avroSchemaDir
+	config.Record.RegexPattern = *regexPattern
+	config.Record.RegexPatternsFile = *regexPatternsFile
+	config.Record.FixedWidthSpecFile = *fixedWidthSpecFile
+	config.Record.XmlRecordPath = *xmlRecordPath
+	config.Record.Sheet = *sheet
+	config.Record.SqliteTable = *sqliteTable
+	config.Record.SqliteQuery = *sqliteQuery
+	config.Record.InfluxPrecision = *influxPrecision
+	config.Record.FillDefaults = *fillDefaults
+	config.Record.BinaryEncodings = binaryEncodings
+	config.SchemaRegistry = columnifier.SchemaRegistry{
+		URL:      *schemaRegistryURL,
+		Subject:  *schemaRegistrySubject,
+		Version:  *schemaRegistryVersion,
+		Id:       *schemaRegistryId,
+		CacheDir: *schemaRegistryCacheDir,
+	}
 
 	c, err := columnifier.NewColumnifier(*schemaType, *schemaFile, *recordType, *output, *config)
 	if err != nil {
@@ -1,17 +1,52 @@
 package columnifier
 
 import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/reproio/columnify/record"
 	"github.com/xitongsys/parquet-go/parquet"
 )
 
 type Config struct {
-	Parquet Parquet
+	Parquet        Parquet
+	Record         record.Config
+	SchemaRegistry SchemaRegistry
 }
 
 type Parquet struct {
 	PageSize         int64
 	RowGroupSize     int64
 	CompressionCodec parquet.CompressionCodec
+
+	// CreatedBy is the application written in the footer, e.g. columnify version v0.1.0 (build 1a2b3c4)
+	CreatedBy string
+	// ColumnOptionsFile is the path to JSON of column specific codecs and encodings keyed by column paths
+	ColumnOptionsFile string
+	// Metadata is key-value pairs added to the footer, overwriting generated ones like the original schema
+	Metadata map[string]string
+	// PageIndex enables ColumnIndex and OffsetIndex for readers to skip pages
+	PageIndex bool
+	// LegacyTypes writes only ConvertedType annotations without LogicalType for old readers like Hive 2
+	LegacyTypes bool
+	// LegacyLists writes lists as repeated fields, the two-level encoding, instead of LIST annotated groups
+	LegacyLists bool
+	// Int96Timestamps writes all timestamps as INT96 for old Hive and Impala
+	Int96Timestamps bool
+	// Int96TimestampColumns is column paths of timestamps written as INT96, e.g. created_at or nested.field
+	Int96TimestampColumns []string
+	// BloomFilterColumns is column paths to write Bloom filters, e.g. user_id or nested.field
+	BloomFilterColumns []string
+}
+
+// SchemaRegistry specifies a schema fetched from a schema registry instead of a local schema file.
+// The schema is identified by Id if it's positive, otherwise by Subject and Version.
+type SchemaRegistry struct {
+	URL      string
+	Subject  string
+	Version  string
+	Id       int
+	CacheDir string
 }
 
 func NewConfig(parquetPageSize, parquetRowGroupSize int64, parquetCompressionCodec string) (*Config, error) {
@@ -25,6 +60,29 @@ func NewConfig(parquetPageSize, parquetRowGroupSize int64, parquetCompressionCod
 			PageSize:         parquetPageSize,
 			RowGroupSize:     parquetRowGroupSize,
 			CompressionCodec: cc,
+			CreatedBy:        DefaultCreatedBy(),
 		},
 	}, nil
 }
+
+// DefaultCreatedBy returns the application name with the module version and the VCS revision if they're available.
+func DefaultCreatedBy() string {
+	version := "devel"
+	build := "unknown"
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v := info.Main.Version; v != "" && v != "(devel)" {
+			version = v
+		}
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" && s.Value != "" {
+				build = s.Value
+				if len(build) > 7 {
+					build = build[:7]
+				}
+			}
+		}
+	}
+
+	return fmt.Sprintf("columnify version %s (build %s)", version, build)
+}
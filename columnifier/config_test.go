@@ -24,6 +24,7 @@ func TestNewConfig(t *testing.T) {
 					PageSize:         8 * 1024,
 					RowGroupSize:     128 * 1024 * 1024,
 					CompressionCodec: parquet.CompressionCodec_SNAPPY,
+					CreatedBy:        DefaultCreatedBy(),
 				},
 			},
 			isErr: false,
@@ -1,40 +1,49 @@
 package columnifier
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"sort"
 
-	"github.com/xitongsys/parquet-go/marshal"
+	"github.com/apache/arrow/go/arrow"
 
 	"github.com/reproio/columnify/record"
 
 	"github.com/reproio/columnify/parquet"
 	"github.com/reproio/columnify/schema"
 	"github.com/xitongsys/parquet-go-source/local"
+	parquetFormat "github.com/xitongsys/parquet-go/parquet"
 	parquetSource "github.com/xitongsys/parquet-go/source"
-	"github.com/xitongsys/parquet-go/writer"
 )
 
 // Columnifier is a parquet specific Columninifier implementation.
 type parquetColumnifier struct {
-	w      *writer.ParquetWriter
+	w      *parquet.Writer
 	schema *schema.IntermediateSchema
 	rt     string
+	config Config
 }
 
 // NewParquetColumnifier creates a new parquetColumnifier.
 func NewParquetColumnifier(st string, sf string, rt string, output string, config Config) (*parquetColumnifier, error) {
-	schemaContent, err := os.ReadFile(sf)
+	intermediateSchema, schemaContent, err := getSchema(st, sf, config)
 	if err != nil {
 		return nil, err
 	}
 
-	intermediateSchema, err := schema.GetSchema(schemaContent, st)
+	parquetOptions := schema.ParquetOptions{
+		LegacyTypes:           config.Parquet.LegacyTypes,
+		LegacyLists:           config.Parquet.LegacyLists,
+		Int96Timestamps:       config.Parquet.Int96Timestamps,
+		Int96TimestampColumns: config.Parquet.Int96TimestampColumns,
+	}
+	sh, err := schema.NewSchemaHandlerFromArrow(*intermediateSchema, parquetOptions)
 	if err != nil {
 		return nil, err
 	}
-
-	sh, err := schema.NewSchemaHandlerFromArrow(*intermediateSchema)
+	int96Units, err := schema.Int96TimestampUnits(*intermediateSchema, parquetOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -49,30 +58,182 @@ func NewParquetColumnifier(st string, sf string, rt string, output string, confi
 		fw = parquet.NewStdioFile()
 	}
 
-	w, err := writer.NewParquetWriter(fw, nil, 1)
+	w, err := parquet.NewWriter(fw, sh)
 	if err != nil {
 		return nil, err
 	}
-	w.SchemaHandler = sh
-	w.Footer.Schema = append(w.Footer.Schema, sh.SchemaElements...)
 
 	w.PageSize = config.Parquet.PageSize
 	w.RowGroupSize = config.Parquet.RowGroupSize
 	w.CompressionType = config.Parquet.CompressionCodec
+	w.PageIndex = config.Parquet.PageIndex
+	if config.Parquet.CreatedBy != "" {
+		w.Footer.CreatedBy = &config.Parquet.CreatedBy
+	}
+
+	kvs, err := keyValueMetadata(st, schemaContent, intermediateSchema, config.Parquet.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	w.Footer.KeyValueMetadata = kvs
+
+	columnOptions, err := getColumnOptions(intermediateSchema, config.Parquet.ColumnOptionsFile, config.Parquet.BloomFilterColumns)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.SetColumnOptions(columnOptions); err != nil {
+		return nil, err
+	}
+	if err := w.SetInt96Timestamps(int96Units); err != nil {
+		return nil, err
+	}
 
 	// Intermediate record type is string typed JSON values
-	w.MarshalFunc = marshal.MarshalJSON
+	w.MarshalFunc = parquet.MarshalJSON
 
 	return &parquetColumnifier{
 		w:      w,
 		schema: intermediateSchema,
 		rt:     rt,
+		config: config,
 	}, nil
 }
 
+// getSchema reads the schema and returns it with the original content, or derives it from the SQLite database
+// given as the schema file.
+func getSchema(st string, sf string, config Config) (*schema.IntermediateSchema, []byte, error) {
+	if st == schema.SchemaTypeSqlite {
+		s, err := schema.NewSchemaFromSqlite(sf, config.Record.SqliteTable, config.Record.SqliteQuery)
+		return s, nil, err
+	}
+
+	schemaContent, err := readSchema(sf, config.SchemaRegistry)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, err := schema.GetSchema(schemaContent, st)
+
+	return s, schemaContent, err
+}
+
+// keyValueMetadata returns the footer metadata of the original schema, the arrow schema and user given pairs.
+// User given pairs take precedence, and they're sorted by keys for reproducible outputs.
+func keyValueMetadata(st string, schemaContent []byte, s *schema.IntermediateSchema, userMetadata map[string]string) ([]*parquetFormat.KeyValue, error) {
+	metadata := make(map[string]string)
+
+	if schemaContent != nil {
+		k, v, err := schema.NewSchemaMetadata(schemaContent, st)
+		if err != nil {
+			return nil, err
+		}
+		if k != "" {
+			metadata[k] = v
+		}
+	}
+
+	// the arrow schema is optional, so it's omitted for types which can't be serialized
+	if v, err := schema.NewArrowSchemaMetadata(*s); err == nil {
+		metadata[schema.ArrowSchemaMetadataKey] = v
+	} else if !errors.Is(err, schema.ErrUnconvertibleSchema) {
+		return nil, err
+	}
+
+	if v, err := schema.NewGeoMetadata(*s); err != nil {
+		return nil, err
+	} else if v != "" {
+		metadata[schema.GeoMetadataKey] = v
+	}
+
+	for k, v := range userMetadata {
+		metadata[k] = v
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]*parquetFormat.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		v := metadata[k]
+		kvs = append(kvs, &parquetFormat.KeyValue{Key: k, Value: &v})
+	}
+
+	return kvs, nil
+}
+
+// getColumnOptions collects column options in schema field properties, and overwrites them with ones in the file.
+// Bloom filters are enabled for given columns in addition.
+func getColumnOptions(s *schema.IntermediateSchema, path string, bloomFilterColumns []string) (map[string]parquet.ColumnOptions, error) {
+	options := make(map[string]parquet.ColumnOptions)
+	if err := collectColumnOptions(s.ArrowSchema.Fields(), "", options); err != nil {
+		return nil, err
+	}
+
+	if path != "" {
+		fileOptions, err := parquet.ReadColumnOptionsFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fileOptions {
+			options[k] = v
+		}
+	}
+
+	for _, c := range bloomFilterColumns {
+		enabled := true
+		o := options[c]
+		o.BloomFilter = &enabled
+		options[c] = o
+	}
+
+	return options, nil
+}
+
+func collectColumnOptions(fields []arrow.Field, prefix string, options map[string]parquet.ColumnOptions) error {
+	for _, f := range fields {
+		path := prefix + f.Name
+
+		if i := f.Metadata.FindKey(parquet.ColumnOptionsMetadataKey); i >= 0 {
+			o, err := parquet.ParseColumnOptions(f.Metadata.Values()[i])
+			if err != nil {
+				return fmt.Errorf("field %s: %w", path, err)
+			}
+			options[path] = o
+		}
+
+		if st, ok := f.Type.(*arrow.StructType); ok {
+			if err := collectColumnOptions(st.Fields(), path+".", options); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// readSchema reads the schema content from the schema file, or the schema registry if its URL is given.
+func readSchema(sf string, registry SchemaRegistry) ([]byte, error) {
+	if registry.URL == "" {
+		return os.ReadFile(sf)
+	}
+	if sf != "" {
+		return nil, fmt.Errorf("schema file %s and schema registry %s are exclusive: %w", sf, registry.URL, schema.ErrInvalidSchema)
+	}
+
+	c := schema.NewRegistryClient(registry.URL, registry.CacheDir)
+	if registry.Id > 0 {
+		return c.GetById(registry.Id)
+	}
+
+	return c.GetBySubject(registry.Subject, registry.Version)
+}
+
 // Write reads, converts input binary data and write it to buffer.
 func (c *parquetColumnifier) WriteFromReader(reader io.Reader) (int, error) {
-	decoder, err := record.NewJsonStringConverter(reader, c.schema, c.rt)
+	decoder, err := record.NewJsonStringConverter(reader, c.schema, c.rt, c.config.Record)
 	if err != nil {
 		return -1, err
 	}
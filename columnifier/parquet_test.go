@@ -3,7 +3,12 @@ package columnifier
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/xitongsys/parquet-go-source/local"
@@ -122,11 +127,65 @@ func TestNewParquetColumnifier(t *testing.T) {
 	}
 }
 
+func TestNewParquetColumnifier_SchemaRegistry(t *testing.T) {
+	content, err := os.ReadFile("testdata/schema/primitives.avsc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/subjects/primitives-value/versions/latest" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"schema": string(content)})
+	}))
+	t.Cleanup(s.Close)
+
+	cases := []struct {
+		sf      string
+		subject string
+		isErr   bool
+	}{
+		{
+			subject: "primitives-value",
+			isErr:   false,
+		},
+		{
+			subject: "unknown-value",
+			isErr:   true,
+		},
+		// schema file and schema registry are exclusive
+		{
+			sf:      "testdata/schema/primitives.avsc",
+			subject: "primitives-value",
+			isErr:   true,
+		},
+	}
+
+	for _, c := range cases {
+		config := Config{
+			SchemaRegistry: SchemaRegistry{
+				URL:     s.URL,
+				Subject: c.subject,
+				Version: "latest",
+			},
+		}
+
+		_, err := NewParquetColumnifier(schema.SchemaTypeAvro, c.sf, record.RecordTypeJsonl, "", config)
+
+		if err != nil != c.isErr {
+			t.Errorf("expected %v, but actual %v", c.isErr, err)
+		}
+	}
+}
+
 func TestWriteClose(t *testing.T) {
 	cases := []struct {
 		st       string
 		sf       string
 		rt       string
+		record   record.Config
 		input    string
 		expected string
 	}{
@@ -138,6 +197,40 @@ func TestWriteClose(t *testing.T) {
 			input:    "testdata/record/primitives.avro",
 			expected: "testdata/parquet/primitives_with_bytes.parquet",
 		},
+		// primitives; Avro schema, Avro single-object encoded record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/primitives.avsc",
+			rt:       record.RecordTypeAvroSingleObject,
+			record:   record.Config{AvroSchemaDir: "testdata/schema/registry"},
+			input:    "testdata/record/primitives.avro_single_object",
+			expected: "testdata/parquet/primitives_with_bytes.parquet",
+		},
+		// primitives; Avro schema, Avro Confluent wire format record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/primitives.avsc",
+			rt:       record.RecordTypeAvroConfluent,
+			record:   record.Config{AvroSchemaDir: "testdata/schema/registry"},
+			input:    "testdata/record/primitives.avro_confluent",
+			expected: "testdata/parquet/primitives_with_bytes.parquet",
+		},
+		// primitives; Avro schema, BSON record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/primitives.avsc",
+			rt:       record.RecordTypeBson,
+			input:    "testdata/record/primitives.bson",
+			expected: "testdata/parquet/primitives.parquet",
+		},
+		// primitives; Avro schema, CBOR record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/primitives.avsc",
+			rt:       record.RecordTypeCbor,
+			input:    "testdata/record/primitives.cbor",
+			expected: "testdata/parquet/primitives.parquet",
+		},
 		// primitives; Avro schema, CSV record
 		{
 			st:       schema.SchemaTypeAvro,
@@ -146,6 +239,22 @@ func TestWriteClose(t *testing.T) {
 			input:    "testdata/record/primitives.csv",
 			expected: "testdata/parquet/primitives.parquet",
 		},
+		// primitives; Avro schema, Ion record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/primitives.avsc",
+			rt:       record.RecordTypeIon,
+			input:    "testdata/record/primitives.ion",
+			expected: "testdata/parquet/primitives.parquet",
+		},
+		// primitives; Avro schema, JSON record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/primitives.avsc",
+			rt:       record.RecordTypeJson,
+			input:    "testdata/record/primitives.json",
+			expected: "testdata/parquet/primitives.parquet",
+		},
 		// primitives; Avro schema, JSONL record
 		{
 			st:       schema.SchemaTypeAvro,
@@ -154,6 +263,15 @@ func TestWriteClose(t *testing.T) {
 			input:    "testdata/record/primitives.jsonl",
 			expected: "testdata/parquet/primitives.parquet",
 		},
+		// primitives; Avro schema, JSONL record with base64 bytes
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/primitives.avsc",
+			rt:       record.RecordTypeJsonl,
+			record:   record.Config{BinaryEncodings: map[string]string{"bytes": record.BinaryEncodingBase64}},
+			input:    "testdata/record/primitives_base64.jsonl",
+			expected: "testdata/parquet/primitives.parquet",
+		},
 		// primitives; Avro schema, LTSV record
 		{
 			st:       schema.SchemaTypeAvro,
@@ -178,6 +296,83 @@ func TestWriteClose(t *testing.T) {
 			input:    "testdata/record/primitives.tsv",
 			expected: "testdata/parquet/primitives.parquet",
 		},
+		// primitives; Avro schema, xlsx record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/primitives.avsc",
+			rt:       record.RecordTypeXlsx,
+			record:   record.Config{Sheet: "primitives"},
+			input:    "testdata/record/primitives.xlsx",
+			expected: "testdata/parquet/primitives.parquet",
+		},
+		// primitives; Avro schema, ods record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/primitives.avsc",
+			rt:       record.RecordTypeOds,
+			record:   record.Config{Sheet: "1"},
+			input:    "testdata/record/primitives.ods",
+			expected: "testdata/parquet/primitives.parquet",
+		},
+		// primitives; Avro schema, sqlite record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/primitives.avsc",
+			rt:       record.RecordTypeSqlite,
+			record:   record.Config{SqliteTable: "primitives"},
+			input:    "testdata/record/primitives.sqlite",
+			expected: "testdata/parquet/primitives_with_bytes.parquet",
+		},
+		// primitives; schema derived from sqlite table, sqlite record
+		{
+			st:       schema.SchemaTypeSqlite,
+			sf:       "testdata/record/primitives.sqlite",
+			rt:       record.RecordTypeSqlite,
+			input:    "testdata/record/primitives.sqlite",
+			expected: "testdata/parquet/primitives_with_bytes.parquet",
+		},
+		// metrics; Avro schema with tags map, influx record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/influx.avsc",
+			rt:       record.RecordTypeInflux,
+			input:    "testdata/record/metrics.influx",
+			expected: "testdata/parquet/influx.parquet",
+		},
+		// metrics; Avro schema, prometheus record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/prometheus.avsc",
+			rt:       record.RecordTypePrometheus,
+			input:    "testdata/record/metrics.prom",
+			expected: "testdata/parquet/prometheus.parquet",
+		},
+		// primitives; Avro schema with fixedwidth properties, fixedwidth record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/primitives_fixedwidth.avsc",
+			rt:       record.RecordTypeFixedWidth,
+			input:    "testdata/record/primitives.fixedwidth",
+			expected: "testdata/parquet/primitives.parquet",
+		},
+		// primitives; Avro schema, fixedwidth record with column spec file
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/primitives.avsc",
+			rt:       record.RecordTypeFixedWidth,
+			record:   record.Config{FixedWidthSpecFile: "testdata/schema/primitives_fixedwidth.json"},
+			input:    "testdata/record/primitives.fixedwidth",
+			expected: "testdata/parquet/primitives.parquet",
+		},
+		// apache combined log; Avro schema, regex record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/apache_combined.avsc",
+			rt:       record.RecordTypeRegex,
+			record:   record.Config{RegexPattern: record.RegexPatternApacheCombined},
+			input:    "testdata/record/apache_combined.log",
+			expected: "testdata/parquet/apache_combined.parquet",
+		},
 		// nullables; Avro schema, Avro record
 		{
 			st:       schema.SchemaTypeAvro,
@@ -211,6 +406,31 @@ func TestWriteClose(t *testing.T) {
 			input:    "testdata/record/logicals.csv",
 			expected: "testdata/parquet/logicals.parquet",
 		},
+		// logicals; Avro schema, sqlite record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/logicals.avsc",
+			rt:       record.RecordTypeSqlite,
+			input:    "testdata/record/logicals.sqlite",
+			expected: "testdata/parquet/logicals.parquet",
+		},
+		// logicals; Avro schema, xlsx record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/logicals.avsc",
+			rt:       record.RecordTypeXlsx,
+			input:    "testdata/record/logicals.xlsx",
+			expected: "testdata/parquet/logicals.parquet",
+		},
+		// logicals; Avro schema, ods record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/logicals.avsc",
+			rt:       record.RecordTypeOds,
+			record:   record.Config{Sheet: "logicals"},
+			input:    "testdata/record/logicals.ods",
+			expected: "testdata/parquet/logicals.parquet",
+		},
 		// logicals; Avro schema, JSONL record
 		{
 			st:       schema.SchemaTypeAvro,
@@ -243,6 +463,62 @@ func TestWriteClose(t *testing.T) {
 			input:    "testdata/record/logicals.tsv",
 			expected: "testdata/parquet/logicals.parquet",
 		},
+		// logicals_extended; Avro schema, Avro record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/logicals_extended.avsc",
+			rt:       record.RecordTypeAvro,
+			input:    "testdata/record/logicals_extended.avro",
+			expected: "testdata/parquet/logicals_extended.parquet",
+		},
+		// logicals_extended; Avro schema, JSONL record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/logicals_extended.avsc",
+			rt:       record.RecordTypeJsonl,
+			input:    "testdata/record/logicals_extended.jsonl",
+			expected: "testdata/parquet/logicals_extended.parquet",
+		},
+		// durations; Avro schema, Avro record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/durations.avsc",
+			rt:       record.RecordTypeAvro,
+			input:    "testdata/record/durations.avro",
+			expected: "testdata/parquet/durations.parquet",
+		},
+		// durations; Avro schema, JSONL record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/durations.avsc",
+			rt:       record.RecordTypeJsonl,
+			input:    "testdata/record/durations.jsonl",
+			expected: "testdata/parquet/durations.parquet",
+		},
+		// durations; Avro schema, MessagePack record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/durations.avsc",
+			rt:       record.RecordTypeMsgpack,
+			input:    "testdata/record/durations.msgpack",
+			expected: "testdata/parquet/durations.parquet",
+		},
+		// enums; Avro schema, Avro record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/enums.avsc",
+			rt:       record.RecordTypeAvro,
+			input:    "testdata/record/enums.avro",
+			expected: "testdata/parquet/enums.parquet",
+		},
+		// enums; Avro schema, JSONL record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/enums.avsc",
+			rt:       record.RecordTypeJsonl,
+			input:    "testdata/record/enums.jsonl",
+			expected: "testdata/parquet/enums.parquet",
+		},
 		// nested; Avro schema, Avro record
 		{
 			st:       schema.SchemaTypeAvro,
@@ -251,6 +527,15 @@ func TestWriteClose(t *testing.T) {
 			input:    "testdata/record/nested.avro",
 			expected: "testdata/parquet/nested_with_bytes.parquet",
 		},
+		// nested; Avro schema, JSON record wrapping records
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/nested.avsc",
+			rt:       record.RecordTypeJson,
+			record:   record.Config{JsonPointer: "/Records"},
+			input:    "testdata/record/nested.json",
+			expected: "testdata/parquet/nested.parquet",
+		},
 		// nested; Avro schema, JSONL record
 		{
 			st:       schema.SchemaTypeAvro,
@@ -291,6 +576,15 @@ func TestWriteClose(t *testing.T) {
 			input:    "testdata/record/array.msgpack",
 			expected: "testdata/parquet/array.parquet",
 		},
+		// array; Avro schema, XML record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/array.avsc",
+			rt:       record.RecordTypeXml,
+			record:   record.Config{XmlRecordPath: "/feed/entry"},
+			input:    "testdata/record/array.xml",
+			expected: "testdata/parquet/array.parquet",
+		},
 		// nullable/complex; Avro schema, Avro record
 		{
 			st:       schema.SchemaTypeAvro,
@@ -315,6 +609,22 @@ func TestWriteClose(t *testing.T) {
 			input:    "testdata/record/nullable_complex.msgpack",
 			expected: "testdata/parquet/nullable_complex.parquet",
 		},
+		// single_field_nested; Avro schema, Avro record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/single_field_nested.avsc",
+			rt:       record.RecordTypeAvro,
+			input:    "testdata/record/single_field_nested.avro",
+			expected: "testdata/parquet/single_field_nested.parquet",
+		},
+		// single_field_nested; Avro schema, JSONL record
+		{
+			st:       schema.SchemaTypeAvro,
+			sf:       "testdata/schema/single_field_nested.avsc",
+			rt:       record.RecordTypeJsonl,
+			input:    "testdata/record/single_field_nested.jsonl",
+			expected: "testdata/parquet/single_field_nested.parquet",
+		},
 
 		// primitives; BigQuery schema, Avro record
 		{
@@ -447,7 +757,10 @@ func TestWriteClose(t *testing.T) {
 			_ = os.Remove(out.Name())
 		})
 
-		columnifier, err := NewParquetColumnifier(c.st, c.sf, c.rt, out.Name(), defaultConfig)
+		config := defaultConfig
+		config.Record = c.record
+
+		columnifier, err := NewParquetColumnifier(c.st, c.sf, c.rt, out.Name(), config)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -514,3 +827,461 @@ func TestWriteClose_Errors(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteClose_Metadata(t *testing.T) {
+	cases := []struct {
+		st       string
+		sf       string
+		rt       string
+		input    string
+		config   Parquet
+		expected map[string]string
+	}{
+		// Avro schema, user metadata
+		{
+			st:    schema.SchemaTypeAvro,
+			sf:    "testdata/schema/primitives.avsc",
+			rt:    record.RecordTypeJsonl,
+			input: "testdata/record/primitives.jsonl",
+			config: Parquet{
+				CreatedBy: "test version 1.0.0 (build abc)",
+				Metadata:  map[string]string{"env": "test"},
+			},
+			expected: map[string]string{
+				"env":                        "test",
+				schema.AvroSchemaMetadataKey: "",
+			},
+		},
+		// BigQuery schema, overwritten schema metadata
+		{
+			st:    schema.SchemaTypeBigquery,
+			sf:    "testdata/schema/primitives.bq.json",
+			rt:    record.RecordTypeJsonl,
+			input: "testdata/record/primitives.jsonl",
+			config: Parquet{
+				Metadata: map[string]string{schema.BigquerySchemaMetadataKey: "overwritten"},
+			},
+			expected: map[string]string{
+				schema.BigquerySchemaMetadataKey: "overwritten",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		out, err := os.CreateTemp("", "out.parquet")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = os.Remove(out.Name())
+		})
+
+		config := defaultConfig
+		config.Parquet.CreatedBy = c.config.CreatedBy
+		config.Parquet.Metadata = c.config.Metadata
+
+		columnifier, err := NewParquetColumnifier(c.st, c.sf, c.rt, out.Name(), config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = columnifier.WriteFromFiles([]string{c.input})
+		if err == nil {
+			err = columnifier.Close()
+		}
+		if err != nil {
+			t.Fatalf("expected success, but actual %v", err)
+		}
+
+		fr, err := local.NewLocalFileReader(out.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		pr, err := reader.NewParquetReader(fr, nil, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if c.config.CreatedBy != "" && pr.Footer.GetCreatedBy() != c.config.CreatedBy {
+			t.Errorf("expected %v, but actual %v", c.config.CreatedBy, pr.Footer.GetCreatedBy())
+		}
+
+		actual := make(map[string]string)
+		for _, kv := range pr.Footer.KeyValueMetadata {
+			actual[kv.Key] = kv.GetValue()
+		}
+		if _, ok := actual[schema.ArrowSchemaMetadataKey]; !ok {
+			t.Errorf("expected %s exists, but actual %v", schema.ArrowSchemaMetadataKey, actual)
+		}
+		for k, v := range c.expected {
+			// empty expected values only check existence
+			if av, ok := actual[k]; !ok || (v != "" && av != v) {
+				t.Errorf("expected %s=%s, but actual %v", k, v, actual)
+			}
+		}
+		pr.ReadStop()
+		fr.Close()
+	}
+}
+
+func TestWriteClose_ColumnOptions(t *testing.T) {
+	type column struct {
+		codec    parquet.CompressionCodec
+		encoding parquet.Encoding
+	}
+
+	cases := []struct {
+		sf                string
+		columnOptionsFile string
+		expected          map[string]column
+	}{
+		// schema field properties
+		{
+			sf: "testdata/schema/primitives_column_options.avsc",
+			expected: map[string]column{
+				"boolean": {codec: parquet.CompressionCodec_SNAPPY, encoding: parquet.Encoding_PLAIN},
+				"int":     {codec: parquet.CompressionCodec_SNAPPY, encoding: parquet.Encoding_DELTA_BINARY_PACKED},
+				"long":    {codec: parquet.CompressionCodec_GZIP, encoding: parquet.Encoding_DELTA_BINARY_PACKED},
+				"bytes":   {codec: parquet.CompressionCodec_SNAPPY, encoding: parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY},
+				"string":  {codec: parquet.CompressionCodec_SNAPPY, encoding: parquet.Encoding_PLAIN_DICTIONARY},
+			},
+		},
+		// column options file overwriting schema field properties
+		{
+			sf:                "testdata/schema/primitives_column_options.avsc",
+			columnOptionsFile: "testdata/config/primitives_column_options.json",
+			expected: map[string]column{
+				"int":    {codec: parquet.CompressionCodec_SNAPPY, encoding: parquet.Encoding_DELTA_BINARY_PACKED},
+				"long":   {codec: parquet.CompressionCodec_ZSTD, encoding: parquet.Encoding_PLAIN},
+				"bytes":  {codec: parquet.CompressionCodec_SNAPPY, encoding: parquet.Encoding_DELTA_BYTE_ARRAY},
+				"string": {codec: parquet.CompressionCodec_UNCOMPRESSED, encoding: parquet.Encoding_PLAIN_DICTIONARY},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		out, err := os.CreateTemp("", "out.parquet")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = os.Remove(out.Name())
+		})
+
+		config := defaultConfig
+		config.Parquet.ColumnOptionsFile = c.columnOptionsFile
+
+		columnifier, err := NewParquetColumnifier(schema.SchemaTypeAvro, c.sf, record.RecordTypeJsonl, out.Name(), config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = columnifier.WriteFromFiles([]string{"testdata/record/primitives.jsonl"})
+		if err == nil {
+			err = columnifier.Close()
+		}
+		if err != nil {
+			t.Fatalf("expected success, but actual %v", err)
+		}
+
+		assertWrittenParquet(t, "testdata/parquet/primitives.parquet", out.Name())
+
+		fr, err := local.NewLocalFileReader(out.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		pr, err := reader.NewParquetReader(fr, nil, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, chunk := range pr.Footer.RowGroups[0].Columns {
+			name := chunk.MetaData.PathInSchema[0]
+			expected, ok := c.expected[name]
+			if !ok {
+				continue
+			}
+
+			if chunk.MetaData.Codec != expected.codec {
+				t.Errorf("expected %v for %s, but actual %v", expected.codec, name, chunk.MetaData.Codec)
+			}
+			found := false
+			for _, e := range chunk.MetaData.Encodings {
+				found = found || e == expected.encoding
+			}
+			if !found {
+				t.Errorf("expected %v for %s, but actual %v", expected.encoding, name, chunk.MetaData.Encodings)
+			}
+		}
+		pr.ReadStop()
+		fr.Close()
+	}
+}
+
+func TestWriteClose_PageIndexAndBloomFilter(t *testing.T) {
+	cases := []struct {
+		bloomFilterColumns []string
+		isErr              bool
+	}{
+		{
+			bloomFilterColumns: []string{"long", "string"},
+			isErr:              false,
+		},
+		// Unknown column
+		{
+			bloomFilterColumns: []string{"unknown"},
+			isErr:              true,
+		},
+		// Bloom filter for booleans
+		{
+			bloomFilterColumns: []string{"boolean"},
+			isErr:              true,
+		},
+	}
+
+	for _, c := range cases {
+		out, err := os.CreateTemp("", "out.parquet")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = os.Remove(out.Name())
+		})
+
+		config := defaultConfig
+		config.Parquet.PageIndex = true
+		config.Parquet.BloomFilterColumns = c.bloomFilterColumns
+
+		columnifier, err := NewParquetColumnifier(schema.SchemaTypeAvro, "testdata/schema/primitives.avsc", record.RecordTypeJsonl, out.Name(), config)
+		if err != nil != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		_, err = columnifier.WriteFromFiles([]string{"testdata/record/primitives.jsonl"})
+		if err == nil {
+			err = columnifier.Close()
+		}
+		if err != nil {
+			t.Fatalf("expected success, but actual %v", err)
+		}
+
+		assertWrittenParquet(t, "testdata/parquet/primitives.parquet", out.Name())
+
+		fr, err := local.NewLocalFileReader(out.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		pr, err := reader.NewParquetReader(fr, nil, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, chunk := range pr.Footer.RowGroups[0].Columns {
+			if chunk.ColumnIndexOffset == nil || chunk.OffsetIndexOffset == nil {
+				t.Errorf("expected page index for %v, but actual %v", chunk.MetaData.PathInSchema, chunk)
+			}
+			if chunk.MetaData.Statistics.NullCount == nil {
+				t.Errorf("expected null count for %v, but actual %v", chunk.MetaData.PathInSchema, chunk.MetaData.Statistics)
+			}
+		}
+		pr.ReadStop()
+		fr.Close()
+	}
+}
+
+func TestWriteClose_Lists(t *testing.T) {
+	// paths are read with internal names
+	cases := []struct {
+		legacyLists bool
+		expected    string
+	}{
+		{
+			legacyLists: false,
+			expected:    "[Array List Element Boolean]",
+		},
+		{
+			legacyLists: true,
+			expected:    "[Array Boolean]",
+		},
+	}
+
+	for _, c := range cases {
+		out, err := os.CreateTemp("", "out.parquet")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = os.Remove(out.Name())
+		})
+
+		config := defaultConfig
+		config.Parquet.LegacyLists = c.legacyLists
+
+		columnifier, err := NewParquetColumnifier(schema.SchemaTypeAvro, "testdata/schema/nullable_complex.avsc", record.RecordTypeJsonl, out.Name(), config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = columnifier.WriteFromFiles([]string{"testdata/record/nullable_complex.jsonl"})
+		if err == nil {
+			err = columnifier.Close()
+		}
+		if err != nil {
+			t.Fatalf("expected success, but actual %v", err)
+		}
+
+		assertWrittenParquet(t, "testdata/parquet/nullable_complex.parquet", out.Name())
+
+		fr, err := local.NewLocalFileReader(out.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		pr, err := reader.NewParquetReader(fr, nil, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual string
+		for _, chunk := range pr.Footer.RowGroups[0].Columns {
+			if chunk.MetaData.PathInSchema[0] == "Array" {
+				actual = fmt.Sprint(chunk.MetaData.PathInSchema)
+				break
+			}
+		}
+		if actual != c.expected {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+		pr.ReadStop()
+		fr.Close()
+	}
+}
+
+func TestWriteClose_Int96Timestamps(t *testing.T) {
+	out, err := os.CreateTemp("", "out.parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Remove(out.Name())
+	})
+
+	config := defaultConfig
+	config.Parquet.Int96TimestampColumns = []string{"timestampmillis"}
+
+	columnifier, err := NewParquetColumnifier(schema.SchemaTypeAvro, "testdata/schema/logicals.avsc", record.RecordTypeJsonl, out.Name(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = columnifier.WriteFromFiles([]string{"testdata/record/logicals.jsonl"})
+	if err == nil {
+		err = columnifier.Close()
+	}
+	if err != nil {
+		t.Fatalf("expected success, but actual %v", err)
+	}
+
+	fr, err := local.NewLocalFileReader(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fr.Close()
+	pr, err := reader.NewParquetReader(fr, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.ReadStop()
+
+	// the first record has 1000 ms, 1 second of the Julian day 2440588
+	expected := "\x00\xca\x9a\x3b\x00\x00\x00\x00\x8c\x3d\x25\x00"
+	values, _, _, err := pr.ReadColumnByIndex(3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 1 || values[0] != expected {
+		t.Errorf("expected: %v, but actual: %v\n", []byte(expected), values)
+	}
+
+	// other timestamps are kept
+	if typ := pr.Footer.Schema[5].GetType(); typ != parquet.Type_INT64 {
+		t.Errorf("expected: %v, but actual: %v\n", parquet.Type_INT64, typ)
+	}
+
+	// column without timestamps
+	config.Parquet.Int96TimestampColumns = []string{"date"}
+	if _, err := NewParquetColumnifier(schema.SchemaTypeAvro, "testdata/schema/logicals.avsc", record.RecordTypeJsonl, out.Name(), config); err == nil {
+		t.Errorf("expected error, but actual %v", err)
+	}
+}
+
+func TestWriteClose_BigqueryTypes(t *testing.T) {
+	out, err := os.CreateTemp("", "out.parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Remove(out.Name())
+	})
+
+	columnifier, err := NewParquetColumnifier(schema.SchemaTypeBigquery, "testdata/schema/bigquery_types.bq.json", record.RecordTypeJsonl, out.Name(), defaultConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = columnifier.WriteFromFiles([]string{"testdata/record/bigquery_types.jsonl"})
+	if err == nil {
+		err = columnifier.Close()
+	}
+	if err != nil {
+		t.Fatalf("expected success, but actual %v", err)
+	}
+
+	fr, err := local.NewLocalFileReader(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fr.Close()
+	pr, err := reader.NewParquetReader(fr, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.ReadStop()
+
+	// unscaled decimals in 33 bytes two's complement
+	decimal := func(unscaled string) string {
+		x, _ := new(big.Int).SetString(unscaled, 10)
+		if x.Sign() < 0 {
+			x.Add(x, new(big.Int).Lsh(big.NewInt(1), 264))
+		}
+		return string(x.FillBytes(make([]byte, 33)))
+	}
+
+	cases := []struct {
+		index    int64
+		expected []interface{}
+	}{
+		// DATETIME is a local timestamp, and TIMESTAMP is in UTC
+		{index: 0, expected: []interface{}{int64(1577934245123456), int64(1577934245000000), int64(1577934245000000)}},
+		{index: 1, expected: []interface{}{int64(1577934245123456), int64(1577934245000000), int64(1577934245000000)}},
+		// GeoJSON is converted to WKT
+		{index: 2, expected: []interface{}{"POINT(139.7 35.7)", "POINT (139.7 35.7)", nil}},
+		{index: 3, expected: []interface{}{`{"a":[1,2]}`, `{"a":[1,2]}`, nil}},
+		// two's complement of 33 bytes
+		{index: 4, expected: []interface{}{decimal("-1234567890123456789012345678901234567890123456789012345678"), decimal("100000000000000000000000000000000000000"), nil}},
+		// intervals in the canonical format keep signs and microseconds
+		{index: 5, expected: []interface{}{"1-2 3 4:5:6.789", "-0-10 3 4:5:6.789012", nil}},
+	}
+
+	for _, c := range cases {
+		values, _, _, err := pr.ReadColumnByIndex(c.index, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(values, c.expected) {
+			t.Errorf("expected: %q, but actual: %q\n", c.expected, values)
+		}
+	}
+
+	expected := `{"version":"1.0.0","primary_column":"geography","columns":{"geography":{"encoding":"WKT","geometry_types":[],"edges":"spherical"}}}`
+	actual := make(map[string]string)
+	for _, kv := range pr.Footer.KeyValueMetadata {
+		actual[kv.Key] = kv.GetValue()
+	}
+	if actual[schema.GeoMetadataKey] != expected {
+		t.Errorf("expected: %v, but actual: %v\n", expected, actual[schema.GeoMetadataKey])
+	}
+}
@@ -0,0 +1,228 @@
+package parquet
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"math/bits"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/cespare/xxhash/v2"
+	parquetFormat "github.com/xitongsys/parquet-go/parquet"
+)
+
+const (
+	// bloomFilterFPP is the false positive probability which filters are sized for
+	bloomFilterFPP = 0.01
+
+	bloomFilterBlockSize = 32
+	bloomFilterMinSize   = bloomFilterBlockSize
+	bloomFilterMaxSize   = 128 * 1024 * 1024
+)
+
+// bloomFilterSalts are the salts of the split block Bloom filter defined in the spec.
+var bloomFilterSalts = [8]uint32{
+	0x47b6137b, 0x44974d91, 0x8824ad5b, 0xa2b7289d,
+	0x705495c7, 0x2df1424b, 0x9efc4947, 0x5c6bfb31,
+}
+
+// bloomFilter is a split block Bloom filter, which consists of 256 bits blocks of 8 words.
+type bloomFilter struct {
+	blocks [][8]uint32
+}
+
+// newBloomFilter creates a filter sized for the number of distinct values up to maxSize bytes.
+func newBloomFilter(ndv int, maxSize int) *bloomFilter {
+	return &bloomFilter{
+		blocks: make([][8]uint32, bloomFilterSize(ndv, maxSize)/bloomFilterBlockSize),
+	}
+}
+
+// bloomFilterSize returns the optimal number of bytes for the number of distinct values,
+// rounded up to a power of 2 and limited by bloomFilterLimit.
+func bloomFilterSize(ndv int, maxSize int) int {
+	numBits := -8 * float64(ndv) / math.Log(1-math.Pow(bloomFilterFPP, 1.0/8))
+	numBytes := int(numBits / 8)
+
+	if limit := bloomFilterLimit(maxSize); numBytes > limit {
+		return limit
+	}
+	if numBytes < bloomFilterMinSize {
+		return bloomFilterMinSize
+	}
+	if numBytes&(numBytes-1) != 0 {
+		numBytes = 1 << bits.Len(uint(numBytes))
+	}
+
+	return numBytes
+}
+
+// bloomFilterLimit returns the maximum number of bytes of filters, which is maxSize rounded down to a power of 2.
+func bloomFilterLimit(maxSize int) int {
+	if maxSize > bloomFilterMaxSize {
+		return bloomFilterMaxSize
+	}
+	if maxSize < bloomFilterMinSize {
+		return bloomFilterMinSize
+	}
+
+	return 1 << (bits.Len(uint(maxSize)) - 1)
+}
+
+// bloomFilterNDV returns the number of distinct values which a filter of the size holds at the false positive probability.
+func bloomFilterNDV(size int) int {
+	return int(-float64(size) * math.Log(1-math.Pow(bloomFilterFPP, 1.0/8)))
+}
+
+// bloomFilterHash is XXH64 with seed 0 of the plain encoded value, without the length of byte arrays.
+func bloomFilterHash(v interface{}, t parquetFormat.Type) uint64 {
+	return xxhash.Sum64(plainValue(v, t))
+}
+
+// insert adds the hash of a value; the upper 32 bits select a block and the lower ones set a bit in each word.
+func (f *bloomFilter) insert(hash uint64) {
+	i := ((hash >> 32) * uint64(len(f.blocks))) >> 32
+	key := uint32(hash)
+	for j, salt := range bloomFilterSalts {
+		f.blocks[i][j] |= 1 << ((key * salt) >> 27)
+	}
+}
+
+// check returns true if the hash may have been inserted.
+func (f *bloomFilter) check(hash uint64) bool {
+	i := ((hash >> 32) * uint64(len(f.blocks))) >> 32
+	key := uint32(hash)
+	for j, salt := range bloomFilterSalts {
+		if f.blocks[i][j]&(1<<((key*salt)>>27)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bytes returns the bitset as little endian words.
+func (f *bloomFilter) bytes() []byte {
+	buf := make([]byte, 0, len(f.blocks)*bloomFilterBlockSize)
+	for _, block := range f.blocks {
+		for _, word := range block {
+			buf = binary.LittleEndian.AppendUint32(buf, word)
+		}
+	}
+
+	return buf
+}
+
+// header serializes the BloomFilterHeader, which is missing in the thrift definition of parquet-go.
+// Algorithm, hash and compression are unions of empty structs; BLOCK, XXHASH and UNCOMPRESSED.
+func (f *bloomFilter) header() ([]byte, error) {
+	buf := thrift.NewTMemoryBuffer()
+	p := thrift.NewTCompactProtocolFactory().GetProtocol(buf)
+
+	if err := p.WriteStructBegin("BloomFilterHeader"); err != nil {
+		return nil, err
+	}
+	if err := p.WriteFieldBegin("numBytes", thrift.I32, 1); err != nil {
+		return nil, err
+	}
+	if err := p.WriteI32(int32(len(f.blocks) * bloomFilterBlockSize)); err != nil {
+		return nil, err
+	}
+	if err := p.WriteFieldEnd(); err != nil {
+		return nil, err
+	}
+	for i, name := range []string{"algorithm", "hash", "compression"} {
+		if err := writeEmptyUnion(p, name, int16(i+2)); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.WriteFieldStop(); err != nil {
+		return nil, err
+	}
+	if err := p.WriteStructEnd(); err != nil {
+		return nil, err
+	}
+	if err := p.Flush(context.TODO()); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeEmptyUnion writes a union field whose first member, an empty struct, is set.
+func writeEmptyUnion(p thrift.TProtocol, name string, id int16) error {
+	steps := []func() error{
+		func() error { return p.WriteFieldBegin(name, thrift.STRUCT, id) },
+		func() error { return p.WriteStructBegin(name) },
+		func() error { return p.WriteFieldBegin(name, thrift.STRUCT, 1) },
+		func() error { return p.WriteStructBegin(name) },
+		p.WriteFieldStop,
+		p.WriteStructEnd,
+		p.WriteFieldEnd,
+		p.WriteFieldStop,
+		p.WriteStructEnd,
+		p.WriteFieldEnd,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bloomFilterLocation is where a Bloom filter of a column chunk is written.
+type bloomFilterLocation struct {
+	offset int64
+	length int32
+}
+
+// footerProtocol adds bloom_filter_offset and bloom_filter_length of ColumnMetaData to the footer,
+// which are missing in the thrift definition of parquet-go. Locations are given in the order of column chunks.
+type footerProtocol struct {
+	thrift.TProtocol
+
+	locations []*bloomFilterLocation
+	structs   []string
+	index     int
+}
+
+func (p *footerProtocol) WriteStructBegin(name string) error {
+	p.structs = append(p.structs, name)
+	return p.TProtocol.WriteStructBegin(name)
+}
+
+func (p *footerProtocol) WriteStructEnd() error {
+	if p.structs[len(p.structs)-1] == "ColumnMetaData" {
+		p.index++
+	}
+	p.structs = p.structs[:len(p.structs)-1]
+	return p.TProtocol.WriteStructEnd()
+}
+
+func (p *footerProtocol) WriteFieldStop() error {
+	if p.structs[len(p.structs)-1] == "ColumnMetaData" && p.index < len(p.locations) && p.locations[p.index] != nil {
+		l := p.locations[p.index]
+		if err := p.TProtocol.WriteFieldBegin("bloom_filter_offset", thrift.I64, 14); err != nil {
+			return err
+		}
+		if err := p.TProtocol.WriteI64(l.offset); err != nil {
+			return err
+		}
+		if err := p.TProtocol.WriteFieldEnd(); err != nil {
+			return err
+		}
+		if err := p.TProtocol.WriteFieldBegin("bloom_filter_length", thrift.I32, 15); err != nil {
+			return err
+		}
+		if err := p.TProtocol.WriteI32(l.length); err != nil {
+			return err
+		}
+		if err := p.TProtocol.WriteFieldEnd(); err != nil {
+			return err
+		}
+	}
+
+	return p.TProtocol.WriteFieldStop()
+}
@@ -0,0 +1,201 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	parquetFormat "github.com/xitongsys/parquet-go/parquet"
+)
+
+func TestBloomFilterHash(t *testing.T) {
+	cases := []struct {
+		input    interface{}
+		t        parquetFormat.Type
+		expected uint64
+	}{
+		// byte arrays are hashed without their lengths
+		{input: "", t: parquetFormat.Type_BYTE_ARRAY, expected: 0xef46db3751d8e999},
+		{input: "a", t: parquetFormat.Type_BYTE_ARRAY, expected: 0xd24ec4f1a98c6e5b},
+		{input: "abc", t: parquetFormat.Type_BYTE_ARRAY, expected: 0x44bc2cf5ad770999},
+		// over 32 bytes
+		{input: strings.Repeat("abcdefghijklmnopqrstuvwxyz", 2), t: parquetFormat.Type_BYTE_ARRAY, expected: 0xf2994a649ec488a1},
+		// little endian "abcd" and "abcdefgh"
+		{input: int32(0x64636261), t: parquetFormat.Type_INT32, expected: 0xde0327b0d25d92cc},
+		{input: int64(0x6867666564636261), t: parquetFormat.Type_INT64, expected: 0x3ad351775b4634b7},
+	}
+
+	for _, c := range cases {
+		actual := bloomFilterHash(c.input, c.t)
+
+		if actual != c.expected {
+			t.Errorf("expected: %x, but actual: %x\n", c.expected, actual)
+		}
+	}
+}
+
+func TestBloomFilterSize(t *testing.T) {
+	cases := []struct {
+		ndv      int
+		maxSize  int
+		expected int
+	}{
+		{ndv: 0, maxSize: bloomFilterMaxSize, expected: bloomFilterMinSize},
+		{ndv: 1000, maxSize: bloomFilterMaxSize, expected: 2048},
+		{ndv: 1 << 30, maxSize: bloomFilterMaxSize, expected: bloomFilterMaxSize},
+		{ndv: 1 << 30, maxSize: 1 << 40, expected: bloomFilterMaxSize},
+		// limits are rounded down to powers of 2
+		{ndv: 1000, maxSize: 1500, expected: 1024},
+		{ndv: 1000, maxSize: 0, expected: bloomFilterMinSize},
+	}
+
+	for _, c := range cases {
+		actual := bloomFilterSize(c.ndv, c.maxSize)
+
+		if actual != c.expected {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	f := newBloomFilter(1000, bloomFilterMaxSize)
+	for i := 0; i < 1000; i++ {
+		f.insert(bloomFilterHash(fmt.Sprintf("user-%d", i), parquetFormat.Type_BYTE_ARRAY))
+	}
+
+	for i := 0; i < 1000; i++ {
+		if !f.check(bloomFilterHash(fmt.Sprintf("user-%d", i), parquetFormat.Type_BYTE_ARRAY)) {
+			t.Errorf("expected: %v, but actual: %v\n", true, false)
+		}
+	}
+
+	var positives int
+	for i := 1000; i < 11000; i++ {
+		if f.check(bloomFilterHash(fmt.Sprintf("user-%d", i), parquetFormat.Type_BYTE_ARRAY)) {
+			positives++
+		}
+	}
+	if positives > 300 {
+		t.Errorf("expected: less than %v, but actual: %v\n", 300, positives)
+	}
+
+	if len(f.bytes()) != 2048 {
+		t.Errorf("expected: %v, but actual: %v\n", 2048, len(f.bytes()))
+	}
+}
+
+func TestBloomFilter_Header(t *testing.T) {
+	header, err := newBloomFilter(0, bloomFilterMaxSize).header()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// numBytes of 32, and BLOCK, XXHASH and UNCOMPRESSED of empty structs
+	expected := []byte{0x15, 0x40, 0x1c, 0x1c, 0x00, 0x00, 0x1c, 0x1c, 0x00, 0x00, 0x1c, 0x1c, 0x00, 0x00, 0x00}
+	if !bytes.Equal(header, expected) {
+		t.Errorf("expected: %x, but actual: %x\n", expected, header)
+	}
+
+	buf := thrift.NewTMemoryBuffer()
+	if _, err := buf.Write(header); err != nil {
+		t.Fatal(err)
+	}
+	p := thrift.NewTCompactProtocolFactory().GetProtocol(buf)
+
+	ids := readFieldIds(t, p, map[int16]func(){
+		1: func() {
+			numBytes, err := p.ReadI32()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if numBytes != bloomFilterMinSize {
+				t.Errorf("expected: %v, but actual: %v\n", bloomFilterMinSize, numBytes)
+			}
+		},
+	})
+	if fmt.Sprint(ids) != "[1 2 3 4]" {
+		t.Errorf("expected: %v, but actual: %v\n", "[1 2 3 4]", ids)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected: %v, but actual: %v\n", 0, buf.Len())
+	}
+}
+
+func TestFooterProtocol(t *testing.T) {
+	md := parquetFormat.NewColumnMetaData()
+	md.PathInSchema = []string{"id"}
+
+	buf := thrift.NewTMemoryBuffer()
+	p := &footerProtocol{
+		TProtocol: thrift.NewTCompactProtocolFactory().GetProtocol(buf),
+		locations: []*bloomFilterLocation{{offset: 100, length: 64}},
+	}
+	if err := md.Write(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Flush(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	r := thrift.NewTCompactProtocolFactory().GetProtocol(buf)
+	var offset int64
+	var length int32
+	readFieldIds(t, r, map[int16]func(){
+		14: func() { offset, _ = r.ReadI64() },
+		15: func() { length, _ = r.ReadI32() },
+	})
+	if offset != 100 || length != 64 {
+		t.Errorf("expected: %v, but actual: %v\n", []int64{100, 64}, []int64{offset, int64(length)})
+	}
+}
+
+// readFieldIds reads a struct, and returns ids of its fields. Fields are skipped unless their readers are given.
+func readFieldIds(t *testing.T, p thrift.TProtocol, readers map[int16]func()) []int16 {
+	if _, err := p.ReadStructBegin(); err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []int16
+	for {
+		_, typeId, id, err := p.ReadFieldBegin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typeId == thrift.STOP {
+			break
+		}
+		ids = append(ids, id)
+
+		if read, ok := readers[id]; ok {
+			read()
+		} else if err := p.Skip(typeId); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.ReadFieldEnd(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := p.ReadStructEnd(); err != nil {
+		t.Fatal(err)
+	}
+
+	return ids
+}
+
+// newBloomFilterFromBytes restores a filter from its bitset.
+func newBloomFilterFromBytes(data []byte) *bloomFilter {
+	f := &bloomFilter{blocks: make([][8]uint32, len(data)/bloomFilterBlockSize)}
+	for i := range f.blocks {
+		for j := range f.blocks[i] {
+			f.blocks[i][j] = binary.LittleEndian.Uint32(data[i*bloomFilterBlockSize+j*4:])
+		}
+	}
+
+	return f
+}
@@ -0,0 +1,178 @@
+package parquet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	parquetFormat "github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/schema"
+)
+
+// ColumnOptionsMetadataKey is the key of schema field properties to specify column options,
+// e.g. {"name": "id", "type": "long", "parquet": {"encoding": "DELTA_BINARY_PACKED"}}
+const ColumnOptionsMetadataKey = "parquet"
+
+// EncodingByteStreamSplit is BYTE_STREAM_SPLIT encoding for floating point values,
+// which is missing in the thrift definition of parquet-go.
+const EncodingByteStreamSplit = parquetFormat.Encoding(9)
+
+var ErrInvalidColumnOptions = errors.New("invalid column options")
+
+// ColumnOptions overrides how values of a column are written. Empty values mean the file-wide defaults.
+type ColumnOptions struct {
+	// Codec is the compression codec like SNAPPY, GZIP or ZSTD
+	Codec string `json:"codec,omitempty"`
+	// Dictionary enables or disables dictionary encoding
+	Dictionary *bool `json:"dictionary,omitempty"`
+	// Encoding is the value encoding used without dictionary like PLAIN, DELTA_BINARY_PACKED, DELTA_BYTE_ARRAY,
+	// DELTA_LENGTH_BYTE_ARRAY or BYTE_STREAM_SPLIT
+	Encoding string `json:"encoding,omitempty"`
+	// BloomFilter enables a split block Bloom filter for each column chunk
+	BloomFilter *bool `json:"bloomFilter,omitempty"`
+}
+
+// columnOptions is the validated ColumnOptions for a leaf column.
+type columnOptions struct {
+	// codec is nil for the default one of the file
+	codec       *parquetFormat.CompressionCodec
+	encoding    parquetFormat.Encoding
+	bloomFilter bool
+}
+
+// ReadColumnOptionsFile reads column options keyed by column paths like "name" or "nested.field".
+func ReadColumnOptionsFile(path string) (map[string]ColumnOptions, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var options map[string]ColumnOptions
+	if err := json.Unmarshal(data, &options); err != nil {
+		return nil, fmt.Errorf("%s: %v: %w", path, err, ErrInvalidColumnOptions)
+	}
+
+	return options, nil
+}
+
+// ParseColumnOptions parses column options in a schema field property.
+func ParseColumnOptions(v string) (ColumnOptions, error) {
+	var options ColumnOptions
+	if err := json.Unmarshal([]byte(v), &options); err != nil {
+		return ColumnOptions{}, fmt.Errorf("%s: %v: %w", v, err, ErrInvalidColumnOptions)
+	}
+
+	return options, nil
+}
+
+// ColumnPaths returns paths of leaf columns except the root like "nested.field", keyed by internal paths.
+func ColumnPaths(sh *schema.SchemaHandler) map[string]string {
+	paths := make(map[string]string)
+	for i, e := range sh.SchemaElements {
+		if e.GetNumChildren() > 0 || i == 0 {
+			continue
+		}
+
+		inPath := sh.IndexMap[int32(i)]
+		exPath := strings.Split(sh.InPathToExPath[inPath], ".")
+		paths[inPath] = strings.Join(exPath[1:], ".")
+	}
+
+	return paths
+}
+
+// lookupColumnOptions returns options of the nearest ancestor, so options of a group apply to the columns under it.
+func lookupColumnOptions(options map[string]ColumnOptions, path string) (ColumnOptions, bool) {
+	for p := path; ; {
+		if o, ok := options[p]; ok {
+			return o, true
+		}
+
+		i := strings.LastIndex(p, ".")
+		if i < 0 {
+			return ColumnOptions{}, false
+		}
+		p = p[:i]
+	}
+}
+
+// resolve validates options for the column of given physical type.
+func (o ColumnOptions) resolve(t parquetFormat.Type) (columnOptions, error) {
+	resolved := columnOptions{
+		encoding: parquetFormat.Encoding_PLAIN,
+	}
+
+	if o.Codec != "" {
+		codec, err := parquetFormat.CompressionCodecFromString(strings.ToUpper(o.Codec))
+		if err != nil {
+			return columnOptions{}, fmt.Errorf("codec %s: %w", o.Codec, ErrInvalidColumnOptions)
+		}
+		resolved.codec = &codec
+	}
+
+	if o.Encoding != "" {
+		if o.Dictionary != nil && *o.Dictionary {
+			return columnOptions{}, fmt.Errorf("encoding %s with dictionary: %w", o.Encoding, ErrInvalidColumnOptions)
+		}
+
+		encoding, err := encodingFromString(strings.ToUpper(o.Encoding))
+		if err != nil {
+			return columnOptions{}, err
+		}
+		if !isEncodingSupported(encoding, t) {
+			return columnOptions{}, fmt.Errorf("encoding %s for %v: %w", o.Encoding, t, ErrInvalidColumnOptions)
+		}
+		resolved.encoding = encoding
+	}
+
+	if o.Dictionary != nil && *o.Dictionary {
+		if t == parquetFormat.Type_BOOLEAN {
+			return columnOptions{}, fmt.Errorf("dictionary for %v: %w", t, ErrInvalidColumnOptions)
+		}
+		resolved.encoding = parquetFormat.Encoding_PLAIN_DICTIONARY
+	}
+
+	if o.BloomFilter != nil && *o.BloomFilter {
+		if t == parquetFormat.Type_BOOLEAN {
+			return columnOptions{}, fmt.Errorf("bloom filter for %v: %w", t, ErrInvalidColumnOptions)
+		}
+		resolved.bloomFilter = true
+	}
+
+	return resolved, nil
+}
+
+// isEnumColumn returns true if the column is annotated as ENUM, which is always dictionary encoded.
+func isEnumColumn(e *parquetFormat.SchemaElement) bool {
+	return e.GetConvertedType() == parquetFormat.ConvertedType_ENUM || (e.LogicalType != nil && e.LogicalType.ENUM != nil)
+}
+
+func encodingFromString(s string) (parquetFormat.Encoding, error) {
+	if s == "BYTE_STREAM_SPLIT" {
+		return EncodingByteStreamSplit, nil
+	}
+
+	e, err := parquetFormat.EncodingFromString(s)
+	if err != nil || e == parquetFormat.Encoding_PLAIN_DICTIONARY || e == parquetFormat.Encoding_RLE_DICTIONARY {
+		return 0, fmt.Errorf("encoding %s: %w", s, ErrInvalidColumnOptions)
+	}
+
+	return e, nil
+}
+
+func isEncodingSupported(e parquetFormat.Encoding, t parquetFormat.Type) bool {
+	switch e {
+	case parquetFormat.Encoding_PLAIN:
+		return true
+	case parquetFormat.Encoding_DELTA_BINARY_PACKED:
+		return t == parquetFormat.Type_INT32 || t == parquetFormat.Type_INT64
+	case parquetFormat.Encoding_DELTA_LENGTH_BYTE_ARRAY, parquetFormat.Encoding_DELTA_BYTE_ARRAY:
+		return t == parquetFormat.Type_BYTE_ARRAY
+	case EncodingByteStreamSplit:
+		return t == parquetFormat.Type_FLOAT || t == parquetFormat.Type_DOUBLE
+	}
+
+	return false
+}
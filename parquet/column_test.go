@@ -0,0 +1,125 @@
+package parquet
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	parquetFormat "github.com/xitongsys/parquet-go/parquet"
+)
+
+func boolToPtr(v bool) *bool { return &v }
+
+func TestColumnOptions_resolve(t *testing.T) {
+	zstd := parquetFormat.CompressionCodec_ZSTD
+
+	cases := []struct {
+		options  ColumnOptions
+		t        parquetFormat.Type
+		expected columnOptions
+		isErr    bool
+	}{
+		{
+			options:  ColumnOptions{},
+			t:        parquetFormat.Type_INT64,
+			expected: columnOptions{encoding: parquetFormat.Encoding_PLAIN},
+		},
+		{
+			options:  ColumnOptions{Codec: "zstd", Encoding: "delta_binary_packed"},
+			t:        parquetFormat.Type_INT64,
+			expected: columnOptions{codec: &zstd, encoding: parquetFormat.Encoding_DELTA_BINARY_PACKED},
+		},
+		{
+			options:  ColumnOptions{Dictionary: boolToPtr(true)},
+			t:        parquetFormat.Type_BYTE_ARRAY,
+			expected: columnOptions{encoding: parquetFormat.Encoding_PLAIN_DICTIONARY},
+		},
+		{
+			options:  ColumnOptions{Dictionary: boolToPtr(false), Encoding: "DELTA_BYTE_ARRAY"},
+			t:        parquetFormat.Type_BYTE_ARRAY,
+			expected: columnOptions{encoding: parquetFormat.Encoding_DELTA_BYTE_ARRAY},
+		},
+		{
+			options:  ColumnOptions{Encoding: "BYTE_STREAM_SPLIT"},
+			t:        parquetFormat.Type_DOUBLE,
+			expected: columnOptions{encoding: EncodingByteStreamSplit},
+		},
+		{
+			options:  ColumnOptions{BloomFilter: boolToPtr(true)},
+			t:        parquetFormat.Type_BYTE_ARRAY,
+			expected: columnOptions{encoding: parquetFormat.Encoding_PLAIN, bloomFilter: true},
+		},
+		// Unknown codec
+		{
+			options: ColumnOptions{Codec: "UNKNOWN"},
+			t:       parquetFormat.Type_INT64,
+			isErr:   true,
+		},
+		// Encoding for another type
+		{
+			options: ColumnOptions{Encoding: "BYTE_STREAM_SPLIT"},
+			t:       parquetFormat.Type_BYTE_ARRAY,
+			isErr:   true,
+		},
+		// Dictionary as encoding
+		{
+			options: ColumnOptions{Encoding: "RLE_DICTIONARY"},
+			t:       parquetFormat.Type_INT64,
+			isErr:   true,
+		},
+		// Bloom filter for booleans
+		{
+			options: ColumnOptions{BloomFilter: boolToPtr(true)},
+			t:       parquetFormat.Type_BOOLEAN,
+			isErr:   true,
+		},
+		// Encoding with dictionary
+		{
+			options: ColumnOptions{Dictionary: boolToPtr(true), Encoding: "DELTA_BINARY_PACKED"},
+			t:       parquetFormat.Type_INT64,
+			isErr:   true,
+		},
+	}
+
+	for _, c := range cases {
+		actual, err := c.options.resolve(c.t)
+
+		if err != nil != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrInvalidColumnOptions) {
+			t.Errorf("expected: %v, but actual: %v\n", ErrInvalidColumnOptions, err)
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestLookupColumnOptions(t *testing.T) {
+	options := map[string]ColumnOptions{
+		"tags":        {Codec: "GZIP"},
+		"nested.leaf": {Codec: "ZSTD"},
+	}
+
+	cases := []struct {
+		path     string
+		expected ColumnOptions
+		ok       bool
+	}{
+		{path: "tags.key_value.key", expected: ColumnOptions{Codec: "GZIP"}, ok: true},
+		{path: "nested.leaf", expected: ColumnOptions{Codec: "ZSTD"}, ok: true},
+		{path: "nested.other", ok: false},
+		{path: "tagsx", ok: false},
+	}
+
+	for _, c := range cases {
+		actual, ok := lookupColumnOptions(options, c.path)
+
+		if ok != c.ok || !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v %v, but actual: %v %v\n", c.expected, c.ok, actual, ok)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/xitongsys/parquet-go/encoding"
+	parquetFormat "github.com/xitongsys/parquet-go/parquet"
+)
+
+// emptyDeltaBinaryPacked is the header of DELTA_BINARY_PACKED encoding without values;
+// block size 128, 4 miniblocks, 0 values and the first value 0.
+var emptyDeltaBinaryPacked = []byte{0x80, 0x01, 0x04, 0x00, 0x00}
+
+// encodeValues encodes non-null values of a page. parquet-go encoders are used except ones which don't
+// accept empty pages, and BYTE_STREAM_SPLIT which parquet-go doesn't have.
+func encodeValues(values []interface{}, t parquetFormat.Type, e parquetFormat.Encoding) []byte {
+	switch e {
+	case parquetFormat.Encoding_DELTA_BINARY_PACKED:
+		if len(values) == 0 {
+			return emptyDeltaBinaryPacked
+		}
+		return encoding.WriteDelta(values)
+
+	case parquetFormat.Encoding_DELTA_LENGTH_BYTE_ARRAY:
+		if len(values) == 0 {
+			return emptyDeltaBinaryPacked
+		}
+		return encoding.WriteDeltaLengthByteArray(values)
+
+	case parquetFormat.Encoding_DELTA_BYTE_ARRAY:
+		if len(values) == 0 {
+			// prefix lengths and suffixes with DELTA_LENGTH_BYTE_ARRAY
+			return append(append([]byte{}, emptyDeltaBinaryPacked...), emptyDeltaBinaryPacked...)
+		}
+		return encoding.WriteDeltaByteArray(values)
+
+	case EncodingByteStreamSplit:
+		return writeByteStreamSplit(values, t)
+	}
+
+	return encoding.WritePlain(values, t)
+}
+
+// writeByteStreamSplit scatters bytes of little endian values into streams of each byte position,
+// which are compressed better than plain values.
+func writeByteStreamSplit(values []interface{}, t parquetFormat.Type) []byte {
+	width := 8
+	if t == parquetFormat.Type_FLOAT {
+		width = 4
+	}
+
+	n := len(values)
+	buf := make([]byte, n*width)
+	b := make([]byte, width)
+	for i, v := range values {
+		if width == 4 {
+			binary.LittleEndian.PutUint32(b, math.Float32bits(v.(float32)))
+		} else {
+			binary.LittleEndian.PutUint64(b, math.Float64bits(v.(float64)))
+		}
+		for j := 0; j < width; j++ {
+			buf[j*n+i] = b[j]
+		}
+	}
+
+	return buf
+}
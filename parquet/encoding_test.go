@@ -0,0 +1,66 @@
+package parquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xitongsys/parquet-go/encoding"
+	parquetFormat "github.com/xitongsys/parquet-go/parquet"
+)
+
+func TestWriteByteStreamSplit(t *testing.T) {
+	cases := []struct {
+		values   []interface{}
+		t        parquetFormat.Type
+		expected []byte
+	}{
+		{
+			// 1.0 is 0x3f800000, 2.0 is 0x40000000
+			values:   []interface{}{float32(1.0), float32(2.0)},
+			t:        parquetFormat.Type_FLOAT,
+			expected: []byte{0x00, 0x00, 0x00, 0x00, 0x80, 0x00, 0x3f, 0x40},
+		},
+		{
+			// 1.0 is 0x3ff0000000000000
+			values:   []interface{}{float64(1.0)},
+			t:        parquetFormat.Type_DOUBLE,
+			expected: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf0, 0x3f},
+		},
+		{
+			values:   []interface{}{},
+			t:        parquetFormat.Type_DOUBLE,
+			expected: []byte{},
+		},
+	}
+
+	for _, c := range cases {
+		actual := writeByteStreamSplit(c.values, c.t)
+
+		if !bytes.Equal(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestEncodeValues_Empty(t *testing.T) {
+	for _, e := range []parquetFormat.Encoding{
+		parquetFormat.Encoding_DELTA_BINARY_PACKED,
+		parquetFormat.Encoding_DELTA_LENGTH_BYTE_ARRAY,
+		parquetFormat.Encoding_DELTA_BYTE_ARRAY,
+	} {
+		buf := encodeValues([]interface{}{}, parquetFormat.Type_BYTE_ARRAY, e)
+
+		// each delta header should be decoded as no values
+		r := bytes.NewReader(buf)
+		for r.Len() > 0 {
+			values, err := encoding.ReadDeltaBinaryPackedINT(r)
+			if err != nil {
+				t.Errorf("expected success for %v, but actual %v", e, err)
+				break
+			}
+			if len(values) != 0 {
+				t.Errorf("expected no values for %v, but actual %v", e, values)
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// julianDayOfEpoch is the Julian day of 1970-01-01.
+const julianDayOfEpoch = 2440588
+
+// int96FromEpoch encodes an epoch value of the unit as INT96 timestamp of Hive and Impala,
+// nanoseconds of the day and the Julian day in little endian.
+func int96FromEpoch(v int64, unit time.Duration) string {
+	perDay := int64(24 * time.Hour / unit)
+	days := v / perDay
+	rem := v % perDay
+	if rem < 0 {
+		days--
+		rem += perDay
+	}
+
+	buf := make([]byte, 12)
+	binary.LittleEndian.PutUint64(buf[:8], uint64(rem*int64(unit)))
+	binary.LittleEndian.PutUint32(buf[8:], uint32(days+julianDayOfEpoch))
+
+	return string(buf)
+}
+
+// convertInt96Timestamps converts INT96 values marshaled from epoch integers to INT96 timestamps.
+// parquet-go marshals integers to INT96 as 12 bytes two's complement in little endian, whose lower 8 bytes are int64.
+func convertInt96Timestamps(values []interface{}, unit time.Duration) error {
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+
+		s, ok := v.(string)
+		if !ok || len(s) != 12 {
+			return fmt.Errorf("unexpected INT96 value %v: %w", v, ErrInvalidRecord)
+		}
+		values[i] = int96FromEpoch(int64(binary.LittleEndian.Uint64([]byte(s[:8]))), unit)
+	}
+
+	return nil
+}
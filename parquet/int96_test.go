@@ -0,0 +1,54 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestInt96FromEpoch(t *testing.T) {
+	cases := []struct {
+		v             int64
+		unit          time.Duration
+		expectedNanos uint64
+		expectedDay   uint32
+	}{
+		{v: 0, unit: time.Millisecond, expectedNanos: 0, expectedDay: 2440588},
+		{v: 86401000, unit: time.Millisecond, expectedNanos: 1000000000, expectedDay: 2440589},
+		{v: 1500, unit: time.Microsecond, expectedNanos: 1500000, expectedDay: 2440588},
+		// before the epoch
+		{v: -1, unit: time.Millisecond, expectedNanos: 86399999000000, expectedDay: 2440587},
+		{v: -86400, unit: time.Second, expectedNanos: 0, expectedDay: 2440587},
+	}
+
+	for _, c := range cases {
+		actual := []byte(int96FromEpoch(c.v, c.unit))
+
+		expected := make([]byte, 12)
+		binary.LittleEndian.PutUint64(expected[:8], c.expectedNanos)
+		binary.LittleEndian.PutUint32(expected[8:], c.expectedDay)
+		if !bytes.Equal(actual, expected) {
+			t.Errorf("expected: %v, but actual: %v\n", expected, actual)
+		}
+	}
+}
+
+func TestConvertInt96Timestamps(t *testing.T) {
+	// -1 in 12 bytes two's complement, as parquet-go marshals
+	values := []interface{}{nil, "\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff\xff"}
+	if err := convertInt96Timestamps(values, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []interface{}{nil, int96FromEpoch(-1, time.Millisecond)}
+	for i := range values {
+		if values[i] != expected[i] {
+			t.Errorf("expected: %v, but actual: %v\n", expected[i], values[i])
+		}
+	}
+
+	if err := convertInt96Timestamps([]interface{}{"short"}, time.Millisecond); err == nil {
+		t.Errorf("expected: %v, but actual: %v\n", ErrInvalidRecord, err)
+	}
+}
@@ -0,0 +1,309 @@
+package parquet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+
+	interop "github.com/parquet-go/parquet-go"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/schema"
+)
+
+// interopColumn is a column chunk read by parquet-go/parquet-go, which is independent of xitongsys/parquet-go.
+type interopColumn struct {
+	values     []string
+	dictPages  int // data pages of dictionary indexes
+	plainPages int // data pages of values
+}
+
+// readInterop reads column chunks of the first row group with parquet-go/parquet-go.
+func readInterop(t *testing.T, data []byte) (*interop.File, []*interopColumn) {
+	f, err := interop.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var columns []*interopColumn
+	for _, cc := range f.RowGroups()[0].ColumnChunks() {
+		c := &interopColumn{}
+		pages := cc.Pages()
+		for {
+			p, err := pages.ReadPage()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Dictionary() != nil {
+				c.dictPages++
+			} else {
+				c.plainPages++
+			}
+
+			values := make([]interop.Value, p.NumValues())
+			n, err := p.Values().ReadValues(values)
+			if err != nil && !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+			for _, v := range values[:n] {
+				c.values = append(c.values, formatInteropValue(v))
+			}
+		}
+		if err := pages.Close(); err != nil {
+			t.Fatal(err)
+		}
+		columns = append(columns, c)
+	}
+
+	return f, columns
+}
+
+func formatInteropValue(v interop.Value) string {
+	switch {
+	case v.IsNull():
+		return "<nil>"
+	case v.Kind() == interop.Double:
+		return strconv.FormatFloat(v.Double(), 'g', -1, 64)
+	}
+
+	return v.String()
+}
+
+// writeInteropRows writes 100 rows of writerTestSchema, and returns expected values of each column.
+func writeInteropRows(t *testing.T, w *Writer) [][]string {
+	expected := make([][]string, 3)
+	for i := 0; i < 100; i++ {
+		v := fmt.Sprintf(`{"id": %d`, i)
+		expected[0] = append(expected[0], strconv.Itoa(i))
+		if i%3 == 0 {
+			expected[1] = append(expected[1], "<nil>")
+		} else {
+			v += fmt.Sprintf(`, "score": %v`, float64(i)*0.5)
+			expected[1] = append(expected[1], strconv.FormatFloat(float64(i)*0.5, 'g', -1, 64))
+		}
+		if i%5 == 0 {
+			expected[2] = append(expected[2], "<nil>")
+		} else {
+			v += fmt.Sprintf(`, "name": "user-%d"`, i%37)
+			expected[2] = append(expected[2], fmt.Sprintf("user-%d", i%37))
+		}
+
+		if err := w.Write(v + "}"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteStop(); err != nil {
+		t.Fatal(err)
+	}
+
+	return expected
+}
+
+func TestWriter_Interop(t *testing.T) {
+	cases := []struct {
+		options            map[string]ColumnOptions
+		dictionaryPageSize int64
+		// dictionary pages of each column, which are -1 if the column falls back to PLAIN
+		dictionary []int
+	}{
+		// default PLAIN and SNAPPY
+		{
+			options:    map[string]ColumnOptions{},
+			dictionary: []int{0, 0, 0},
+		},
+		// delta encodings, byte stream split and codecs
+		{
+			options: map[string]ColumnOptions{
+				"id":    {Encoding: "DELTA_BINARY_PACKED", Codec: "GZIP"},
+				"score": {Encoding: "BYTE_STREAM_SPLIT", Codec: "ZSTD"},
+				"name":  {Encoding: "DELTA_BYTE_ARRAY", Codec: "UNCOMPRESSED"},
+			},
+			dictionary: []int{0, 0, 0},
+		},
+		{
+			options: map[string]ColumnOptions{
+				"name": {Encoding: "DELTA_LENGTH_BYTE_ARRAY"},
+			},
+			dictionary: []int{0, 0, 0},
+		},
+		// dictionaries of all pages
+		{
+			options: map[string]ColumnOptions{
+				"id":   {Dictionary: boolToPtr(true)},
+				"name": {Dictionary: boolToPtr(true)},
+			},
+			dictionary: []int{1, 0, 1},
+		},
+		// dictionaries exceeding the limit fall back to PLAIN
+		{
+			options: map[string]ColumnOptions{
+				"id":   {Dictionary: boolToPtr(true)},
+				"name": {Dictionary: boolToPtr(true)},
+			},
+			dictionaryPageSize: 64,
+			dictionary:         []int{-1, 0, -1},
+		},
+	}
+
+	for _, c := range cases {
+		sh, err := schema.NewSchemaHandlerFromJSON(writerTestSchema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := buffer.NewBufferFile(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := NewWriter(f, sh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.MarshalFunc = MarshalJSON
+		w.PageSize = 64
+		if c.dictionaryPageSize > 0 {
+			w.DictionaryPageSize = c.dictionaryPageSize
+		}
+		if err := w.SetColumnOptions(c.options); err != nil {
+			t.Fatal(err)
+		}
+		expected := writeInteropRows(t, w)
+
+		_, columns := readInterop(t, f.(buffer.BufferFile).Bytes())
+		for i, column := range columns {
+			if fmt.Sprint(column.values) != fmt.Sprint(expected[i]) {
+				t.Errorf("expected: %v, but actual: %v\n", expected[i], column.values)
+			}
+
+			switch c.dictionary[i] {
+			case 0:
+				if column.dictPages != 0 {
+					t.Errorf("expected: %v, but actual: %v\n", "no dictionaries", column)
+				}
+			case 1:
+				if column.plainPages != 0 {
+					t.Errorf("expected: %v, but actual: %v\n", "only dictionaries", column)
+				}
+			default:
+				if column.dictPages == 0 || column.plainPages == 0 {
+					t.Errorf("expected: %v, but actual: %v\n", "dictionaries and PLAIN", column)
+				}
+			}
+		}
+	}
+}
+
+func TestWriter_Interop_PageIndex(t *testing.T) {
+	sh, err := schema.NewSchemaHandlerFromJSON(writerTestSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := buffer.NewBufferFile(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWriter(f, sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.MarshalFunc = MarshalJSON
+	w.PageSize = 64
+	w.PageIndex = true
+	writeInteropRows(t, w)
+
+	file, _ := readInterop(t, f.(buffer.BufferFile).Bytes())
+	cc := file.RowGroups()[0].ColumnChunks()[0]
+	columnIndex, err := cc.ColumnIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	offsetIndex, err := cc.OffsetIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if columnIndex.NumPages() < 2 || columnIndex.NumPages() != offsetIndex.NumPages() || !columnIndex.IsAscending() {
+		t.Fatalf("expected: %v, but actual: %v and %v pages\n", "ascending pages", columnIndex.NumPages(), offsetIndex.NumPages())
+	}
+
+	// pages cover all rows in order
+	last := columnIndex.NumPages() - 1
+	if columnIndex.MinValue(0).Int64() != 0 || columnIndex.MaxValue(last).Int64() != 99 || offsetIndex.FirstRowIndex(0) != 0 {
+		t.Errorf("expected: %v, but actual: %v-%v\n", "0-99", columnIndex.MinValue(0), columnIndex.MaxValue(last))
+	}
+	for i := 1; i <= last; i++ {
+		if offsetIndex.FirstRowIndex(i) != columnIndex.MinValue(i).Int64() {
+			t.Errorf("expected: %v, but actual: %v\n", columnIndex.MinValue(i), offsetIndex.FirstRowIndex(i))
+		}
+	}
+	for i := 0; i <= last; i++ {
+		if columnIndex.NullPage(i) {
+			t.Errorf("expected: %v, but actual: %v\n", "no null pages", i)
+		}
+	}
+}
+
+func TestWriter_Interop_BloomFilter(t *testing.T) {
+	cases := []struct {
+		bloomFilterMaxSize int
+		expectedSize       int64
+	}{
+		{bloomFilterMaxSize: 1024 * 1024, expectedSize: 128},
+		// id column of 100 values fills the filter of the limit
+		{bloomFilterMaxSize: 32, expectedSize: 32},
+	}
+
+	for _, c := range cases {
+		sh, err := schema.NewSchemaHandlerFromJSON(writerTestSchema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := buffer.NewBufferFile(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := NewWriter(f, sh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.MarshalFunc = MarshalJSON
+		w.BloomFilterMaxSize = c.bloomFilterMaxSize
+		err = w.SetColumnOptions(map[string]ColumnOptions{
+			"id":   {BloomFilter: boolToPtr(true)},
+			"name": {BloomFilter: boolToPtr(true)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeInteropRows(t, w)
+
+		file, _ := readInterop(t, f.(buffer.BufferFile).Bytes())
+		columns := file.RowGroups()[0].ColumnChunks()
+		if filter := columns[1].BloomFilter(); filter != nil {
+			t.Errorf("expected: %v, but actual: %v\n", nil, filter)
+		}
+
+		id := columns[0].BloomFilter()
+		if id == nil || id.Size() != c.expectedSize {
+			t.Fatalf("expected: %v, but actual: %v\n", c.expectedSize, id)
+		}
+		for i := 0; i < 100; i++ {
+			if ok, err := id.Check(interop.ValueOf(int64(i))); err != nil || !ok {
+				t.Errorf("expected: %v, but actual: %v, %v\n", true, ok, err)
+			}
+		}
+
+		name := columns[2].BloomFilter()
+		if name == nil {
+			t.Fatalf("expected: %v, but actual: %v\n", "a Bloom filter", name)
+		}
+		for i := 1; i < 37; i++ {
+			if ok, err := name.Check(interop.ValueOf(fmt.Sprintf("user-%d", i))); err != nil || !ok {
+				t.Errorf("expected: %v, but actual: %v, %v\n", true, ok, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,432 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/layout"
+	parquetFormat "github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/schema"
+	"github.com/xitongsys/parquet-go/types"
+)
+
+var ErrInvalidRecord = errors.New("invalid record")
+
+// schemaNode is a schema element with its children, built from flattened schema elements.
+type schemaNode struct {
+	index    int32
+	path     string
+	name     string
+	elem     *parquetFormat.SchemaElement
+	children []*schemaNode
+	maxRL    int32
+}
+
+func (n *schemaNode) isLeaf() bool { return len(n.children) == 0 }
+
+func (n *schemaNode) isList() bool {
+	return n.elem.GetConvertedType() == parquetFormat.ConvertedType_LIST ||
+		(n.elem.LogicalType != nil && n.elem.LogicalType.LIST != nil)
+}
+
+func (n *schemaNode) isMap() bool {
+	return n.elem.GetConvertedType() == parquetFormat.ConvertedType_MAP ||
+		n.elem.GetConvertedType() == parquetFormat.ConvertedType_MAP_KEY_VALUE ||
+		(n.elem.LogicalType != nil && n.elem.LogicalType.MAP != nil)
+}
+
+// isListElementWrapper returns true if the repeated group of a list wraps its element, i.e. three-level lists.
+// Names of two-level lists with group elements follow backward compatibility rules of the spec.
+func (n *schemaNode) isListElementWrapper(list *schemaNode) bool {
+	return len(n.children) == 1 && n.name != "array" && n.name != list.name+"_tuple"
+}
+
+// MarshalJSON converts JSON records to column values like marshal.MarshalJSON of parquet-go. It walks the schema
+// instead of values, so missing fields, null and empty lists, and null list elements get the right definition levels.
+func MarshalJSON(records []interface{}, sh *schema.SchemaHandler) (*map[string]*layout.Table, error) {
+	root, err := newSchemaTree(sh)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &jsonMarshaller{tables: make(map[string]*layout.Table)}
+	for i, e := range sh.SchemaElements {
+		if e.GetNumChildren() > 0 || i == 0 {
+			continue
+		}
+
+		path := sh.IndexMap[int32(i)]
+		t := layout.NewEmptyTable()
+		t.Path = common.StrToPath(path)
+		t.MaxDefinitionLevel, _ = sh.MaxDefinitionLevel(t.Path)
+		t.MaxRepetitionLevel, _ = sh.MaxRepetitionLevel(t.Path)
+		t.RepetitionType = e.GetRepetitionType()
+		t.Schema = e
+		t.Info = sh.Infos[i]
+		m.tables[path] = t
+	}
+
+	for _, r := range records {
+		s, ok := r.(string)
+		if !ok {
+			return nil, fmt.Errorf("record %v is not a JSON string: %w", r, ErrInvalidRecord)
+		}
+
+		var v interface{}
+		d := json.NewDecoder(strings.NewReader(s))
+		d.UseNumber()
+		if err := d.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%v: %w", err, ErrInvalidRecord)
+		}
+		if v == nil {
+			return nil, fmt.Errorf("record is null: %w", ErrInvalidRecord)
+		}
+
+		if err := m.writeValue(root, v, 0, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	return &m.tables, nil
+}
+
+// newSchemaTree restores the tree of schema elements, which are flattened in depth-first order.
+func newSchemaTree(sh *schema.SchemaHandler) (*schemaNode, error) {
+	var next int32
+	var build func() (*schemaNode, error)
+	build = func() (*schemaNode, error) {
+		if int(next) >= len(sh.SchemaElements) {
+			return nil, fmt.Errorf("broken schema: %w", ErrInvalidRecord)
+		}
+
+		i := next
+		next++
+		path := sh.IndexMap[i]
+		n := &schemaNode{
+			index: i,
+			path:  path,
+			name:  sh.Infos[i].ExName,
+			elem:  sh.SchemaElements[i],
+		}
+		n.maxRL, _ = sh.MaxRepetitionLevel(common.StrToPath(path))
+
+		for j := int32(0); j < n.elem.GetNumChildren(); j++ {
+			child, err := build()
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, child)
+		}
+
+		return n, nil
+	}
+
+	return build()
+}
+
+// jsonMarshaller accumulates column values of leaves keyed by internal paths.
+type jsonMarshaller struct {
+	tables map[string]*layout.Table
+}
+
+// writeField writes a field value, which is nil if it's missing or null. dl is the definition level of the parent.
+func (m *jsonMarshaller) writeField(n *schemaNode, v interface{}, rl, dl int32) error {
+	switch n.elem.GetRepetitionType() {
+	case parquetFormat.FieldRepetitionType_REPEATED:
+		// legacy two-level lists, or repeated groups of lists and maps whose values are given by writeValue
+		if v == nil {
+			m.writeNulls(n, rl, dl)
+			return nil
+		}
+		vs, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected an array for %s, but actual: %v: %w", n.path, v, ErrInvalidRecord)
+		}
+		return m.writeRepeated(n, vs, rl, dl)
+
+	case parquetFormat.FieldRepetitionType_OPTIONAL:
+		if v == nil {
+			m.writeNulls(n, rl, dl)
+			return nil
+		}
+		return m.writeValue(n, v, rl, dl+1)
+	}
+
+	if v == nil {
+		return fmt.Errorf("required field %s is missing: %w", n.path, ErrInvalidRecord)
+	}
+
+	return m.writeValue(n, v, rl, dl)
+}
+
+// writeRepeated writes elements of a repeated field. An empty array is written as a null at the parent level.
+func (m *jsonMarshaller) writeRepeated(n *schemaNode, vs []interface{}, rl, dl int32) error {
+	if len(vs) == 0 {
+		m.writeNulls(n, rl, dl)
+		return nil
+	}
+
+	for i, e := range vs {
+		r := n.maxRL
+		if i == 0 {
+			r = rl
+		}
+		if e == nil {
+			return fmt.Errorf("null element of repeated %s can't be written: %w", n.path, ErrInvalidRecord)
+		}
+		if err := m.writeValue(n, e, r, dl+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeValue writes a non-null value defined at the level dl.
+func (m *jsonMarshaller) writeValue(n *schemaNode, v interface{}, rl, dl int32) error {
+	if n.isLeaf() {
+		t := m.tables[n.path]
+		val, err := leafValue(n.elem, v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", n.path, err)
+		}
+		t.Values = append(t.Values, val)
+		t.DefinitionLevels = append(t.DefinitionLevels, dl)
+		t.RepetitionLevels = append(t.RepetitionLevels, rl)
+		return nil
+	}
+
+	switch {
+	case n.isList() && len(n.children) == 1 && n.children[0].elem.GetRepetitionType() == parquetFormat.FieldRepetitionType_REPEATED:
+		return m.writeList(n, v, rl, dl)
+
+	case n.isMap() && len(n.children) == 1 && n.children[0].elem.GetRepetitionType() == parquetFormat.FieldRepetitionType_REPEATED:
+		return m.writeMap(n, v, rl, dl)
+	}
+
+	record, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected an object for %s, but actual: %v: %w", n.path, v, ErrInvalidRecord)
+	}
+	for _, child := range n.children {
+		if err := m.writeField(child, record[child.name], rl, dl); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// leafValue converts a JSON value to the representation of the column. Decimals and intervals are converted here,
+// because parquet-go loses precision of wide decimals and takes intervals as integers.
+// Objects and arrays of byte array columns are written as JSON texts, and strings of binary columns are bytes as
+// code points 0-255 like the JSON encoding of Avro bytes.
+func leafValue(e *parquetFormat.SchemaElement, v interface{}) (interface{}, error) {
+	switch {
+	case e.GetConvertedType() == parquetFormat.ConvertedType_DECIMAL || (e.LogicalType != nil && e.LogicalType.DECIMAL != nil):
+		return decimalValue(e, v)
+
+	case e.GetConvertedType() == parquetFormat.ConvertedType_INTERVAL:
+		if m, ok := v.(map[string]interface{}); ok {
+			return intervalValue(m)
+		}
+	}
+
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		if e.GetType() == parquetFormat.Type_BYTE_ARRAY {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %w", err, ErrInvalidRecord)
+			}
+			return string(data), nil
+		}
+
+	case string:
+		if isBinary(e) {
+			return binaryValue(v.(string))
+		}
+	}
+
+	val := types.JSONTypeToParquetType(reflect.ValueOf(v), e.Type, e.ConvertedType, int(e.GetTypeLength()), int(e.GetScale()))
+	if val == nil {
+		return nil, fmt.Errorf("unable to convert %v to %v: %w", v, e.GetType(), ErrInvalidRecord)
+	}
+	if s, ok := val.(string); ok && e.GetType() == parquetFormat.Type_FIXED_LEN_BYTE_ARRAY && len(s) != int(e.GetTypeLength()) {
+		return nil, fmt.Errorf("%d bytes of %q don't fit FIXED_LEN_BYTE_ARRAY(%d): %w", len(s), s, e.GetTypeLength(), ErrInvalidRecord)
+	}
+
+	return val, nil
+}
+
+// isBinary returns true if the column is bytes without annotations, or a UUID.
+func isBinary(e *parquetFormat.SchemaElement) bool {
+	if e.GetType() != parquetFormat.Type_BYTE_ARRAY && e.GetType() != parquetFormat.Type_FIXED_LEN_BYTE_ARRAY {
+		return false
+	}
+
+	return e.ConvertedType == nil && (e.LogicalType == nil || e.LogicalType.UUID != nil)
+}
+
+// binaryValue converts a string of code points 0-255 to the bytes.
+func binaryValue(v string) (interface{}, error) {
+	b := make([]byte, 0, len(v))
+	for _, r := range v {
+		if r > 0xff {
+			return nil, fmt.Errorf("invalid bytes %q: %w", v, ErrInvalidRecord)
+		}
+		b = append(b, byte(r))
+	}
+
+	return string(b), nil
+}
+
+// decimalValue converts a decimal number or string to the unscaled integer of the physical type exactly.
+// Values which have more digits than the scale or the precision are rejected instead of being rounded.
+func decimalValue(e *parquetFormat.SchemaElement, v interface{}) (interface{}, error) {
+	precision, scale := e.GetPrecision(), e.GetScale()
+	if lt := e.LogicalType; lt != nil && lt.DECIMAL != nil {
+		precision, scale = lt.DECIMAL.Precision, lt.DECIMAL.Scale
+	}
+
+	r, ok := new(big.Rat).SetString(fmt.Sprint(v))
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %v: %w", v, ErrInvalidRecord)
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("decimal %v has more digits than scale %d: %w", v, scale, ErrInvalidRecord)
+	}
+	unscaled := r.Num()
+	if new(big.Int).Abs(unscaled).Cmp(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(precision)), nil)) >= 0 {
+		return nil, fmt.Errorf("decimal %v exceeds precision %d: %w", v, precision, ErrInvalidRecord)
+	}
+
+	switch e.GetType() {
+	case parquetFormat.Type_INT32:
+		return int32(unscaled.Int64()), nil
+	case parquetFormat.Type_INT64:
+		return unscaled.Int64(), nil
+	case parquetFormat.Type_FIXED_LEN_BYTE_ARRAY:
+		return string(twosComplement(unscaled, int(e.GetTypeLength()))), nil
+	}
+
+	// minimum bytes with the sign bit
+	return string(twosComplement(unscaled, unscaled.BitLen()/8+1)), nil
+}
+
+// twosComplement returns big-endian two's complement of the integer in n bytes.
+func twosComplement(x *big.Int, n int) []byte {
+	if x.Sign() < 0 {
+		x = new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), uint(n*8)), x)
+	}
+
+	return x.FillBytes(make([]byte, n))
+}
+
+// intervalValue converts an object of months, days and milliseconds to INTERVAL, three little-endian uint32.
+func intervalValue(m map[string]interface{}) (interface{}, error) {
+	buf := make([]byte, 12)
+	for i, k := range []string{"months", "days", "milliseconds"} {
+		var n uint64
+		if v, ok := m[k]; ok && v != nil {
+			var err error
+			if n, err = strconv.ParseUint(fmt.Sprint(v), 10, 32); err != nil {
+				return nil, fmt.Errorf("invalid interval %s %v: %w", k, v, ErrInvalidRecord)
+			}
+		}
+		binary.LittleEndian.PutUint32(buf[i*4:], uint32(n))
+	}
+
+	return string(buf), nil
+}
+
+// writeList writes an array to a LIST annotated group. Elements of three-level lists can be null if they're optional.
+func (m *jsonMarshaller) writeList(n *schemaNode, v interface{}, rl, dl int32) error {
+	vs, ok := v.([]interface{})
+	if !ok {
+		return fmt.Errorf("expected an array for %s, but actual: %v: %w", n.path, v, ErrInvalidRecord)
+	}
+
+	repeated := n.children[0]
+	if !repeated.isListElementWrapper(n) {
+		return m.writeRepeated(repeated, vs, rl, dl)
+	}
+
+	if len(vs) == 0 {
+		m.writeNulls(n, rl, dl)
+		return nil
+	}
+	for i, e := range vs {
+		r := repeated.maxRL
+		if i == 0 {
+			r = rl
+		}
+		if err := m.writeField(repeated.children[0], e, r, dl+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeMap writes an object to a MAP annotated group. Keys are sorted for reproducible outputs.
+func (m *jsonMarshaller) writeMap(n *schemaNode, v interface{}, rl, dl int32) error {
+	kvs, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected an object for %s, but actual: %v: %w", n.path, v, ErrInvalidRecord)
+	}
+
+	keyValue := n.children[0]
+	if len(kvs) == 0 {
+		m.writeNulls(n, rl, dl)
+		return nil
+	}
+	if len(keyValue.children) != 2 {
+		return fmt.Errorf("map %s must have key and value: %w", n.path, ErrInvalidRecord)
+	}
+
+	keys := make([]string, 0, len(kvs))
+	for k := range kvs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		r := keyValue.maxRL
+		if i == 0 {
+			r = rl
+		}
+		if err := m.writeField(keyValue.children[0], k, r, dl+1); err != nil {
+			return err
+		}
+		if err := m.writeField(keyValue.children[1], kvs[k], r, dl+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeNulls writes nulls to all leaves under the node, which are defined up to the level dl.
+func (m *jsonMarshaller) writeNulls(n *schemaNode, rl, dl int32) {
+	if n.isLeaf() {
+		t := m.tables[n.path]
+		t.Values = append(t.Values, nil)
+		t.DefinitionLevels = append(t.DefinitionLevels, dl)
+		t.RepetitionLevels = append(t.RepetitionLevels, rl)
+		return
+	}
+
+	for _, child := range n.children {
+		m.writeNulls(child, rl, dl)
+	}
+}
@@ -0,0 +1,267 @@
+package parquet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	columnifySchema "github.com/reproio/columnify/schema"
+	parquetFormat "github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/schema"
+)
+
+func newMarshalTestSchemaHandler() *schema.SchemaHandler {
+	required := parquetFormat.FieldRepetitionTypePtr(parquetFormat.FieldRepetitionType_REQUIRED)
+	optional := parquetFormat.FieldRepetitionTypePtr(parquetFormat.FieldRepetitionType_OPTIONAL)
+	repeated := parquetFormat.FieldRepetitionTypePtr(parquetFormat.FieldRepetitionType_REPEATED)
+	int64Type := parquetFormat.TypePtr(parquetFormat.Type_INT64)
+	byteArray := parquetFormat.TypePtr(parquetFormat.Type_BYTE_ARRAY)
+	utf8 := parquetFormat.ConvertedTypePtr(parquetFormat.ConvertedType_UTF8)
+	one, two, four := int32(1), int32(2), int32(4)
+
+	return schema.NewSchemaHandlerFromSchemaList([]*parquetFormat.SchemaElement{
+		{Name: "root", RepetitionType: required, NumChildren: &four},
+		{Name: "id", Type: int64Type, RepetitionType: required},
+		// three-level list of nullable strings
+		{Name: "tags", RepetitionType: optional, NumChildren: &one, ConvertedType: parquetFormat.ConvertedTypePtr(parquetFormat.ConvertedType_LIST)},
+		{Name: "list", RepetitionType: repeated, NumChildren: &one},
+		{Name: "element", Type: byteArray, RepetitionType: optional, ConvertedType: utf8},
+		// legacy two-level list
+		{Name: "nums", Type: int64Type, RepetitionType: repeated},
+		// map of nullable values
+		{Name: "attrs", RepetitionType: optional, NumChildren: &one, ConvertedType: parquetFormat.ConvertedTypePtr(parquetFormat.ConvertedType_MAP)},
+		{Name: "key_value", RepetitionType: repeated, NumChildren: &two, ConvertedType: parquetFormat.ConvertedTypePtr(parquetFormat.ConvertedType_MAP_KEY_VALUE)},
+		{Name: "key", Type: byteArray, RepetitionType: required, ConvertedType: utf8},
+		{Name: "value", Type: int64Type, RepetitionType: optional},
+	})
+}
+
+func TestMarshalJSON(t *testing.T) {
+	cases := []struct {
+		records  []interface{}
+		index    int32 // of the leaf in the schema
+		expected string
+	}{
+		// null list, empty list, and a list with a null element
+		{
+			records:  []interface{}{`{"id": 1, "tags": null}`, `{"id": 2, "tags": []}`, `{"id": 3, "tags": ["a", null]}`},
+			index:    4,
+			expected: "[<nil> <nil> a <nil>] [0 1 3 2] [0 0 0 1]",
+		},
+		// missing list is the same as null
+		{
+			records:  []interface{}{`{"id": 1}`},
+			index:    4,
+			expected: "[<nil>] [0] [0]",
+		},
+		// legacy list can't distinguish null and empty
+		{
+			records:  []interface{}{`{"id": 1, "nums": null}`, `{"id": 2, "nums": []}`, `{"id": 3, "nums": [1, 2]}`},
+			index:    5,
+			expected: "[<nil> <nil> 1 2] [0 0 1 1] [0 0 0 1]",
+		},
+		// map keys are sorted
+		{
+			records:  []interface{}{`{"id": 1, "attrs": {"b": 1, "a": null}}`, `{"id": 2, "attrs": {}}`},
+			index:    8,
+			expected: "[a b <nil>] [2 2 1] [0 1 0]",
+		},
+		{
+			records:  []interface{}{`{"id": 1, "attrs": {"b": 1, "a": null}}`, `{"id": 2, "attrs": {}}`},
+			index:    9,
+			expected: "[<nil> 1 <nil>] [2 3 1] [0 1 0]",
+		},
+	}
+
+	for _, c := range cases {
+		sh := newMarshalTestSchemaHandler()
+		tables, err := MarshalJSON(c.records, sh)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		table := (*tables)[sh.IndexMap[c.index]]
+		actual := fmt.Sprint(table.Values, table.DefinitionLevels, table.RepetitionLevels)
+		if actual != c.expected {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestMarshalJSON_ListElementNullability(t *testing.T) {
+	s, err := columnifySchema.NewSchemaFromAvroSchema([]byte(`
+{
+  "type": "record",
+  "name": "Lists",
+  "fields": [
+    {"name": "required_list", "type": {"type": "array", "items": ["null", "string"]}},
+    {"name": "optional_list", "type": ["null", {"type": "array", "items": "string"}]}
+  ]
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh, err := columnifySchema.NewSchemaHandlerFromArrow(*s, columnifySchema.ParquetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []interface{}{
+		`{"required_list": ["a", null], "optional_list": null}`,
+		`{"required_list": [], "optional_list": ["b"]}`,
+	}
+	tables, err := MarshalJSON(records, sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		index    int32
+		expected string
+	}{
+		// required list of nullable elements
+		{index: 3, expected: "[a <nil> <nil>] [2 1 0] [0 1 0]"},
+		// optional list of required elements
+		{index: 6, expected: "[<nil> b] [0 2] [0 0]"},
+	}
+	for _, c := range cases {
+		table := (*tables)[sh.IndexMap[c.index]]
+		actual := fmt.Sprint(table.Values, table.DefinitionLevels, table.RepetitionLevels)
+		if actual != c.expected {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+
+	// required elements can't be null
+	if _, err := MarshalJSON([]interface{}{`{"required_list": [], "optional_list": [null]}`}, sh); !errors.Is(err, ErrInvalidRecord) {
+		t.Errorf("expected: %v, but actual: %v\n", ErrInvalidRecord, err)
+	}
+}
+
+func TestMarshalJSON_Error(t *testing.T) {
+	cases := []interface{}{
+		// missing and null required fields
+		`{"tags": ["a"]}`,
+		`{"id": null}`,
+		`{"id": 1, "nums": [1, null]}`,
+		`{"id": 1, "tags": "a"}`,
+		`{"id": 1, "attrs": [1]}`,
+		`null`,
+		`{`,
+	}
+
+	for _, c := range cases {
+		_, err := MarshalJSON([]interface{}{c}, newMarshalTestSchemaHandler())
+		if !errors.Is(err, ErrInvalidRecord) {
+			t.Errorf("expected: %v, but actual: %v\n", ErrInvalidRecord, err)
+		}
+	}
+}
+
+func TestMarshalJSON_LogicalValues(t *testing.T) {
+	required := parquetFormat.FieldRepetitionTypePtr(parquetFormat.FieldRepetitionType_REQUIRED)
+	flba := parquetFormat.TypePtr(parquetFormat.Type_FIXED_LEN_BYTE_ARRAY)
+	decimal := parquetFormat.ConvertedTypePtr(parquetFormat.ConvertedType_DECIMAL)
+	three, twelve, thirtyTwo, thirtyEight, seventySix := int32(3), int32(12), int32(32), int32(38), int32(76)
+	sh := schema.NewSchemaHandlerFromSchemaList([]*parquetFormat.SchemaElement{
+		{Name: "root", RepetitionType: required, NumChildren: &three},
+		{Name: "amount", Type: flba, TypeLength: &thirtyTwo, RepetitionType: required, ConvertedType: decimal, Precision: &seventySix, Scale: &thirtyEight},
+		{Name: "interval", Type: flba, TypeLength: &twelve, RepetitionType: required, ConvertedType: parquetFormat.ConvertedTypePtr(parquetFormat.ConvertedType_INTERVAL)},
+		{Name: "json", Type: parquetFormat.TypePtr(parquetFormat.Type_BYTE_ARRAY), RepetitionType: required, LogicalType: &parquetFormat.LogicalType{JSON: parquetFormat.NewJsonType()}},
+	})
+
+	tables, err := MarshalJSON([]interface{}{
+		`{"amount": -0.00000000000000000000000000000000000001, "interval": {"months": 1, "days": 2, "milliseconds": 3}, "json": {"a": [1]}}`,
+	}, sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[int32]interface{}{
+		1: strings.Repeat("\xff", 32),
+		2: "\x01\x00\x00\x00\x02\x00\x00\x00\x03\x00\x00\x00",
+		3: `{"a":[1]}`,
+	}
+	for i, e := range expected {
+		actual := (*tables)[sh.IndexMap[i]].Values[0]
+		if actual != e {
+			t.Errorf("expected: %q, but actual: %q\n", e, actual)
+		}
+	}
+
+	for _, c := range []string{
+		`{"amount": 0.000000000000000000000000000000000000001, "interval": {}, "json": 1}`,
+		`{"amount": 1e38, "interval": {}, "json": 1}`,
+		`{"amount": 1, "interval": {"days": -1}, "json": 1}`,
+	} {
+		if _, err := MarshalJSON([]interface{}{c}, sh); !errors.Is(err, ErrInvalidRecord) {
+			t.Errorf("expected: %v, but actual: %v\n", ErrInvalidRecord, err)
+		}
+	}
+}
+
+func TestMarshalJSON_BigNumericRange(t *testing.T) {
+	s, err := columnifySchema.NewSchemaFromBigQuerySchema([]byte(`[{"name": "amount", "type": "BIGNUMERIC", "mode": "REQUIRED"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh, err := columnifySchema.NewSchemaHandlerFromArrow(*s, columnifySchema.ParquetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the max and min of BigQuery BIGNUMERIC, which have 39 integer digits
+	tables, err := MarshalJSON([]interface{}{
+		`{"amount": "578960446186580977117854925043439539266.34992332820282019728792003956564819967"}`,
+		`{"amount": "-578960446186580977117854925043439539266.34992332820282019728792003956564819968"}`,
+	}, sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []interface{}{
+		"\x00\x7f" + strings.Repeat("\xff", 31),
+		"\xff\x80" + strings.Repeat("\x00", 31),
+	}
+	for i, e := range expected {
+		if actual := (*tables)[sh.IndexMap[1]].Values[i]; actual != e {
+			t.Errorf("expected: %q, but actual: %q\n", e, actual)
+		}
+	}
+}
+
+func TestMarshalJSON_BinaryValues(t *testing.T) {
+	required := parquetFormat.FieldRepetitionTypePtr(parquetFormat.FieldRepetitionType_REQUIRED)
+	two, three := int32(2), int32(3)
+	sh := schema.NewSchemaHandlerFromSchemaList([]*parquetFormat.SchemaElement{
+		{Name: "root", RepetitionType: required, NumChildren: &three},
+		{Name: "bytes", Type: parquetFormat.TypePtr(parquetFormat.Type_BYTE_ARRAY), RepetitionType: required},
+		{Name: "fixed", Type: parquetFormat.TypePtr(parquetFormat.Type_FIXED_LEN_BYTE_ARRAY), TypeLength: &two, RepetitionType: required},
+		{Name: "string", Type: parquetFormat.TypePtr(parquetFormat.Type_BYTE_ARRAY), RepetitionType: required, ConvertedType: parquetFormat.ConvertedTypePtr(parquetFormat.ConvertedType_UTF8)},
+	})
+
+	tables, err := MarshalJSON([]interface{}{
+		`{"bytes": "ÿ\u0000a", "fixed": "\u0080\u0001", "string": "ÿ"}`,
+	}, sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[int32]interface{}{
+		1: "\xff\x00a",
+		2: "\x80\x01",
+		3: "ÿ",
+	}
+	for i, e := range expected {
+		actual := (*tables)[sh.IndexMap[i]].Values[0]
+		if actual != e {
+			t.Errorf("expected: %q, but actual: %q\n", e, actual)
+		}
+	}
+
+	if _, err := MarshalJSON([]interface{}{`{"bytes": "Ā", "fixed": "", "string": ""}`}, sh); !errors.Is(err, ErrInvalidRecord) {
+		t.Errorf("expected: %v, but actual: %v\n", ErrInvalidRecord, err)
+	}
+}
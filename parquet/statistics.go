@@ -0,0 +1,238 @@
+package parquet
+
+import (
+	"math"
+	"strings"
+
+	"github.com/xitongsys/parquet-go/encoding"
+	parquetFormat "github.com/xitongsys/parquet-go/parquet"
+)
+
+// sortOrder is the type defined order of a column, which decides how min/max values are compared.
+type sortOrder int
+
+const (
+	sortOrderSigned sortOrder = iota
+	sortOrderUnsigned
+	sortOrderUndefined
+)
+
+// columnSortOrder returns the sort order of a column from its physical type and annotations.
+func columnSortOrder(e *parquetFormat.SchemaElement) sortOrder {
+	if lt := e.LogicalType; lt != nil {
+		switch {
+		case lt.INTEGER != nil && lt.INTEGER.IsSigned, lt.DECIMAL != nil:
+			return sortOrderSigned
+		case lt.INTEGER != nil:
+			return sortOrderUnsigned
+		}
+	}
+
+	if e.ConvertedType != nil {
+		switch e.GetConvertedType() {
+		case parquetFormat.ConvertedType_UINT_8, parquetFormat.ConvertedType_UINT_16,
+			parquetFormat.ConvertedType_UINT_32, parquetFormat.ConvertedType_UINT_64:
+			return sortOrderUnsigned
+		case parquetFormat.ConvertedType_DECIMAL:
+			return sortOrderSigned
+		case parquetFormat.ConvertedType_INTERVAL:
+			return sortOrderUndefined
+		}
+	}
+
+	switch e.GetType() {
+	case parquetFormat.Type_INT96:
+		return sortOrderUndefined
+	case parquetFormat.Type_BYTE_ARRAY, parquetFormat.Type_FIXED_LEN_BYTE_ARRAY:
+		return sortOrderUnsigned
+	}
+
+	return sortOrderSigned
+}
+
+// statistics accumulates min/max values and the null count of a page or a column chunk.
+// Values are compared in the type defined order, e.g. byte arrays are compared as unsigned bytes.
+type statistics struct {
+	t         parquetFormat.Type
+	order     sortOrder
+	min, max  interface{}
+	nullCount int64
+}
+
+func newStatistics(e *parquetFormat.SchemaElement) *statistics {
+	return &statistics{t: e.GetType(), order: columnSortOrder(e)}
+}
+
+// hasMinMax returns false for types without the defined order like INT96, or no comparable values.
+func (s *statistics) hasMinMax() bool {
+	return s.min != nil && s.max != nil && s.order != sortOrderUndefined
+}
+
+func (s *statistics) update(v interface{}) {
+	if v == nil {
+		s.nullCount++
+		return
+	}
+
+	// NaN can't be ordered, so it's excluded from min/max
+	switch vv := v.(type) {
+	case float32:
+		if math.IsNaN(float64(vv)) {
+			return
+		}
+	case float64:
+		if math.IsNaN(vv) {
+			return
+		}
+	}
+
+	if s.min == nil || s.compare(v, s.min) < 0 {
+		s.min = v
+	}
+	if s.max == nil || s.compare(v, s.max) > 0 {
+		s.max = v
+	}
+}
+
+func (s *statistics) merge(o *statistics) {
+	s.nullCount += o.nullCount
+	if o.min != nil {
+		s.update(o.min)
+	}
+	if o.max != nil {
+		s.update(o.max)
+	}
+}
+
+// minBytes and maxBytes return plain encoded values without length prefixes.
+// Zeros of floating point values are signed to cover both of them as the spec requires.
+func (s *statistics) minBytes() []byte {
+	v := s.min
+	switch vv := v.(type) {
+	case float32:
+		if vv == 0 {
+			v = float32(math.Copysign(0, -1))
+		}
+	case float64:
+		if vv == 0 {
+			v = math.Copysign(0, -1)
+		}
+	}
+
+	return plainValue(v, s.t)
+}
+
+func (s *statistics) maxBytes() []byte {
+	v := s.max
+	switch vv := v.(type) {
+	case float32:
+		if vv == 0 {
+			v = float32(0)
+		}
+	case float64:
+		if vv == 0 {
+			v = float64(0)
+		}
+	}
+
+	return plainValue(v, s.t)
+}
+
+// toThrift returns statistics of the footer and page headers. Deprecated min/max fields are filled only for types
+// whose signed order is the same as the type defined order.
+func (s *statistics) toThrift() *parquetFormat.Statistics {
+	stats := parquetFormat.NewStatistics()
+	nullCount := s.nullCount
+	stats.NullCount = &nullCount
+
+	if s.hasMinMax() {
+		stats.MinValue = s.minBytes()
+		stats.MaxValue = s.maxBytes()
+		if s.order == sortOrderSigned && s.t != parquetFormat.Type_BYTE_ARRAY && s.t != parquetFormat.Type_FIXED_LEN_BYTE_ARRAY {
+			stats.Min = stats.MinValue
+			stats.Max = stats.MaxValue
+		}
+	}
+
+	return stats
+}
+
+// compare compares values of the physical type in the sort order.
+func (s *statistics) compare(a, b interface{}) int {
+	unsigned := s.order == sortOrderUnsigned
+
+	switch av := a.(type) {
+	case bool:
+		bv := b.(bool)
+		return compareLess(!av && bv, av && !bv)
+	case int32:
+		bv := b.(int32)
+		if unsigned {
+			return compareLess(uint32(av) < uint32(bv), uint32(av) > uint32(bv))
+		}
+		return compareLess(av < bv, av > bv)
+	case int64:
+		bv := b.(int64)
+		if unsigned {
+			return compareLess(uint64(av) < uint64(bv), uint64(av) > uint64(bv))
+		}
+		return compareLess(av < bv, av > bv)
+	case float32:
+		bv := b.(float32)
+		return compareLess(av < bv, av > bv)
+	case float64:
+		bv := b.(float64)
+		return compareLess(av < bv, av > bv)
+	case string:
+		if unsigned {
+			return strings.Compare(av, b.(string))
+		}
+		return compareSignedBytes(av, b.(string))
+	}
+
+	return 0
+}
+
+// compareSignedBytes compares big-endian two's complement integers like decimals in byte arrays.
+func compareSignedBytes(a, b string) int {
+	aNeg := len(a) > 0 && a[0]&0x80 != 0
+	bNeg := len(b) > 0 && b[0]&0x80 != 0
+	if aNeg != bNeg {
+		return compareLess(aNeg, bNeg)
+	}
+
+	// sign extension to the same length keeps the value, then unsigned comparison works
+	pad := "\x00"
+	if aNeg {
+		pad = "\xff"
+	}
+	if len(a) < len(b) {
+		a = strings.Repeat(pad, len(b)-len(a)) + a
+	} else if len(b) < len(a) {
+		b = strings.Repeat(pad, len(a)-len(b)) + b
+	}
+
+	return strings.Compare(a, b)
+}
+
+func compareLess(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+
+	return 0
+}
+
+// plainValue encodes the value as plain without the length prefix of byte arrays, which is used for statistics
+// and hashes of Bloom filters.
+func plainValue(v interface{}, t parquetFormat.Type) []byte {
+	buf := encoding.WritePlain([]interface{}{v}, t)
+	if t == parquetFormat.Type_BYTE_ARRAY {
+		return buf[4:]
+	}
+
+	return buf
+}
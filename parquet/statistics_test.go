@@ -0,0 +1,134 @@
+package parquet
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	parquetFormat "github.com/xitongsys/parquet-go/parquet"
+)
+
+func TestStatistics(t *testing.T) {
+	cases := []struct {
+		schema            *parquetFormat.SchemaElement
+		values            []interface{}
+		expectedMin       []byte
+		expectedMax       []byte
+		expectedNullCount int64
+		expectedDeprecate bool
+	}{
+		// signed integers with nulls
+		{
+			schema:            &parquetFormat.SchemaElement{Type: parquetFormat.TypePtr(parquetFormat.Type_INT32)},
+			values:            []interface{}{int32(3), nil, int32(-1), nil},
+			expectedMin:       []byte{0xff, 0xff, 0xff, 0xff},
+			expectedMax:       []byte{0x03, 0x00, 0x00, 0x00},
+			expectedNullCount: 2,
+			expectedDeprecate: true,
+		},
+		// unsigned integers
+		{
+			schema: &parquetFormat.SchemaElement{
+				Type:          parquetFormat.TypePtr(parquetFormat.Type_INT32),
+				ConvertedType: parquetFormat.ConvertedTypePtr(parquetFormat.ConvertedType_UINT_32),
+			},
+			values:            []interface{}{int32(3), int32(-1)},
+			expectedMin:       []byte{0x03, 0x00, 0x00, 0x00},
+			expectedMax:       []byte{0xff, 0xff, 0xff, 0xff},
+			expectedDeprecate: false,
+		},
+		// strings without length prefixes
+		{
+			schema: &parquetFormat.SchemaElement{
+				Type:          parquetFormat.TypePtr(parquetFormat.Type_BYTE_ARRAY),
+				ConvertedType: parquetFormat.ConvertedTypePtr(parquetFormat.ConvertedType_UTF8),
+			},
+			values:            []interface{}{"foo", "bar", "\xe3\x81\x82", "baz"},
+			expectedMin:       []byte("bar"),
+			expectedMax:       []byte("\xe3\x81\x82"),
+			expectedDeprecate: false,
+		},
+		// binaries without converted types
+		{
+			schema:            &parquetFormat.SchemaElement{Type: parquetFormat.TypePtr(parquetFormat.Type_BYTE_ARRAY)},
+			values:            []interface{}{"\x01\x02", "\x01"},
+			expectedMin:       []byte{0x01},
+			expectedMax:       []byte{0x01, 0x02},
+			expectedDeprecate: false,
+		},
+		// decimals in two's complement
+		{
+			schema: &parquetFormat.SchemaElement{
+				Type:          parquetFormat.TypePtr(parquetFormat.Type_BYTE_ARRAY),
+				ConvertedType: parquetFormat.ConvertedTypePtr(parquetFormat.ConvertedType_DECIMAL),
+			},
+			values:            []interface{}{"\x01\x00", "\xff", "\x7f"},
+			expectedMin:       []byte{0xff},
+			expectedMax:       []byte{0x01, 0x00},
+			expectedDeprecate: false,
+		},
+		// signed zeros, and NaN is ignored
+		{
+			schema:            &parquetFormat.SchemaElement{Type: parquetFormat.TypePtr(parquetFormat.Type_DOUBLE)},
+			values:            []interface{}{math.NaN(), float64(0)},
+			expectedMin:       []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80},
+			expectedMax:       []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			expectedDeprecate: true,
+		},
+		// INT96 has no defined order
+		{
+			schema:            &parquetFormat.SchemaElement{Type: parquetFormat.TypePtr(parquetFormat.Type_INT96)},
+			values:            []interface{}{"\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00", nil},
+			expectedMin:       nil,
+			expectedMax:       nil,
+			expectedNullCount: 1,
+		},
+		// only nulls
+		{
+			schema:            &parquetFormat.SchemaElement{Type: parquetFormat.TypePtr(parquetFormat.Type_INT64)},
+			values:            []interface{}{nil},
+			expectedMin:       nil,
+			expectedMax:       nil,
+			expectedNullCount: 1,
+		},
+	}
+
+	for _, c := range cases {
+		s := newStatistics(c.schema)
+		for _, v := range c.values {
+			s.update(v)
+		}
+		actual := s.toThrift()
+
+		if !bytes.Equal(actual.MinValue, c.expectedMin) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expectedMin, actual.MinValue)
+		}
+		if !bytes.Equal(actual.MaxValue, c.expectedMax) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expectedMax, actual.MaxValue)
+		}
+		if actual.GetNullCount() != c.expectedNullCount {
+			t.Errorf("expected: %v, but actual: %v\n", c.expectedNullCount, actual.GetNullCount())
+		}
+		if (actual.Min != nil) != c.expectedDeprecate {
+			t.Errorf("expected: %v, but actual: %v\n", c.expectedDeprecate, actual.Min)
+		}
+	}
+}
+
+func TestStatistics_Merge(t *testing.T) {
+	e := &parquetFormat.SchemaElement{Type: parquetFormat.TypePtr(parquetFormat.Type_INT64)}
+
+	s1, s2 := newStatistics(e), newStatistics(e)
+	s1.update(int64(5))
+	s1.update(nil)
+	s2.update(int64(-3))
+	s2.update(int64(10))
+
+	s := newStatistics(e)
+	s.merge(s1)
+	s.merge(s2)
+
+	if s.min != int64(-3) || s.max != int64(10) || s.nullCount != 1 {
+		t.Errorf("expected: %v, but actual: %v\n", []interface{}{int64(-3), int64(10), 1}, []interface{}{s.min, s.max, s.nullCount})
+	}
+}
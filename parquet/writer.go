@@ -0,0 +1,799 @@
+package parquet
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/compress"
+	"github.com/xitongsys/parquet-go/encoding"
+	"github.com/xitongsys/parquet-go/layout"
+	parquetFormat "github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/schema"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+const magic = "PAR1"
+
+// Writer is a parquet writer based on parquet-go's ParquetWriter, which supports column specific codecs and encodings.
+// It also writes statistics in the type defined order, the page index and Bloom filters which parquet-go doesn't.
+type Writer struct {
+	SchemaHandler *schema.SchemaHandler
+	Footer        *parquetFormat.FileMetaData
+	PFile         source.ParquetFile
+
+	PageSize        int64
+	RowGroupSize    int64
+	CompressionType parquetFormat.CompressionCodec
+
+	// DictionaryPageSize is the limit of dictionaries. Pages after a dictionary exceeds it are written in PLAIN,
+	// so high cardinality columns don't keep all distinct values of a row group.
+	DictionaryPageSize int64
+
+	// BloomFilterMaxSize is the limit of Bloom filters in bytes. Hashes of distinct values are kept until they fill
+	// a filter of the limit, and the rest of values are inserted to the filter directly.
+	BloomFilterMaxSize int
+
+	// PageIndex enables ColumnIndex and OffsetIndex, which are written after all row groups
+	PageIndex bool
+
+	// MarshalFunc converts buffered objects to column values
+	MarshalFunc func(src []interface{}, sh *schema.SchemaHandler) (*map[string]*layout.Table, error)
+
+	marshalSchemaHandler *schema.SchemaHandler
+
+	// Size is the size of buffered pages of the current row group
+	Size int64
+
+	offset  int64
+	columns map[string]columnOptions // keyed by internal column paths
+
+	int96Units map[string]time.Duration // units of epoch values of INT96 timestamps keyed by internal column paths
+
+	objs              []interface{}
+	objsSize          int64
+	objSize           int64
+	checkSizeCritical int64
+
+	chunks  map[string]*columnChunk // keyed by internal column paths
+	numRows int64
+
+	pageIndexes  []*pageIndex
+	bloomFilters []*bloomFilterLocation // in the order of column chunks in the footer
+}
+
+// columnChunk buffers pages of a column in the current row group.
+type columnChunk struct {
+	schema    *parquetFormat.SchemaElement
+	pages     []*columnPage
+	dict      *layout.DictRecType
+	dictSize  int64 // of the dictionary page in PLAIN
+	dictFull  bool  // true if the dictionary exceeds the limit, and the rest of pages are PLAIN
+	stats     *statistics
+	numValues int64
+
+	// hashes are distinct values for the Bloom filter, until they fill the filter of the maximum size
+	hashes map[uint64]struct{}
+	filter *bloomFilter
+}
+
+// columnPage is an encoded data page with its statistics for the page index.
+type columnPage struct {
+	rawData          []byte
+	uncompressedSize int64
+	stats            *statistics
+	numValues        int64
+	numRows          int64
+}
+
+// pageIndex is the page index of a column chunk, which is written with the footer.
+type pageIndex struct {
+	chunk       *parquetFormat.ColumnChunk
+	columnIndex *parquetFormat.ColumnIndex // nil if min/max of pages are undefined
+	offsetIndex *parquetFormat.OffsetIndex
+}
+
+// NewWriter creates a new Writer, and writes the header to the file.
+func NewWriter(pFile source.ParquetFile, sh *schema.SchemaHandler) (*Writer, error) {
+	createdBy := "columnify"
+	footer := parquetFormat.NewFileMetaData()
+	footer.Version = 1
+	footer.CreatedBy = &createdBy
+	footer.Schema = append(footer.Schema, sh.SchemaElements...)
+	for i, e := range sh.SchemaElements {
+		if e.GetNumChildren() == 0 && i > 0 {
+			footer.ColumnOrders = append(footer.ColumnOrders, &parquetFormat.ColumnOrder{
+				TYPE_ORDER: parquetFormat.NewTypeDefinedOrder(),
+			})
+		}
+	}
+
+	if _, err := pFile.Write([]byte(magic)); err != nil {
+		return nil, err
+	}
+
+	w := &Writer{
+		SchemaHandler:      sh,
+		Footer:             footer,
+		PFile:              pFile,
+		PageSize:           8 * 1024,
+		RowGroupSize:       128 * 1024 * 1024,
+		CompressionType:    parquetFormat.CompressionCodec_SNAPPY,
+		DictionaryPageSize: 1024 * 1024,
+		BloomFilterMaxSize: 1024 * 1024,
+		offset:             int64(len(magic)),
+		columns:            make(map[string]columnOptions),
+		int96Units:         make(map[string]time.Duration),
+		chunks:             make(map[string]*columnChunk),
+	}
+
+	// enums are a few symbols, so they're always dictionary encoded
+	for inPath := range ColumnPaths(sh) {
+		if isEnumColumn(sh.SchemaElements[sh.MapIndex[inPath]]) {
+			w.columns[inPath] = columnOptions{encoding: parquetFormat.Encoding_PLAIN_DICTIONARY}
+		}
+	}
+
+	return w, nil
+}
+
+// SetColumnOptions validates and applies options keyed by column paths like "nested.field".
+// Options of a group column are applied to leaf columns under it.
+func (w *Writer) SetColumnOptions(options map[string]ColumnOptions) error {
+	paths := ColumnPaths(w.SchemaHandler)
+
+	used := make(map[string]bool)
+	for inPath, path := range paths {
+		o, ok := lookupColumnOptions(options, path)
+		if !ok {
+			continue
+		}
+
+		idx := w.SchemaHandler.MapIndex[inPath]
+		e := w.SchemaHandler.SchemaElements[idx]
+		resolved, err := o.resolve(e.GetType())
+		if err != nil {
+			return fmt.Errorf("column %s: %w", path, err)
+		}
+		if isEnumColumn(e) {
+			if o.Encoding != "" || (o.Dictionary != nil && !*o.Dictionary) {
+				return fmt.Errorf("column %s is ENUM without dictionary: %w", path, ErrInvalidColumnOptions)
+			}
+			resolved.encoding = parquetFormat.Encoding_PLAIN_DICTIONARY
+		}
+		w.columns[inPath] = resolved
+
+		for p := range options {
+			if p == path || (len(path) > len(p) && path[:len(p)+1] == p+".") {
+				used[p] = true
+			}
+		}
+	}
+
+	for _, p := range sortedKeys(options) {
+		if !used[p] {
+			return fmt.Errorf("column %s is not found: %w", p, ErrInvalidColumnOptions)
+		}
+	}
+
+	return nil
+}
+
+// SetInt96Timestamps sets units of epoch values of INT96 timestamp columns keyed by column paths like "nested.field",
+// which are converted to Julian days and nanoseconds of the day on writing.
+func (w *Writer) SetInt96Timestamps(units map[string]time.Duration) error {
+	inPaths := make(map[string]string)
+	for inPath, path := range ColumnPaths(w.SchemaHandler) {
+		inPaths[path] = inPath
+	}
+
+	for path, unit := range units {
+		inPath, ok := inPaths[path]
+		if !ok {
+			return fmt.Errorf("column %s is not found: %w", path, ErrInvalidColumnOptions)
+		}
+		if t := w.SchemaHandler.SchemaElements[w.SchemaHandler.MapIndex[inPath]].GetType(); t != parquetFormat.Type_INT96 {
+			return fmt.Errorf("column %s is %v, not INT96: %w", path, t, ErrInvalidColumnOptions)
+		}
+		w.int96Units[inPath] = unit
+	}
+
+	return nil
+}
+
+// codec returns the compression codec of the column.
+func (w *Writer) codec(inPath string) parquetFormat.CompressionCodec {
+	if o, ok := w.columns[inPath]; ok && o.codec != nil {
+		return *o.codec
+	}
+
+	return w.CompressionType
+}
+
+// encoding returns the value encoding of the column.
+func (w *Writer) encoding(inPath string) parquetFormat.Encoding {
+	if o, ok := w.columns[inPath]; ok {
+		return o.encoding
+	}
+
+	return parquetFormat.Encoding_PLAIN
+}
+
+// Write buffers an object, and flushes them as pages if the buffer is large enough.
+func (w *Writer) Write(src interface{}) error {
+	ln := int64(len(w.objs))
+
+	val := reflect.ValueOf(src)
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+		src = val.Interface()
+	}
+
+	if w.checkSizeCritical <= ln {
+		w.objSize = (w.objSize+common.SizeOf(val))/2 + 1
+	}
+	w.objsSize += w.objSize
+	w.objs = append(w.objs, src)
+
+	criSize := w.PageSize * w.SchemaHandler.GetColumnNum()
+	if w.objsSize >= criSize {
+		return w.Flush(false)
+	}
+
+	dln := (criSize - w.objsSize + w.objSize - 1) / w.objSize / 2
+	w.checkSizeCritical = dln + ln
+
+	return nil
+}
+
+// flushObjs converts buffered objects to pages.
+func (w *Writer) flushObjs() error {
+	if len(w.objs) == 0 {
+		return nil
+	}
+
+	if w.marshalSchemaHandler == nil {
+		w.marshalSchemaHandler = marshalSchemaHandler(w.SchemaHandler)
+	}
+	tableMap, err := w.MarshalFunc(w.objs, w.marshalSchemaHandler)
+	if err != nil {
+		return err
+	}
+
+	for name, table := range *tableMap {
+		if unit, ok := w.int96Units[name]; ok {
+			if err := convertInt96Timestamps(table.Values, unit); err != nil {
+				return err
+			}
+		}
+		if err := w.writePages(name, table); err != nil {
+			return err
+		}
+	}
+
+	w.numRows += int64(len(w.objs))
+
+	return nil
+}
+
+// writePages encodes column values as data pages of the current column chunk.
+// Pages are split at record boundaries, so rows in the page index don't span pages.
+func (w *Writer) writePages(name string, table *layout.Table) error {
+	elem := w.SchemaHandler.SchemaElements[w.SchemaHandler.MapIndex[name]]
+	chunk, ok := w.chunks[name]
+	if !ok {
+		chunk = &columnChunk{
+			schema: elem,
+			stats:  newStatistics(elem),
+		}
+		if w.encoding(name) == parquetFormat.Encoding_PLAIN_DICTIONARY {
+			chunk.dict = layout.NewDictRec(elem.GetType())
+		}
+		if w.columns[name].bloomFilter {
+			chunk.hashes = make(map[uint64]struct{})
+		}
+		w.chunks[name] = chunk
+	}
+	t := elem.GetType()
+
+	for i := 0; i < len(table.Values); {
+		page := &columnPage{
+			stats: newStatistics(elem),
+		}
+		values := make([]interface{}, 0)
+
+		var size int64
+		j := i
+		for ; j < len(table.Values); j++ {
+			if table.RepetitionLevels[j] == 0 {
+				if j > i && size >= w.PageSize {
+					break
+				}
+				page.numRows++
+			}
+
+			if table.DefinitionLevels[j] < table.MaxDefinitionLevel {
+				page.stats.update(nil)
+				continue
+			}
+
+			v := table.Values[j]
+			page.stats.update(v)
+			values = append(values, v)
+			size += valueSize(v)
+			chunk.insertHash(v, w.BloomFilterMaxSize)
+		}
+		page.numValues = int64(j - i)
+
+		var data []byte
+		if table.MaxRepetitionLevel > 0 {
+			data = append(data, encoding.WriteRLEBitPackedHybridInt32(table.RepetitionLevels[i:j], int32(bits.Len32(uint32(table.MaxRepetitionLevel))))...)
+		}
+		if table.MaxDefinitionLevel > 0 {
+			data = append(data, encoding.WriteRLEBitPackedHybridInt32(table.DefinitionLevels[i:j], int32(bits.Len32(uint32(table.MaxDefinitionLevel))))...)
+		}
+		e := w.encoding(name)
+		if chunk.dict != nil && !chunk.dictFull {
+			data = append(data, chunk.encodeDictIndexes(values)...)
+			chunk.dictFull = chunk.dictSize > w.DictionaryPageSize
+		} else {
+			if e == parquetFormat.Encoding_PLAIN_DICTIONARY {
+				e = parquetFormat.Encoding_PLAIN
+			}
+			data = append(data, encodeValues(values, t, e)...)
+		}
+
+		header := parquetFormat.NewPageHeader()
+		header.Type = parquetFormat.PageType_DATA_PAGE
+		header.DataPageHeader = parquetFormat.NewDataPageHeader()
+		header.DataPageHeader.NumValues = int32(page.numValues)
+		header.DataPageHeader.DefinitionLevelEncoding = parquetFormat.Encoding_RLE
+		header.DataPageHeader.RepetitionLevelEncoding = parquetFormat.Encoding_RLE
+		header.DataPageHeader.Encoding = e
+		header.DataPageHeader.Statistics = page.stats.toThrift()
+
+		rawData, err := compressPage(header, data, w.codec(name))
+		if err != nil {
+			return err
+		}
+		page.rawData = rawData
+		page.uncompressedSize = int64(len(rawData)) - int64(header.CompressedPageSize) + int64(header.UncompressedPageSize)
+
+		chunk.pages = append(chunk.pages, page)
+		chunk.stats.merge(page.stats)
+		chunk.numValues += page.numValues
+		w.Size += int64(len(rawData))
+
+		i = j
+	}
+
+	return nil
+}
+
+// Flush writes buffered pages as a row group if it's large enough or forced.
+func (w *Writer) Flush(force bool) error {
+	if err := w.flushObjs(); err != nil {
+		return err
+	}
+
+	if (w.Size+w.objsSize >= w.RowGroupSize || force) && len(w.chunks) > 0 {
+		if err := w.writeRowGroup(); err != nil {
+			return err
+		}
+	}
+
+	w.Footer.NumRows += int64(len(w.objs))
+	w.objs = w.objs[:0]
+	w.objsSize = 0
+
+	return nil
+}
+
+func (w *Writer) writeRowGroup() error {
+	rowGroup := parquetFormat.NewRowGroup()
+	rowGroup.Columns = make([]*parquetFormat.ColumnChunk, 0)
+	rowGroup.NumRows = w.numRows
+
+	var filters []*bloomFilter
+	for k, e := range w.SchemaHandler.SchemaElements {
+		if e.GetNumChildren() > 0 {
+			continue
+		}
+		inPath := w.SchemaHandler.IndexMap[int32(k)]
+		chunk := w.chunks[inPath]
+		if chunk == nil {
+			continue
+		}
+
+		columnChunk, err := w.writeColumnChunk(inPath, chunk)
+		if err != nil {
+			return err
+		}
+		rowGroup.Columns = append(rowGroup.Columns, columnChunk)
+		rowGroup.TotalByteSize += columnChunk.MetaData.TotalUncompressedSize
+
+		filters = append(filters, chunk.bloomFilter(w.BloomFilterMaxSize))
+	}
+
+	// Bloom filters follow column chunks of the row group
+	for _, filter := range filters {
+		if filter == nil {
+			w.bloomFilters = append(w.bloomFilters, nil)
+			continue
+		}
+
+		header, err := filter.header()
+		if err != nil {
+			return err
+		}
+		data := append(header, filter.bytes()...)
+		w.bloomFilters = append(w.bloomFilters, &bloomFilterLocation{offset: w.offset, length: int32(len(data))})
+		if err := w.write(data); err != nil {
+			return err
+		}
+	}
+
+	w.Footer.RowGroups = append(w.Footer.RowGroups, rowGroup)
+	w.Size = 0
+	w.numRows = 0
+	w.chunks = make(map[string]*columnChunk)
+
+	return nil
+}
+
+// writeColumnChunk writes the dictionary page and data pages of a column chunk, and returns its metadata.
+func (w *Writer) writeColumnChunk(inPath string, chunk *columnChunk) (*parquetFormat.ColumnChunk, error) {
+	md := parquetFormat.NewColumnMetaData()
+	md.Type = chunk.schema.GetType()
+	md.Codec = w.codec(inPath)
+	md.PathInSchema = common.StrToPath(w.SchemaHandler.InPathToExPath[inPath])[1:]
+	md.NumValues = chunk.numValues
+	md.Statistics = chunk.stats.toThrift()
+
+	columnChunk := parquetFormat.NewColumnChunk()
+	columnChunk.FileOffset = w.offset
+	columnChunk.MetaData = md
+
+	if chunk.dict != nil {
+		md.Encodings = []parquetFormat.Encoding{parquetFormat.Encoding_RLE, parquetFormat.Encoding_PLAIN, parquetFormat.Encoding_PLAIN_DICTIONARY}
+
+		data := encoding.WritePlain(chunk.dict.DictSlice, chunk.dict.Type)
+		header := parquetFormat.NewPageHeader()
+		header.Type = parquetFormat.PageType_DICTIONARY_PAGE
+		header.DictionaryPageHeader = parquetFormat.NewDictionaryPageHeader()
+		header.DictionaryPageHeader.NumValues = int32(len(chunk.dict.DictSlice))
+		header.DictionaryPageHeader.Encoding = parquetFormat.Encoding_PLAIN
+
+		rawData, err := compressPage(header, data, md.Codec)
+		if err != nil {
+			return nil, err
+		}
+		offset := w.offset
+		md.DictionaryPageOffset = &offset
+		md.TotalCompressedSize += int64(len(rawData))
+		md.TotalUncompressedSize += int64(len(rawData)) - int64(header.CompressedPageSize) + int64(header.UncompressedPageSize)
+		if err := w.write(rawData); err != nil {
+			return nil, err
+		}
+	} else {
+		md.Encodings = []parquetFormat.Encoding{parquetFormat.Encoding_RLE, w.encoding(inPath)}
+	}
+
+	md.DataPageOffset = w.offset
+	offsetIndex := parquetFormat.NewOffsetIndex()
+	var firstRowIndex int64
+	for _, page := range chunk.pages {
+		offsetIndex.PageLocations = append(offsetIndex.PageLocations, &parquetFormat.PageLocation{
+			Offset:             w.offset,
+			CompressedPageSize: int32(len(page.rawData)),
+			FirstRowIndex:      firstRowIndex,
+		})
+		firstRowIndex += page.numRows
+
+		md.TotalCompressedSize += int64(len(page.rawData))
+		md.TotalUncompressedSize += page.uncompressedSize
+		if err := w.write(page.rawData); err != nil {
+			return nil, err
+		}
+	}
+
+	if w.PageIndex {
+		w.pageIndexes = append(w.pageIndexes, &pageIndex{
+			chunk:       columnChunk,
+			columnIndex: chunk.columnIndex(),
+			offsetIndex: offsetIndex,
+		})
+	}
+
+	return columnChunk, nil
+}
+
+// insertHash adds the hash of a value for the Bloom filter if it's enabled. Hashes of distinct values are kept
+// to size the filter, until they fill the filter of the maximum size.
+func (c *columnChunk) insertHash(v interface{}, maxSize int) {
+	if c.hashes == nil && c.filter == nil {
+		return
+	}
+
+	h := bloomFilterHash(v, c.schema.GetType())
+	if c.filter != nil {
+		c.filter.insert(h)
+		return
+	}
+
+	c.hashes[h] = struct{}{}
+	if len(c.hashes) >= bloomFilterNDV(bloomFilterLimit(maxSize)) {
+		c.filter = c.bloomFilter(maxSize)
+		c.hashes = nil
+	}
+}
+
+// bloomFilter returns the Bloom filter of the chunk, or nil if it's disabled.
+func (c *columnChunk) bloomFilter(maxSize int) *bloomFilter {
+	if c.filter != nil || c.hashes == nil {
+		return c.filter
+	}
+
+	filter := newBloomFilter(len(c.hashes), maxSize)
+	for h := range c.hashes {
+		filter.insert(h)
+	}
+
+	return filter
+}
+
+// columnIndex returns min/max values and null counts of pages, or nil if some pages have values without min/max.
+func (c *columnChunk) columnIndex() *parquetFormat.ColumnIndex {
+	columnIndex := parquetFormat.NewColumnIndex()
+
+	var prev *statistics
+	ascending, descending := true, true
+	for _, page := range c.pages {
+		nullPage := page.stats.nullCount == page.numValues
+		if !nullPage && !page.stats.hasMinMax() {
+			return nil
+		}
+
+		columnIndex.NullPages = append(columnIndex.NullPages, nullPage)
+		columnIndex.NullCounts = append(columnIndex.NullCounts, page.stats.nullCount)
+		if nullPage {
+			columnIndex.MinValues = append(columnIndex.MinValues, []byte{})
+			columnIndex.MaxValues = append(columnIndex.MaxValues, []byte{})
+			continue
+		}
+		columnIndex.MinValues = append(columnIndex.MinValues, page.stats.minBytes())
+		columnIndex.MaxValues = append(columnIndex.MaxValues, page.stats.maxBytes())
+
+		if prev != nil {
+			minOrder, maxOrder := c.stats.compare(page.stats.min, prev.min), c.stats.compare(page.stats.max, prev.max)
+			ascending = ascending && minOrder >= 0 && maxOrder >= 0
+			descending = descending && minOrder <= 0 && maxOrder <= 0
+		}
+		prev = page.stats
+	}
+
+	switch {
+	case ascending:
+		columnIndex.BoundaryOrder = parquetFormat.BoundaryOrder_ASCENDING
+	case descending:
+		columnIndex.BoundaryOrder = parquetFormat.BoundaryOrder_DESCENDING
+	default:
+		columnIndex.BoundaryOrder = parquetFormat.BoundaryOrder_UNORDERED
+	}
+
+	return columnIndex
+}
+
+// WriteStop flushes buffered objects, and writes the page index and the footer.
+func (w *Writer) WriteStop() error {
+	if err := w.Flush(true); err != nil {
+		return err
+	}
+
+	if err := w.writePageIndexes(); err != nil {
+		return err
+	}
+
+	w.renameSchema()
+
+	ts := thrift.NewTSerializer()
+	ts.Protocol = &footerProtocol{
+		TProtocol: thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport),
+		locations: w.bloomFilters,
+	}
+	footer, err := ts.Write(context.TODO(), w.Footer)
+	if err != nil {
+		return err
+	}
+	if err := w.write(footer); err != nil {
+		return err
+	}
+
+	size := make([]byte, 4)
+	binary.LittleEndian.PutUint32(size, uint32(len(footer)))
+	if err := w.write(size); err != nil {
+		return err
+	}
+
+	return w.write([]byte(magic))
+}
+
+// writePageIndexes writes column indexes of all column chunks, and then offset indexes of them.
+func (w *Writer) writePageIndexes() error {
+	for _, pi := range w.pageIndexes {
+		if pi.columnIndex == nil {
+			continue
+		}
+
+		data, err := serialize(pi.columnIndex)
+		if err != nil {
+			return err
+		}
+		offset, length := w.offset, int32(len(data))
+		pi.chunk.ColumnIndexOffset = &offset
+		pi.chunk.ColumnIndexLength = &length
+		if err := w.write(data); err != nil {
+			return err
+		}
+	}
+
+	for _, pi := range w.pageIndexes {
+		data, err := serialize(pi.offsetIndex)
+		if err != nil {
+			return err
+		}
+		offset, length := w.offset, int32(len(data))
+		pi.chunk.OffsetIndexOffset = &offset
+		pi.chunk.OffsetIndexLength = &length
+		if err := w.write(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// write writes data to the file, and advances the offset.
+func (w *Writer) write(data []byte) error {
+	if _, err := w.PFile.Write(data); err != nil {
+		return err
+	}
+	w.offset += int64(len(data))
+
+	return nil
+}
+
+// renameSchema replaces internal names with external ones in the footer.
+func (w *Writer) renameSchema() {
+	for i := range w.Footer.Schema {
+		w.Footer.Schema[i].Name = w.SchemaHandler.Infos[i].ExName
+	}
+}
+
+// compressPage compresses page data, and returns it following the serialized header.
+func compressPage(header *parquetFormat.PageHeader, data []byte, codec parquetFormat.CompressionCodec) ([]byte, error) {
+	compressed := compress.Compress(data, codec)
+	header.CompressedPageSize = int32(len(compressed))
+	header.UncompressedPageSize = int32(len(data))
+
+	rawData, err := serialize(header)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(rawData, compressed...), nil
+}
+
+// encodeDictIndexes adds values to the dictionary, and encodes their indexes with the bit width prefix.
+func (c *columnChunk) encodeDictIndexes(values []interface{}) []byte {
+	dict := c.dict
+	indexes := make([]int32, len(values))
+	for i, v := range values {
+		idx, ok := dict.DictMap[v]
+		if !ok {
+			idx = int32(len(dict.DictSlice))
+			dict.DictSlice = append(dict.DictSlice, v)
+			dict.DictMap[v] = idx
+			c.dictSize += int64(len(encoding.WritePlain([]interface{}{v}, dict.Type)))
+		}
+		indexes[i] = idx
+	}
+
+	bitWidth := int32(1)
+	if n := len(dict.DictSlice); n > 2 {
+		bitWidth = int32(bits.Len32(uint32(n - 1)))
+	}
+
+	return append([]byte{byte(bitWidth)}, encoding.WriteRLEInt32(indexes, bitWidth)...)
+}
+
+// valueSize estimates the encoded size of a value to split pages.
+func valueSize(v interface{}) int64 {
+	switch vv := v.(type) {
+	case bool:
+		return 1
+	case int32, float32:
+		return 4
+	case string:
+		return int64(len(vv))
+	}
+
+	return 8
+}
+
+// serialize encodes a thrift struct with the compact protocol.
+func serialize(s thrift.TStruct) ([]byte, error) {
+	ts := thrift.NewTSerializer()
+	ts.Protocol = &compactProtocol{
+		TProtocol: thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport),
+	}
+
+	return ts.Write(context.TODO(), s)
+}
+
+// compactProtocol writes bool elements of lists like null_pages of ColumnIndex as 0 or 1 in the compact protocol spec.
+// The thrift library of parquet-go writes false as 2, which some readers take as true.
+type compactProtocol struct {
+	thrift.TProtocol
+
+	lists []thrift.TType // element types of nested lists
+}
+
+func (p *compactProtocol) WriteListBegin(elemType thrift.TType, size int) error {
+	p.lists = append(p.lists, elemType)
+	return p.TProtocol.WriteListBegin(elemType, size)
+}
+
+func (p *compactProtocol) WriteListEnd() error {
+	p.lists = p.lists[:len(p.lists)-1]
+	return p.TProtocol.WriteListEnd()
+}
+
+func (p *compactProtocol) WriteBool(value bool) error {
+	if len(p.lists) == 0 || p.lists[len(p.lists)-1] != thrift.BOOL {
+		return p.TProtocol.WriteBool(value)
+	}
+	if value {
+		return p.TProtocol.WriteByte(1)
+	}
+
+	return p.TProtocol.WriteByte(0)
+}
+
+// marshalSchemaHandler returns the schema handler given to MarshalFunc. parquet-go converts values by converted types,
+// and drops values of ones it doesn't know like JSON and ENUM, so they're replaced with their physical types.
+func marshalSchemaHandler(sh *schema.SchemaHandler) *schema.SchemaHandler {
+	copied := *sh
+	copied.SchemaElements = make([]*parquetFormat.SchemaElement, len(sh.SchemaElements))
+	for i, e := range sh.SchemaElements {
+		copied.SchemaElements[i] = e
+
+		switch e.GetConvertedType() {
+		case parquetFormat.ConvertedType_JSON, parquetFormat.ConvertedType_ENUM, parquetFormat.ConvertedType_BSON:
+			if e.ConvertedType != nil {
+				plain := *e
+				plain.ConvertedType = nil
+				copied.SchemaElements[i] = &plain
+			}
+		}
+	}
+
+	return &copied
+}
+
+// sortedKeys is a helper to iterate maps in a stable order.
+func sortedKeys(m map[string]ColumnOptions) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
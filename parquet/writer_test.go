@@ -0,0 +1,469 @@
+package parquet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/marshal"
+	parquetFormat "github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/schema"
+)
+
+const writerTestSchema = `{
+  "Tag": "name=root",
+  "Fields": [
+    {"Tag": "name=id, type=INT64"},
+    {"Tag": "name=score, type=DOUBLE, repetitiontype=OPTIONAL"},
+    {"Tag": "name=name, type=UTF8, repetitiontype=OPTIONAL"}
+  ]
+}`
+
+func TestWriter_SetColumnOptions(t *testing.T) {
+	cases := []struct {
+		options map[string]ColumnOptions
+		isErr   bool
+	}{
+		{
+			options: map[string]ColumnOptions{
+				"id":    {Encoding: "DELTA_BINARY_PACKED"},
+				"score": {Encoding: "BYTE_STREAM_SPLIT", Codec: "GZIP"},
+			},
+			isErr: false,
+		},
+		// Unknown column
+		{
+			options: map[string]ColumnOptions{"unknown": {Codec: "GZIP"}},
+			isErr:   true,
+		},
+		// Unsupported encoding for the column type
+		{
+			options: map[string]ColumnOptions{"name": {Encoding: "DELTA_BINARY_PACKED"}},
+			isErr:   true,
+		},
+	}
+
+	for _, c := range cases {
+		sh, err := schema.NewSchemaHandlerFromJSON(writerTestSchema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := buffer.NewBufferFile(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := NewWriter(f, sh)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = w.SetColumnOptions(c.options)
+		if err != nil != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidColumnOptions) {
+			t.Errorf("expected: %v, but actual: %v\n", ErrInvalidColumnOptions, err)
+		}
+	}
+}
+
+func TestWriter_SetInt96Timestamps(t *testing.T) {
+	cases := []struct {
+		units map[string]time.Duration
+		isErr bool
+	}{
+		{
+			units: map[string]time.Duration{"ts": time.Millisecond},
+			isErr: false,
+		},
+		// Unknown column
+		{
+			units: map[string]time.Duration{"unknown": time.Millisecond},
+			isErr: true,
+		},
+		// Not INT96 column
+		{
+			units: map[string]time.Duration{"id": time.Millisecond},
+			isErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		sh, err := schema.NewSchemaHandlerFromJSON(`{
+  "Tag": "name=root",
+  "Fields": [
+    {"Tag": "name=id, type=INT64"},
+    {"Tag": "name=ts, type=INT96, repetitiontype=OPTIONAL"}
+  ]
+}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := buffer.NewBufferFile(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := NewWriter(f, sh)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = w.SetInt96Timestamps(c.units)
+		if err != nil != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidColumnOptions) {
+			t.Errorf("expected: %v, but actual: %v\n", ErrInvalidColumnOptions, err)
+		}
+	}
+}
+
+func TestWriter_WriteStop(t *testing.T) {
+	sh, err := schema.NewSchemaHandlerFromJSON(writerTestSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := buffer.NewBufferFile(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWriter(f, sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.MarshalFunc = marshal.MarshalJSON
+	err = w.SetColumnOptions(map[string]ColumnOptions{
+		"id":   {Encoding: "DELTA_BINARY_PACKED", Codec: "GZIP"},
+		"name": {Encoding: "DELTA_BYTE_ARRAY"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// name column has only nulls
+	for _, v := range []string{`{"id": 1, "score": 0.5}`, `{"id": 3}`} {
+		if err := w.Write(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteStop(); err != nil {
+		t.Fatal(err)
+	}
+
+	rf, err := buffer.NewBufferFile(f.(buffer.BufferFile).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := reader.NewParquetReader(rf, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.GetNumRows() != 2 {
+		t.Errorf("expected: %v, but actual: %v\n", 2, r.GetNumRows())
+	}
+	columns := r.Footer.RowGroups[0].Columns
+	if columns[0].MetaData.Codec != parquetFormat.CompressionCodec_GZIP || columns[1].MetaData.Codec != parquetFormat.CompressionCodec_SNAPPY {
+		t.Errorf("expected: GZIP and SNAPPY, but actual: %v and %v\n", columns[0].MetaData.Codec, columns[1].MetaData.Codec)
+	}
+
+	ids, _, _, err := r.ReadColumnByIndex(0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != int64(1) || ids[1] != int64(3) {
+		t.Errorf("expected: %v, but actual: %v\n", []int64{1, 3}, ids)
+	}
+
+	names, _, _, err := r.ReadColumnByIndex(2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != nil || names[1] != nil {
+		t.Errorf("expected: %v, but actual: %v\n", []interface{}{nil, nil}, names)
+	}
+}
+
+func TestWriter_WriteStop_PageIndexAndBloomFilter(t *testing.T) {
+	sh, err := schema.NewSchemaHandlerFromJSON(writerTestSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := buffer.NewBufferFile(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWriter(f, sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.MarshalFunc = marshal.MarshalJSON
+	w.PageSize = 16
+	w.PageIndex = true
+	err = w.SetColumnOptions(map[string]ColumnOptions{
+		"name": {BloomFilter: boolToPtr(true), Dictionary: boolToPtr(true)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// name column has nulls in the first 4 rows
+	for i := 0; i < 10; i++ {
+		v := fmt.Sprintf(`{"id": %d}`, i)
+		if i >= 4 {
+			v = fmt.Sprintf(`{"id": %d, "name": "user-%d"}`, i, i)
+		}
+		if err := w.Write(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteStop(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := f.(buffer.BufferFile).Bytes()
+	rf, err := buffer.NewBufferFile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := reader.NewParquetReader(rf, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Footer.ColumnOrders) != 3 {
+		t.Errorf("expected: %v, but actual: %v\n", 3, len(r.Footer.ColumnOrders))
+	}
+
+	names, _, _, err := r.ReadColumnByIndex(2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 10 || names[3] != nil || names[9] != "user-9" {
+		t.Errorf("expected: %v, but actual: %v\n", "10 names", names)
+	}
+
+	columns := r.Footer.RowGroups[0].Columns
+	stats := columns[0].MetaData.Statistics
+	if !bytes.Equal(stats.MinValue, plainValue(int64(0), parquetFormat.Type_INT64)) || !bytes.Equal(stats.MaxValue, plainValue(int64(9), parquetFormat.Type_INT64)) {
+		t.Errorf("expected: %v, but actual: %v\n", []int64{0, 9}, []interface{}{stats.MinValue, stats.MaxValue})
+	}
+	if stats := columns[2].MetaData.Statistics; string(stats.MinValue) != "user-4" || stats.GetNullCount() != 4 {
+		t.Errorf("expected: %v, but actual: %v\n", []interface{}{"user-4", 4}, []interface{}{string(stats.MinValue), stats.GetNullCount()})
+	}
+
+	// id column is split into pages of 2 rows
+	columnIndex := parquetFormat.NewColumnIndex()
+	deserializeAt(t, data, columns[0].GetColumnIndexOffset(), columns[0].GetColumnIndexLength(), columnIndex)
+	if len(columnIndex.NullPages) != 5 || columnIndex.BoundaryOrder != parquetFormat.BoundaryOrder_ASCENDING {
+		t.Errorf("expected: %v, but actual: %v\n", "5 ascending pages", columnIndex)
+	}
+	offsetIndex := parquetFormat.NewOffsetIndex()
+	deserializeAt(t, data, columns[0].GetOffsetIndexOffset(), columns[0].GetOffsetIndexLength(), offsetIndex)
+	if len(offsetIndex.PageLocations) != 5 || offsetIndex.PageLocations[4].FirstRowIndex != 8 {
+		t.Errorf("expected: %v, but actual: %v\n", "5 pages from row 8", offsetIndex.PageLocations)
+	}
+	if offsetIndex.PageLocations[0].Offset != columns[0].MetaData.DataPageOffset {
+		t.Errorf("expected: %v, but actual: %v\n", columns[0].MetaData.DataPageOffset, offsetIndex.PageLocations[0].Offset)
+	}
+
+	// nulls of name column are in the first page
+	deserializeAt(t, data, columns[2].GetColumnIndexOffset(), columns[2].GetColumnIndexLength(), columnIndex)
+	if fmt.Sprint(columnIndex.NullCounts) != "[4 0 0]" || string(columnIndex.MinValues[0]) != "user-4" {
+		t.Errorf("expected: %v, but actual: %v\n", "4 nulls in the first page", columnIndex)
+	}
+
+	// Bloom filter only for name column
+	if len(w.bloomFilters) != 3 || w.bloomFilters[0] != nil || w.bloomFilters[2] == nil {
+		t.Fatalf("expected: %v, but actual: %v\n", "a Bloom filter of name column", w.bloomFilters)
+	}
+	l := w.bloomFilters[2]
+	buf := thrift.NewTMemoryBuffer()
+	if _, err := buf.Write(data[l.offset : l.offset+int64(l.length)]); err != nil {
+		t.Fatal(err)
+	}
+	readFieldIds(t, thrift.NewTCompactProtocolFactory().GetProtocol(buf), nil)
+	filter := newBloomFilterFromBytes(buf.Bytes())
+	for _, name := range []string{"user-4", "user-9"} {
+		if !filter.check(bloomFilterHash(name, parquetFormat.Type_BYTE_ARRAY)) {
+			t.Errorf("expected: %v, but actual: %v\n", true, false)
+		}
+	}
+}
+
+// deserializeAt reads a thrift struct at the offset of the file.
+func deserializeAt(t *testing.T, data []byte, offset int64, length int32, s thrift.TStruct) {
+	td := thrift.NewTDeserializer()
+	td.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(td.Transport)
+	if err := td.Read(s, data[offset:offset+int64(length)]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestColumnChunk_ColumnIndex(t *testing.T) {
+	e := &parquetFormat.SchemaElement{Type: parquetFormat.TypePtr(parquetFormat.Type_DOUBLE)}
+	newPage := func(values ...interface{}) *columnPage {
+		page := &columnPage{stats: newStatistics(e), numValues: int64(len(values))}
+		for _, v := range values {
+			page.stats.update(v)
+		}
+		return page
+	}
+
+	cases := []struct {
+		pages             []*columnPage
+		expectedNullPages []bool
+		expectedOrder     parquetFormat.BoundaryOrder
+		expectedNil       bool
+	}{
+		{
+			pages:             []*columnPage{newPage(1.0, 2.0), newPage(nil, nil), newPage(2.0, 3.0)},
+			expectedNullPages: []bool{false, true, false},
+			expectedOrder:     parquetFormat.BoundaryOrder_ASCENDING,
+		},
+		{
+			pages:             []*columnPage{newPage(3.0), newPage(1.0, nil)},
+			expectedNullPages: []bool{false, false},
+			expectedOrder:     parquetFormat.BoundaryOrder_DESCENDING,
+		},
+		{
+			pages:             []*columnPage{newPage(1.0, 5.0), newPage(2.0, 3.0)},
+			expectedNullPages: []bool{false, false},
+			expectedOrder:     parquetFormat.BoundaryOrder_UNORDERED,
+		},
+		// NaN can't be in min/max
+		{
+			pages:       []*columnPage{newPage(1.0), newPage(math.NaN())},
+			expectedNil: true,
+		},
+	}
+
+	for _, c := range cases {
+		chunk := &columnChunk{schema: e, pages: c.pages, stats: newStatistics(e)}
+		actual := chunk.columnIndex()
+
+		if (actual == nil) != c.expectedNil {
+			t.Errorf("expected: %v, but actual: %v\n", c.expectedNil, actual)
+			continue
+		}
+		if actual == nil {
+			continue
+		}
+		if fmt.Sprint(actual.NullPages) != fmt.Sprint(c.expectedNullPages) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expectedNullPages, actual.NullPages)
+		}
+		if actual.BoundaryOrder != c.expectedOrder {
+			t.Errorf("expected: %v, but actual: %v\n", c.expectedOrder, actual.BoundaryOrder)
+		}
+	}
+}
+
+func TestColumnChunk_BloomFilter(t *testing.T) {
+	e := &parquetFormat.SchemaElement{Type: parquetFormat.TypePtr(parquetFormat.Type_BYTE_ARRAY)}
+
+	cases := []struct {
+		ndv            int
+		maxSize        int
+		expectedSize   int
+		expectedHashes bool
+	}{
+		{ndv: 10, maxSize: 1024, expectedSize: bloomFilterMinSize, expectedHashes: true},
+		// hashes are dropped after they fill the filter of the maximum size
+		{ndv: 1000, maxSize: 256, expectedSize: 256, expectedHashes: false},
+	}
+
+	for _, c := range cases {
+		chunk := &columnChunk{schema: e, hashes: make(map[uint64]struct{})}
+		for i := 0; i < c.ndv; i++ {
+			chunk.insertHash(fmt.Sprintf("user-%d", i), c.maxSize)
+		}
+		filter := chunk.bloomFilter(c.maxSize)
+
+		if (chunk.hashes != nil) != c.expectedHashes {
+			t.Errorf("expected: %v, but actual: %v\n", c.expectedHashes, len(chunk.hashes))
+		}
+		if len(filter.bytes()) != c.expectedSize {
+			t.Errorf("expected: %v, but actual: %v\n", c.expectedSize, len(filter.bytes()))
+		}
+		for i := 0; i < c.ndv; i++ {
+			if !filter.check(bloomFilterHash(fmt.Sprintf("user-%d", i), parquetFormat.Type_BYTE_ARRAY)) {
+				t.Errorf("expected: %v, but actual: %v\n", true, false)
+			}
+		}
+	}
+
+	// disabled
+	if filter := (&columnChunk{schema: e}).bloomFilter(1024); filter != nil {
+		t.Errorf("expected: %v, but actual: %v\n", nil, filter)
+	}
+}
+
+func TestWriter_WriteStop_EnumAndJSON(t *testing.T) {
+	// JSON tags of parquet-go can't declare these converted types
+	required := parquetFormat.FieldRepetitionTypePtr(parquetFormat.FieldRepetitionType_REQUIRED)
+	optional := parquetFormat.FieldRepetitionTypePtr(parquetFormat.FieldRepetitionType_OPTIONAL)
+	byteArray := parquetFormat.TypePtr(parquetFormat.Type_BYTE_ARRAY)
+	numChildren := int32(2)
+	sh := schema.NewSchemaHandlerFromSchemaList([]*parquetFormat.SchemaElement{
+		{Name: "root", RepetitionType: required, NumChildren: &numChildren},
+		{Name: "kind", Type: byteArray, RepetitionType: required, ConvertedType: parquetFormat.ConvertedTypePtr(parquetFormat.ConvertedType_ENUM)},
+		{Name: "doc", Type: byteArray, RepetitionType: optional, ConvertedType: parquetFormat.ConvertedTypePtr(parquetFormat.ConvertedType_JSON)},
+	})
+	f, err := buffer.NewBufferFile(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWriter(f, sh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.MarshalFunc = marshal.MarshalJSON
+
+	disabled := false
+	if err := w.SetColumnOptions(map[string]ColumnOptions{"kind": {Dictionary: &disabled}}); !errors.Is(err, ErrInvalidColumnOptions) {
+		t.Errorf("expected: %v, but actual: %v\n", ErrInvalidColumnOptions, err)
+	}
+	if err := w.SetColumnOptions(map[string]ColumnOptions{"kind": {Codec: "GZIP"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Write(`{"kind": "A", "doc": "{\"k\":1}"}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteStop(); err != nil {
+		t.Fatal(err)
+	}
+
+	rf, err := buffer.NewBufferFile(f.(buffer.BufferFile).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := reader.NewParquetReader(rf, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := r.Footer.Schema[1].GetConvertedType(); ct != parquetFormat.ConvertedType_ENUM {
+		t.Errorf("expected: %v, but actual: %v\n", parquetFormat.ConvertedType_ENUM, ct)
+	}
+	// enums are dictionary encoded, and JSON isn't
+	for i, expected := range []bool{true, false} {
+		md := r.Footer.RowGroups[0].Columns[i].MetaData
+		if actual := md.DictionaryPageOffset != nil; actual != expected {
+			t.Errorf("expected: %v, but actual: %v\n", expected, actual)
+		}
+	}
+
+	for i, expected := range []string{"A", `{"k":1}`} {
+		values, _, _, err := r.ReadColumnByIndex(int64(i), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(values) != 1 || values[0] != expected {
+			t.Errorf("expected: %v, but actual: %v\n", expected, values)
+		}
+	}
+}
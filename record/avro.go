@@ -5,20 +5,40 @@ import (
 	"io"
 
 	"github.com/linkedin/goavro/v2"
+	"github.com/reproio/columnify/avro"
+	"github.com/reproio/columnify/schema"
 )
 
 type avroInnerDecoder struct {
-	r *goavro.OCFReader
+	r         *goavro.OCFReader
+	flattener *avroUnionFlattener
+	resolve   avroResolveFunc
 }
 
-func newAvroInnerDecoder(r io.Reader) (*avroInnerDecoder, error) {
+// newAvroInnerDecoder reads an Object Container File. Records are resolved from the writer schema in the file to
+// the schema if it's given.
+func newAvroInnerDecoder(r io.Reader, s *schema.IntermediateSchema) (*avroInnerDecoder, error) {
 	reader, err := goavro.NewOCFReader(r)
 	if err != nil {
 		return nil, err
 	}
 
+	rt, names, err := parseAvroWriterSchema(reader.Codec().Schema())
+	if err != nil {
+		return nil, err
+	}
+
+	var resolve avroResolveFunc
+	if s != nil {
+		if resolve, err = newAvroResolver(rt, names, s); err != nil {
+			return nil, err
+		}
+	}
+
 	return &avroInnerDecoder{
-		r: reader,
+		r:         reader,
+		flattener: newAvroUnionFlattener(rt, names),
+		resolve:   resolve,
 	}, nil
 }
 
@@ -34,7 +54,14 @@ func (d *avroInnerDecoder) Decode(r *map[string]interface{}) error {
 			return fmt.Errorf("invalid value %v: %w", v, ErrUnconvertibleRecord)
 		}
 
-		flatten := flattenAvroUnion(m)
+		flatten := d.flattener.flatten(m)
+		if d.resolve != nil {
+			resolved, err := d.resolve(flatten)
+			if err != nil {
+				return err
+			}
+			flatten = resolved.(map[string]interface{})
+		}
 		*r = flatten
 	} else if d.r.RemainingBlockItems() == 0 {
 		if d.r.Err() != nil {
@@ -46,26 +73,123 @@ func (d *avroInnerDecoder) Decode(r *map[string]interface{}) error {
 	return d.r.Err()
 }
 
-// flattenAvroUnion flattens nested map type has only 1 element.
-func flattenAvroUnion(in map[string]interface{}) map[string]interface{} {
-	out := make(map[string]interface{})
+// avroUnionFlattener unwraps union values of goavro like {"string": "foo"} by the writer schema, so records and maps
+// which have only one entry are kept as is.
+// see also https://github.com/linkedin/goavro#translating-from-go-to-avro-data
+type avroUnionFlattener struct {
+	names     *avro.NamedTypes
+	record    avro.RecordType
+	namespace string
+}
+
+func newAvroUnionFlattener(rt avro.RecordType, names *avro.NamedTypes) *avroUnionFlattener {
+	return &avroUnionFlattener{
+		names:     names,
+		record:    rt,
+		namespace: avro.NamespaceOf(avro.FullName(rt.Name, rt.Namespace, "")),
+	}
+}
+
+func (f *avroUnionFlattener) flatten(m map[string]interface{}) map[string]interface{} {
+	return f.flattenRecord(m, f.record, f.namespace)
+}
+
+func (f *avroUnionFlattener) flattenRecord(m map[string]interface{}, rt avro.RecordType, namespace string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	for _, field := range rt.Fields {
+		if v, ok := m[field.Name]; ok {
+			out[field.Name] = f.flattenValue(v, field.Type, namespace)
+		}
+	}
+
+	return out
+}
+
+func (f *avroUnionFlattener) flattenValue(v interface{}, t avro.AvroType, namespace string) interface{} {
+	switch {
+	case t.UnionType != nil:
+		m, ok := v.(map[string]interface{})
+		if !ok || len(m) != 1 {
+			return v
+		}
+		for name, vv := range m {
+			if b, ns, ok := f.unionBranch(*t.UnionType, name, namespace); ok {
+				return f.flattenValue(vv, b, ns)
+			}
+			return vv
+		}
+
+	case t.DefinedType != nil:
+		if dt, fullName, err := f.names.Lookup(string(*t.DefinedType), namespace); err == nil {
+			return f.flattenValue(v, dt, avro.NamespaceOf(fullName))
+		}
+
+	case t.RecordType != nil:
+		if m, ok := v.(map[string]interface{}); ok {
+			ns := avro.NamespaceOf(avro.FullName(t.RecordType.Name, t.RecordType.Namespace, namespace))
+			return f.flattenRecord(m, *t.RecordType, ns)
+		}
+
+	case t.ArrayType != nil:
+		if vs, ok := v.([]interface{}); ok {
+			for i, e := range vs {
+				vs[i] = f.flattenValue(e, t.ArrayType.Items, namespace)
+			}
+		}
 
-	for k, v := range in {
+	case t.MapsType != nil:
 		if m, ok := v.(map[string]interface{}); ok {
-			// Flatten because Avro-JSON representation has redundant nested map type.
-			// see also https://github.com/linkedin/goavro#translating-from-go-to-avro-data
-			if len(m) == 1 {
-				for _, vv := range m {
-					out[k] = vv
-					break
-				}
-			} else {
-				out[k] = flattenAvroUnion(m)
+			for k, e := range m {
+				m[k] = f.flattenValue(e, t.MapsType.Values, namespace)
 			}
-		} else {
-			out[k] = v
 		}
 	}
 
-	return out
+	return v
+}
+
+// unionBranch finds the branch of the union by the name goavro uses, e.g. "string", "long.timestamp-millis",
+// "array" or full names of named types.
+func (f *avroUnionFlattener) unionBranch(ut avro.UnionType, name, namespace string) (avro.AvroType, string, bool) {
+	for _, b := range ut {
+		ns := namespace
+		if b.DefinedType != nil {
+			dt, fullName, err := f.names.Lookup(string(*b.DefinedType), namespace)
+			if err != nil {
+				continue
+			}
+			if fullName == name {
+				return dt, avro.NamespaceOf(fullName), true
+			}
+			continue
+		}
+
+		var names []string
+		switch {
+		case b.PrimitiveType != nil:
+			names = []string{string(*b.PrimitiveType)}
+		case b.LogicalType != nil:
+			names = []string{b.LogicalType.Type, b.LogicalType.Type + "." + b.LogicalType.LogicalType}
+		case b.RecordType != nil:
+			names = []string{avro.FullName(b.RecordType.Name, b.RecordType.Namespace, ns)}
+		case b.EnumsType != nil:
+			names = []string{avro.FullName(b.EnumsType.Name, b.EnumsType.Namespace, ns)}
+		case b.FixedType != nil:
+			names = []string{avro.FullName(b.FixedType.Name, b.FixedType.Namespace, ns)}
+		case b.ArrayType != nil:
+			names = []string{avro.AvroComplexType_Array}
+		case b.MapsType != nil:
+			names = []string{avro.AvroComplexType_Maps}
+		}
+		for _, n := range names {
+			if n == name {
+				return b, ns, true
+			}
+		}
+	}
+
+	return avro.AvroType{}, "", false
 }
@@ -0,0 +1,254 @@
+package record
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/reproio/columnify/schema"
+)
+
+type avroFraming int
+
+const (
+	// Avro single-object encoding; C3 01 + 8 bytes CRC-64-AVRO fingerprint + body
+	// https://avro.apache.org/docs/1.10.2/spec.html#single_object_encoding
+	avroFramingSingleObject avroFraming = iota
+	// Confluent wire format; 00 + 4 bytes big-endian schema id + body
+	// https://docs.confluent.io/platform/current/schema-registry/serdes-develop/index.html#wire-format
+	avroFramingConfluent
+)
+
+const (
+	avroSingleObjectHeaderSize = 10
+	avroConfluentHeaderSize    = 5
+	avroConfluentMagicByte     = 0x00
+
+	avroFramedReadSize = 64 * 1024
+	// avroFramedMaxMessageSize limits the buffer for a message, so corrupt lengths don't read the rest of the input
+	avroFramedMaxMessageSize = 16 * 1024 * 1024
+)
+
+// avroSchemaStore resolves writer schemas from a local directory of .avsc files.
+// Schemas are keyed by CRC-64-AVRO fingerprint, and by schema id if the file name is numeric like "42.avsc".
+type avroSchemaStore struct {
+	byFingerprint map[uint64]*goavro.Codec
+	byId          map[uint32]*goavro.Codec
+}
+
+func newAvroSchemaStore(dir string) (*avroSchemaStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("avro schema directory is required: %w", ErrUnconvertibleRecord)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.avsc"))
+	if err != nil {
+		return nil, err
+	}
+
+	store := &avroSchemaStore{
+		byFingerprint: make(map[uint64]*goavro.Codec),
+		byId:          make(map[uint32]*goavro.Codec),
+	}
+	for _, p := range paths {
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+
+		codec, err := goavro.NewCodec(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema %s: %v: %w", p, err, ErrUnconvertibleRecord)
+		}
+		store.byFingerprint[codec.Rabin] = codec
+
+		name := strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))
+		if id, err := strconv.ParseUint(name, 10, 32); err == nil {
+			store.byId[uint32(id)] = codec
+		}
+	}
+
+	return store, nil
+}
+
+// avroFramedInnerDecoder decodes a sequence of framed Avro binary messages.
+// Each message has its own header to identify the writer schema.
+type avroFramedInnerDecoder struct {
+	r       io.Reader
+	buf     []byte
+	eof     bool
+	offset  int64 // of the head of the buffer in the input
+	framing avroFraming
+	store   *avroSchemaStore
+	schema  *schema.IntermediateSchema
+	writers map[*goavro.Codec]*avroFramedWriter
+}
+
+// avroFramedWriter converts records of a writer schema, which is created at the first message of the schema.
+type avroFramedWriter struct {
+	flattener *avroUnionFlattener
+	// resolve is nil without the reader schema
+	resolve avroResolveFunc
+}
+
+// newAvroFramedInnerDecoder reads framed messages. Records are resolved from their writer schemas to the schema if
+// it's given, same as Object Container Files.
+func newAvroFramedInnerDecoder(r io.Reader, s *schema.IntermediateSchema, framing avroFraming, schemaDir string) (*avroFramedInnerDecoder, error) {
+	store, err := newAvroSchemaStore(schemaDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &avroFramedInnerDecoder{
+		r:       r,
+		framing: framing,
+		store:   store,
+		schema:  s,
+		writers: make(map[*goavro.Codec]*avroFramedWriter),
+	}, nil
+}
+
+func (d *avroFramedInnerDecoder) Decode(r *map[string]interface{}) error {
+	codec, headerSize, err := d.readHeader()
+	if err != nil {
+		return err
+	}
+
+	for {
+		v, rest, err := codec.NativeFromBinary(d.buf[headerSize:])
+		if err == nil {
+			d.offset += int64(len(d.buf) - len(rest))
+			d.buf = rest
+
+			m, mapOk := v.(map[string]interface{})
+			if !mapOk {
+				return fmt.Errorf("invalid value %v: %w", v, ErrUnconvertibleRecord)
+			}
+			w, err := d.writer(codec)
+			if err != nil {
+				return err
+			}
+			flatten := w.flattener.flatten(m)
+			if w.resolve != nil {
+				resolved, err := w.resolve(flatten)
+				if err != nil {
+					return err
+				}
+				flatten = resolved.(map[string]interface{})
+			}
+			*r = flatten
+
+			return nil
+		}
+
+		// The message might be truncated by the buffer boundary, which goavro doesn't tell from corrupt ones.
+		// The buffer is doubled to decode it again, so messages are decoded a few times at most.
+		if d.eof || len(d.buf) >= avroFramedMaxMessageSize {
+			return fmt.Errorf("invalid message at offset %d: %v: %w", d.offset, err, ErrUnconvertibleRecord)
+		}
+		if err := d.fill(len(d.buf)); err != nil {
+			return err
+		}
+	}
+}
+
+// writer returns the converter of records of the writer schema, which is created at the first use.
+func (d *avroFramedInnerDecoder) writer(codec *goavro.Codec) (*avroFramedWriter, error) {
+	if w, ok := d.writers[codec]; ok {
+		return w, nil
+	}
+
+	rt, names, err := parseAvroWriterSchema(codec.Schema())
+	if err != nil {
+		return nil, err
+	}
+	w := &avroFramedWriter{
+		flattener: newAvroUnionFlattener(rt, names),
+	}
+	if d.schema != nil {
+		if w.resolve, err = newAvroResolver(rt, names, d.schema); err != nil {
+			return nil, err
+		}
+	}
+	d.writers[codec] = w
+
+	return w, nil
+}
+
+// readHeader reads a message header and resolves the writer schema.
+func (d *avroFramedInnerDecoder) readHeader() (*goavro.Codec, int, error) {
+	headerSize := avroSingleObjectHeaderSize
+	if d.framing == avroFramingConfluent {
+		headerSize = avroConfluentHeaderSize
+	}
+
+	for len(d.buf) < headerSize && !d.eof {
+		if err := d.fill(avroFramedReadSize); err != nil {
+			return nil, 0, err
+		}
+	}
+	if len(d.buf) == 0 {
+		return nil, 0, io.EOF
+	}
+	if len(d.buf) < headerSize {
+		return nil, 0, fmt.Errorf("truncated header %v at offset %d: %w", d.buf, d.offset, ErrUnconvertibleRecord)
+	}
+
+	switch d.framing {
+	case avroFramingSingleObject:
+		fingerprint, _, err := goavro.FingerprintFromSOE(d.buf)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%v at offset %d: %w", err, d.offset, ErrUnconvertibleRecord)
+		}
+		codec, ok := d.store.byFingerprint[fingerprint]
+		if !ok {
+			return nil, 0, fmt.Errorf("unknown schema fingerprint %d at offset %d: %w", fingerprint, d.offset, ErrUnconvertibleRecord)
+		}
+		return codec, headerSize, nil
+
+	case avroFramingConfluent:
+		if d.buf[0] != avroConfluentMagicByte {
+			return nil, 0, fmt.Errorf("unknown magic byte %#x at offset %d: %w", d.buf[0], d.offset, ErrUnconvertibleRecord)
+		}
+		id := binary.BigEndian.Uint32(d.buf[1:avroConfluentHeaderSize])
+		codec, ok := d.store.byId[id]
+		if !ok {
+			return nil, 0, fmt.Errorf("unknown schema id %d at offset %d: %w", id, d.offset, ErrUnconvertibleRecord)
+		}
+		return codec, headerSize, nil
+	}
+
+	return nil, 0, fmt.Errorf("unknown framing %d: %w", d.framing, ErrUnsupportedRecord)
+}
+
+// fill reads the next chunk of the size from the underlying reader to the buffer. The size is at least
+// avroFramedReadSize, and limited so that the buffer doesn't exceed avroFramedMaxMessageSize.
+func (d *avroFramedInnerDecoder) fill(size int) error {
+	if size < avroFramedReadSize {
+		size = avroFramedReadSize
+	}
+	if rest := avroFramedMaxMessageSize - len(d.buf); size > rest && rest > 0 {
+		size = rest
+	}
+
+	chunk := make([]byte, size)
+	n, err := io.ReadFull(d.r, chunk)
+	if err != nil {
+		if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+		d.eof = true
+	}
+
+	buf := make([]byte, 0, len(d.buf)+n)
+	buf = append(buf, d.buf...)
+	d.buf = append(buf, chunk[:n]...)
+
+	return nil
+}
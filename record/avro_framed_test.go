@@ -0,0 +1,338 @@
+package record
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/reproio/columnify/schema"
+)
+
+const testAvroFramedSchema = `
+{
+  "type": "record",
+  "name": "Framed",
+  "fields" : [
+    {"name": "int",    "type": "int"},
+    {"name": "string", "type": "string"},
+    {"name": "nullable", "type": ["null", "string"], "default": null}
+  ]
+}`
+
+func writeTestAvroSchemaDir(t *testing.T, name string) string {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(testAvroFramedSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestAvroFramedInnerDecoder_Decode(t *testing.T) {
+	codec, err := goavro.NewCodec(testAvroFramedSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	natives := []map[string]interface{}{
+		{"int": int32(1), "string": "foo", "nullable": goavro.Union("string", "bar")},
+		{"int": int32(2), "string": "bar", "nullable": nil},
+	}
+	expected := []map[string]interface{}{
+		{"int": int32(1), "string": "foo", "nullable": "bar"},
+		{"int": int32(2), "string": "bar", "nullable": nil},
+	}
+
+	singleObject := func() []byte {
+		var buf []byte
+		for _, n := range natives {
+			if buf, err = codec.SingleFromNative(buf, n); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return buf
+	}()
+	confluent := func(id uint32) []byte {
+		var buf []byte
+		for _, n := range natives {
+			header := make([]byte, avroConfluentHeaderSize)
+			binary.BigEndian.PutUint32(header[1:], id)
+			if buf, err = codec.BinaryFromNative(append(buf, header...), n); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return buf
+	}
+
+	cases := []struct {
+		input      []byte
+		framing    avroFraming
+		schemaFile string
+		expected   []map[string]interface{}
+		isErr      bool
+	}{
+		// Single-object encoding
+		{
+			input:      singleObject,
+			framing:    avroFramingSingleObject,
+			schemaFile: "framed.avsc",
+			expected:   expected,
+			isErr:      false,
+		},
+
+		// Confluent wire format
+		{
+			input:      confluent(42),
+			framing:    avroFramingConfluent,
+			schemaFile: "42.avsc",
+			expected:   expected,
+			isErr:      false,
+		},
+
+		// Unknown schema id
+		{
+			input:      confluent(1),
+			framing:    avroFramingConfluent,
+			schemaFile: "42.avsc",
+			expected:   []map[string]interface{}{},
+			isErr:      true,
+		},
+
+		// Not single-object encoded
+		{
+			input:      confluent(42),
+			framing:    avroFramingSingleObject,
+			schemaFile: "42.avsc",
+			expected:   []map[string]interface{}{},
+			isErr:      true,
+		},
+
+		// Truncated body
+		{
+			input:      singleObject[:len(singleObject)-2],
+			framing:    avroFramingSingleObject,
+			schemaFile: "framed.avsc",
+			expected:   expected[:1],
+			isErr:      true,
+		},
+	}
+
+	for _, c := range cases {
+		dir := writeTestAvroSchemaDir(t, c.schemaFile)
+
+		d, err := newAvroFramedInnerDecoder(bytes.NewReader(c.input), nil, c.framing, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := make([]map[string]interface{}, 0)
+		for {
+			var v map[string]interface{}
+			err = d.Decode(&v)
+			if err != nil {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		if (err != nil && err != io.EOF) != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestAvroFramedInnerDecoder_Resolve(t *testing.T) {
+	codec, err := goavro.NewCodec(testAvroFramedSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, err := codec.SingleFromNative(nil, map[string]interface{}{
+		"int": int32(1), "string": "foo", "nullable": goavro.Union("string", "bar"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		readerSchema string
+		expected     map[string]interface{}
+		isErr        bool
+	}{
+		// int is promoted to long, string is removed, and added is filled with the default
+		{
+			readerSchema: `
+{
+  "type": "record",
+  "name": "Framed",
+  "fields" : [
+    {"name": "int",      "type": "long"},
+    {"name": "nullable", "type": ["null", "string"], "default": null},
+    {"name": "added",    "type": "string", "default": "baz"}
+  ]
+}`,
+			expected: map[string]interface{}{"int": int32(1), "nullable": "bar", "added": "baz"},
+			isErr:    false,
+		},
+
+		// added without any default
+		{
+			readerSchema: `
+{
+  "type": "record",
+  "name": "Framed",
+  "fields" : [
+    {"name": "int",   "type": "long"},
+    {"name": "added", "type": "string"}
+  ]
+}`,
+			expected: nil,
+			isErr:    true,
+		},
+
+		// string can't be resolved to long
+		{
+			readerSchema: `
+{
+  "type": "record",
+  "name": "Framed",
+  "fields" : [
+    {"name": "string", "type": "long"}
+  ]
+}`,
+			expected: nil,
+			isErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		s, err := schema.NewSchemaFromAvroSchema([]byte(c.readerSchema))
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := newAvroFramedInnerDecoder(bytes.NewReader(input), s, avroFramingSingleObject, writeTestAvroSchemaDir(t, "framed.avsc"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var actual map[string]interface{}
+		err = d.Decode(&actual)
+		if err != nil != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !c.isErr && !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+// countingReader counts bytes read from the reader.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += n
+	return n, err
+}
+
+func TestAvroFramedInnerDecoder_DecodeCorrupt(t *testing.T) {
+	codec, err := goavro.NewCodec(testAvroFramedSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, err := codec.SingleFromNative(nil, map[string]interface{}{"int": int32(1), "string": "foo", "nullable": nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a valid message, and a corrupt one which has a negative length of the string followed by more bytes than the limit
+	input := append([]byte{}, valid...)
+	input = append(input, valid[:avroSingleObjectHeaderSize]...)
+	input = append(input, 0x02, 0x01)
+	input = append(input, make([]byte, avroFramedMaxMessageSize+4*avroFramedReadSize)...)
+
+	r := &countingReader{r: bytes.NewReader(input)}
+	d, err := newAvroFramedInnerDecoder(r, nil, avroFramingSingleObject, writeTestAvroSchemaDir(t, "framed.avsc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v map[string]interface{}
+	if err := d.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	err = d.Decode(&v)
+	if !errors.Is(err, ErrUnconvertibleRecord) || !strings.Contains(err.Error(), fmt.Sprintf("offset %d", len(valid))) {
+		t.Errorf("expected: %v at offset %d, but actual: %v\n", ErrUnconvertibleRecord, len(valid), err)
+	}
+	if r.n > len(valid)+avroFramedMaxMessageSize {
+		t.Errorf("expected: at most %d bytes read, but actual: %d\n", len(valid)+avroFramedMaxMessageSize, r.n)
+	}
+}
+
+func TestAvroFramedInnerDecoder_DecodeLarge(t *testing.T) {
+	codec, err := goavro.NewCodec(testAvroFramedSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"int": int32(1), "string": strings.Repeat("a", 5*avroFramedReadSize), "nullable": nil}
+	message, err := codec.SingleFromNative(nil, expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// messages over buffers are decoded after the buffer is doubled a few times
+	input := append(append([]byte{}, message...), message...)
+	r := &countingReader{r: bytes.NewReader(input)}
+	d, err := newAvroFramedInnerDecoder(r, nil, avroFramingSingleObject, writeTestAvroSchemaDir(t, "framed.avsc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		var actual map[string]interface{}
+		if err := d.Decode(&actual); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected: %v, but actual: %v\n", "a large message", len(actual["string"].(string)))
+		}
+	}
+	var v map[string]interface{}
+	if err := d.Decode(&v); err != io.EOF {
+		t.Errorf("expected: %v, but actual: %v\n", io.EOF, err)
+	}
+	if r.n != len(input) {
+		t.Errorf("expected: %v, but actual: %v\n", len(input), r.n)
+	}
+}
+
+func TestNewAvroSchemaStore(t *testing.T) {
+	if _, err := newAvroSchemaStore(""); err == nil {
+		t.Errorf("expected error occurs, but actual it's nil")
+	}
+
+	dir := writeTestAvroSchemaDir(t, "invalid.avsc")
+	if err := os.WriteFile(filepath.Join(dir, "invalid.avsc"), []byte(`{"type": "unknown"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newAvroSchemaStore(dir); err == nil {
+		t.Errorf("expected error occurs, but actual it's nil")
+	}
+}
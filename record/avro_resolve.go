@@ -0,0 +1,315 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/avro"
+	"github.com/reproio/columnify/schema"
+)
+
+// avroResolveFunc converts a value of the writer schema to the reader schema.
+type avroResolveFunc func(v interface{}) (interface{}, error)
+
+var (
+	// avroPromotableTypes is reader types which values of writer primitive types can be resolved to.
+	// In addition to promotions of the spec like int to long, strings can be also text values of temporal and
+	// decimal types, which are converted later.
+	avroPromotableTypes = map[string][]arrow.Type{
+		avro.AvroPrimitiveType_Boolean: {arrow.BOOL},
+		avro.AvroPrimitiveType_Int: {
+			arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64, arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64,
+			arrow.FLOAT32, arrow.FLOAT64, arrow.DATE32, arrow.TIME32,
+		},
+		avro.AvroPrimitiveType_Long:   {arrow.INT64, arrow.UINT64, arrow.FLOAT32, arrow.FLOAT64, arrow.TIME64, arrow.TIMESTAMP},
+		avro.AvroPrimitiveType_Float:  {arrow.FLOAT32, arrow.FLOAT64},
+		avro.AvroPrimitiveType_Double: {arrow.FLOAT64},
+		avro.AvroPrimitiveType_String: {
+			arrow.STRING, arrow.BINARY, arrow.FIXED_SIZE_BINARY,
+			arrow.DATE32, arrow.TIME32, arrow.TIME64, arrow.TIMESTAMP, arrow.DECIMAL, arrow.INTERVAL,
+		},
+		avro.AvroPrimitiveType_Bytes: {arrow.BINARY, arrow.STRING, arrow.DECIMAL},
+		avro.AvroComplexType_Enums:   {arrow.STRING, arrow.BINARY},
+		avro.AvroComplexType_Fixed:   {arrow.BINARY, arrow.FIXED_SIZE_BINARY, arrow.DECIMAL, arrow.INTERVAL, arrow.DURATION},
+	}
+)
+
+// newAvroResolver returns a function to project records of the writer schema onto the reader schema by Avro schema
+// resolution rules. Reader fields are matched with writer fields by their names or aliases, and ones missing in the
+// writer schema are filled with their defaults, or null if they're nullable. Writer fields not in the reader schema
+// are ignored. Types must be the same or promotable, e.g. int to long or float to double.
+func newAvroResolver(rt avro.RecordType, names *avro.NamedTypes, s *schema.IntermediateSchema) (avroResolveFunc, error) {
+	return resolveAvroRecord(names, rt, avro.NamespaceOf(avro.FullName(rt.Name, rt.Namespace, "")), s.ArrowSchema.Fields())
+}
+
+// parseAvroWriterSchema parses the writer schema of records, and collects named types in it.
+func parseAvroWriterSchema(writerSchema string) (avro.RecordType, *avro.NamedTypes, error) {
+	var rt avro.RecordType
+	if err := json.Unmarshal([]byte(writerSchema), &rt); err != nil {
+		return rt, nil, fmt.Errorf("invalid writer schema: %v: %w", err, ErrUnconvertibleRecord)
+	}
+	names, err := avro.NewNamedTypes(rt)
+	if err != nil {
+		return rt, nil, fmt.Errorf("invalid writer schema: %v: %w", err, ErrUnconvertibleRecord)
+	}
+
+	return rt, names, nil
+}
+
+func resolveAvroRecord(names *avro.NamedTypes, rt avro.RecordType, namespace string, fields []arrow.Field) (avroResolveFunc, error) {
+	type resolvedField struct {
+		field      arrow.Field
+		writerName string
+		resolve    avroResolveFunc
+	}
+
+	resolved := make([]resolvedField, 0, len(fields))
+	for _, f := range fields {
+		wf, err := findAvroWriterField(rt, f)
+		if err != nil {
+			return nil, err
+		}
+
+		r := resolvedField{field: f}
+		if wf != nil {
+			r.writerName = wf.Name
+			if r.resolve, err = resolveAvroType(names, wf.Type, namespace, f.Type); err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+		} else if f.Metadata.FindKey(schema.DefaultMetadataKey) < 0 && !f.Nullable {
+			return nil, fmt.Errorf("field %s is missing in the writer schema %s and has no default: %w", f.Name, rt.Name, ErrUnconvertibleRecord)
+		}
+		resolved = append(resolved, r)
+	}
+
+	return func(v interface{}) (interface{}, error) {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid record %v of %s: %w", v, rt.Name, ErrUnconvertibleRecord)
+		}
+
+		out := make(map[string]interface{}, len(resolved))
+		for _, r := range resolved {
+			if r.writerName == "" {
+				d, ok, err := fieldDefault(r.field)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					out[r.field.Name] = d
+				}
+				continue
+			}
+
+			vv, ok := m[r.writerName]
+			if !ok {
+				continue
+			}
+			if vv != nil && r.resolve != nil {
+				var err error
+				if vv, err = r.resolve(vv); err != nil {
+					return nil, fmt.Errorf("field %s: %w", r.field.Name, err)
+				}
+			}
+			out[r.field.Name] = vv
+		}
+
+		return out, nil
+	}, nil
+}
+
+// findAvroWriterField returns the writer field of the same name as the reader field, or one of its aliases.
+func findAvroWriterField(rt avro.RecordType, f arrow.Field) (*avro.RecordField, error) {
+	candidates := []string{f.Name}
+	if i := f.Metadata.FindKey(schema.AliasesMetadataKey); i >= 0 {
+		var aliases []string
+		if err := json.Unmarshal([]byte(f.Metadata.Values()[i]), &aliases); err != nil {
+			return nil, fmt.Errorf("aliases of field %s: %v: %w", f.Name, err, ErrUnconvertibleRecord)
+		}
+		candidates = append(candidates, aliases...)
+	}
+
+	for _, c := range candidates {
+		for i := range rt.Fields {
+			if rt.Fields[i].Name == c {
+				return &rt.Fields[i], nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// resolveAvroType checks the writer type can be resolved to the reader type, and returns a function to convert
+// values of it. The function is nil if values are kept as is.
+func resolveAvroType(names *avro.NamedTypes, wt avro.AvroType, namespace string, t arrow.DataType) (avroResolveFunc, error) {
+	switch {
+	case wt.UnionType != nil:
+		return resolveAvroUnion(names, *wt.UnionType, namespace, t)
+
+	case wt.DefinedType != nil:
+		dt, fullName, err := names.Lookup(string(*wt.DefinedType), namespace)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", err, ErrUnconvertibleRecord)
+		}
+		return resolveAvroType(names, dt, avro.NamespaceOf(fullName), t)
+
+	case wt.RecordType != nil:
+		st, ok := t.(*arrow.StructType)
+		if !ok {
+			return nil, unresolvableAvroTypeError(wt.RecordType.Name, t)
+		}
+		ns := avro.NamespaceOf(avro.FullName(wt.RecordType.Name, wt.RecordType.Namespace, namespace))
+		return resolveAvroRecord(names, *wt.RecordType, ns, st.Fields())
+
+	case wt.ArrayType != nil:
+		lt, ok := t.(*arrow.ListType)
+		if !ok {
+			return nil, unresolvableAvroTypeError(avro.AvroComplexType_Array, t)
+		}
+		resolve, err := resolveAvroType(names, wt.ArrayType.Items, namespace, lt.Elem())
+		if err != nil || resolve == nil {
+			return nil, err
+		}
+		return func(v interface{}) (interface{}, error) {
+			vs, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid array %v: %w", v, ErrUnconvertibleRecord)
+			}
+			for i, e := range vs {
+				if e == nil {
+					continue
+				}
+				ee, err := resolve(e)
+				if err != nil {
+					return nil, err
+				}
+				vs[i] = ee
+			}
+			return vs, nil
+		}, nil
+
+	case wt.MapsType != nil:
+		mt, ok := t.(*schema.MapType)
+		if !ok {
+			return nil, unresolvableAvroTypeError(avro.AvroComplexType_Maps, t)
+		}
+		resolve, err := resolveAvroType(names, wt.MapsType.Values, namespace, mt.ValueType())
+		if err != nil || resolve == nil {
+			return nil, err
+		}
+		return func(v interface{}) (interface{}, error) {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid map %v: %w", v, ErrUnconvertibleRecord)
+			}
+			for k, e := range m {
+				if e == nil {
+					continue
+				}
+				ee, err := resolve(e)
+				if err != nil {
+					return nil, err
+				}
+				m[k] = ee
+			}
+			return m, nil
+		}, nil
+
+	case wt.EnumsType != nil:
+		return nil, checkAvroPromotion(avro.AvroComplexType_Enums, t)
+
+	case wt.FixedType != nil:
+		return nil, checkAvroPromotion(avro.AvroComplexType_Fixed, t)
+
+	case wt.LogicalType != nil:
+		return resolveAvroPrimitive(wt.LogicalType.Type, t)
+
+	case wt.PrimitiveType != nil:
+		return resolveAvroPrimitive(string(*wt.PrimitiveType), t)
+	}
+
+	return nil, fmt.Errorf("unsupported writer type %v: %w", wt, ErrUnconvertibleRecord)
+}
+
+// resolveAvroUnion resolves a union to the reader type. Nulls are written as nulls, and other values are resolved by
+// branches which can be resolved to the reader type, because flattened values don't know their branches.
+func resolveAvroUnion(names *avro.NamedTypes, ut avro.UnionType, namespace string, t arrow.DataType) (avroResolveFunc, error) {
+	var resolvers []avroResolveFunc
+	var lastErr error
+	matched, identical := false, false
+	for _, b := range ut {
+		if b.PrimitiveType != nil && *b.PrimitiveType == avro.AvroPrimitiveType_Null {
+			continue
+		}
+		resolve, err := resolveAvroType(names, b, namespace, t)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		matched = true
+		if resolve == nil {
+			identical = true
+		} else {
+			resolvers = append(resolvers, resolve)
+		}
+	}
+
+	if !matched {
+		// lastErr is nil for unions of only null
+		return nil, lastErr
+	}
+	if len(resolvers) == 0 {
+		return nil, nil
+	}
+
+	return func(v interface{}) (interface{}, error) {
+		var err error
+		for _, resolve := range resolvers {
+			var vv interface{}
+			if vv, err = resolve(v); err == nil {
+				return vv, nil
+			}
+		}
+		if identical {
+			// a value of branches kept as is
+			return v, nil
+		}
+		return nil, err
+	}, nil
+}
+
+func resolveAvroPrimitive(name string, t arrow.DataType) (avroResolveFunc, error) {
+	if name == avro.AvroPrimitiveType_Null {
+		return nil, nil
+	}
+	if err := checkAvroPromotion(name, t); err != nil {
+		return nil, err
+	}
+
+	if name == avro.AvroPrimitiveType_Bytes && t.ID() == arrow.STRING {
+		return func(v interface{}) (interface{}, error) {
+			if b, ok := v.([]byte); ok {
+				return string(b), nil
+			}
+			return v, nil
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func checkAvroPromotion(name string, t arrow.DataType) error {
+	for _, id := range avroPromotableTypes[name] {
+		if t.ID() == id {
+			return nil
+		}
+	}
+
+	return unresolvableAvroTypeError(name, t)
+}
+
+func unresolvableAvroTypeError(name string, t arrow.DataType) error {
+	return fmt.Errorf("writer type %s is unable to resolve to %v: %w", name, t, ErrUnconvertibleRecord)
+}
@@ -0,0 +1,132 @@
+package record
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/reproio/columnify/schema"
+)
+
+const avroResolveWriterSchema = `
+{
+  "type": "record",
+  "name": "User",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "user_name", "type": "string"},
+    {"name": "id", "type": "int"},
+    {"name": "score", "type": "float"},
+    {"name": "avatar", "type": "bytes"},
+    {"name": "removed", "type": "string"},
+    {"name": "address", "type": ["null", {"type": "record", "name": "Address", "fields": [
+      {"name": "city", "type": "string"},
+      {"name": "zip", "type": "int"}
+    ]}]},
+    {"name": "history", "type": {"type": "array", "items": "Address"}}
+  ]
+}
+`
+
+func newAvroResolveTestOCF(t *testing.T, records []map[string]interface{}) []byte {
+	w := &bytes.Buffer{}
+	ow, err := goavro.NewOCFWriter(goavro.OCFConfig{W: w, Schema: avroResolveWriterSchema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ow.Append(records); err != nil {
+		t.Fatal(err)
+	}
+
+	return w.Bytes()
+}
+
+func TestAvroInnerDecoder_Resolve(t *testing.T) {
+	input := newAvroResolveTestOCF(t, []map[string]interface{}{
+		{
+			"user_name": "foo",
+			"id":        1,
+			"score":     1.5,
+			"avatar":    []byte("png"),
+			"removed":   "x",
+			"address":   goavro.Union("com.example.Address", map[string]interface{}{"city": "Tokyo", "zip": 100}),
+			"history":   []interface{}{map[string]interface{}{"city": "Osaka", "zip": 530}},
+		},
+	})
+
+	// fields are reordered, renamed, promoted, added and removed
+	readerSchema := `
+{
+  "type": "record",
+  "name": "User",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "name", "type": "string", "aliases": ["user_name"]},
+    {"name": "score", "type": "double"},
+    {"name": "avatar", "type": "string"},
+    {"name": "country", "type": "string", "default": "JP"},
+    {"name": "nickname", "type": ["null", "string"]},
+    {"name": "address", "type": ["null", {"type": "record", "name": "Address", "fields": [
+      {"name": "city", "type": "string"},
+      {"name": "zip", "type": "long"},
+      {"name": "verified", "type": "boolean", "default": false}
+    ]}]},
+    {"name": "history", "type": {"type": "array", "items": "Address"}}
+  ]
+}
+`
+	s, err := schema.NewSchemaFromAvroSchema([]byte(readerSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := newAvroInnerDecoder(bytes.NewReader(input), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual map[string]interface{}
+	if err := d.Decode(&actual); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"id":      int32(1),
+		"name":    "foo",
+		"score":   float32(1.5),
+		"avatar":  "png",
+		"country": "JP",
+		"address": map[string]interface{}{"city": "Tokyo", "zip": int32(100), "verified": false},
+		"history": []interface{}{map[string]interface{}{"city": "Osaka", "zip": int32(530), "verified": false}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected: %v, but actual: %v\n", expected, actual)
+	}
+}
+
+func TestAvroInnerDecoder_ResolveErrors(t *testing.T) {
+	cases := []string{
+		// int can't be narrowed
+		`{"type": "record", "name": "User", "fields": [{"name": "score", "type": "int"}]}`,
+		// string isn't a number
+		`{"type": "record", "name": "User", "fields": [{"name": "user_name", "type": "long"}]}`,
+		// added field without default
+		`{"type": "record", "name": "User", "fields": [{"name": "country", "type": "string"}]}`,
+		// record isn't an array
+		`{"type": "record", "name": "User", "fields": [{"name": "address", "type": {"type": "array", "items": "string"}}]}`,
+	}
+
+	input := newAvroResolveTestOCF(t, nil)
+	for _, c := range cases {
+		s, err := schema.NewSchemaFromAvroSchema([]byte(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := newAvroInnerDecoder(bytes.NewReader(input), s); !errors.Is(err, ErrUnconvertibleRecord) {
+			t.Errorf("expected: %v, but actual: %v\n", ErrUnconvertibleRecord, err)
+		}
+	}
+}
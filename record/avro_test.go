@@ -9,27 +9,53 @@ import (
 	"github.com/linkedin/goavro/v2"
 )
 
-func TestFlattenAvroUnion(t *testing.T) {
+func TestAvroUnionFlattener(t *testing.T) {
+	rt, names, err := parseAvroWriterSchema(`
+{
+  "type": "record",
+  "name": "Unions",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "primitive", "type": "int"},
+    {"name": "nullable", "type": ["null", "string"]},
+    {"name": "multiple", "type": ["null", "int", "string"]},
+    {"name": "single", "type": {"type": "record", "name": "Single", "fields": [{"name": "string", "type": "string"}]}},
+    {"name": "nullable_single", "type": ["null", "Single"]},
+    {"name": "map", "type": {"type": "map", "values": ["null", "string"]}},
+    {"name": "single_map", "type": {"type": "map", "values": "string"}},
+    {"name": "array", "type": ["null", {"type": "array", "items": "Single"}]},
+    {"name": "timestamp", "type": ["null", {"type": "long", "logicalType": "timestamp-millis"}]}
+  ]
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	input := map[string]interface{}{
-		"primitive": 42,
-		"nested-single": map[string]interface{}{
-			"string": "test",
-		},
-		"nested-multiple": map[string]interface{}{
-			"int":    42,
-			"string": "test",
-		},
+		"primitive":       42,
+		"nullable":        map[string]interface{}{"string": "test"},
+		"multiple":        map[string]interface{}{"int": 42},
+		"single":          map[string]interface{}{"string": "test"},
+		"nullable_single": map[string]interface{}{"com.example.Single": map[string]interface{}{"string": "test"}},
+		"map":             map[string]interface{}{"a": map[string]interface{}{"string": "test"}, "b": nil},
+		"single_map":      map[string]interface{}{"string": "test"},
+		"array":           map[string]interface{}{"array": []interface{}{map[string]interface{}{"string": "test"}}},
+		"timestamp":       map[string]interface{}{"long.timestamp-millis": 1000},
 	}
 	expected := map[string]interface{}{
-		"primitive":     42,
-		"nested-single": "test",
-		"nested-multiple": map[string]interface{}{
-			"int":    42,
-			"string": "test",
-		},
+		"primitive":       42,
+		"nullable":        "test",
+		"multiple":        42,
+		"single":          map[string]interface{}{"string": "test"},
+		"nullable_single": map[string]interface{}{"string": "test"},
+		"map":             map[string]interface{}{"a": "test", "b": nil},
+		"single_map":      map[string]interface{}{"string": "test"},
+		"array":           []interface{}{map[string]interface{}{"string": "test"}},
+		"timestamp":       1000,
 	}
 
-	actual := flattenAvroUnion(input)
+	actual := newAvroUnionFlattener(rt, names).flatten(input)
 
 	if !reflect.DeepEqual(actual, expected) {
 		t.Errorf("expected: %v, but actual: %v\n", expected, actual)
@@ -118,7 +144,7 @@ func TestAvroInnerDecoder_Decode(t *testing.T) {
 
 	for _, c := range cases {
 		buf := bytes.NewReader(c.input)
-		d, err := newAvroInnerDecoder(buf)
+		d, err := newAvroInnerDecoder(buf, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -0,0 +1,279 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// intervalCanonicalPattern matches BigQuery's canonical interval format, [sign]Y-M [sign]D [sign]H:M:S[.F]
+	intervalCanonicalPattern = regexp.MustCompile(`^([-+]?)(\d+)-(\d+) ([-+]?\d+) ([-+]?)(\d+):(\d+):(\d+)(?:\.(\d{1,9}))?$`)
+
+	// intervalIsoPattern matches ISO 8601 durations like P1Y2M3DT4H5M6.789S, whose parts can be signed like P-1Y2M
+	intervalIsoPattern = regexp.MustCompile(`^([-+]?)P(?:([-+]?\d+)Y)?(?:([-+]?\d+)M)?(?:([-+]?\d+)W)?(?:([-+]?\d+)D)?(?:T(?:([-+]?\d+)H)?(?:([-+]?\d+)M)?(?:([-+]?)(\d+)(?:\.(\d{1,9}))?S)?)?$`)
+
+	// wktPattern matches the leading keyword of WKT geometries
+	wktPattern = regexp.MustCompile(`(?i)^\s*(POINT|LINESTRING|POLYGON|MULTIPOINT|MULTILINESTRING|MULTIPOLYGON|GEOMETRYCOLLECTION)\s*(EMPTY|\()`)
+)
+
+// parseDecimal validates a decimal text, and returns it as a number to keep all the digits.
+func parseDecimal(v string) (json.Number, error) {
+	if strings.Contains(v, "/") {
+		return "", fmt.Errorf("invalid decimal %s: %w", v, ErrUnconvertibleRecord)
+	}
+	if _, ok := new(big.Rat).SetString(v); !ok {
+		return "", fmt.Errorf("invalid decimal %s: %w", v, ErrUnconvertibleRecord)
+	}
+
+	return json.Number(v), nil
+}
+
+// interval is months, days and microseconds of an interval text, each of which can be negative.
+type interval struct {
+	months, days, micros *big.Int
+}
+
+// parseIntervalParts parses an interval text of BigQuery's canonical format or ISO 8601. Years are counted as
+// 12 months and weeks as 7 days. Fractions under microseconds are rejected because BigQuery doesn't have them.
+func parseIntervalParts(v string) (*interval, error) {
+	n := func(sign string, s string) *big.Int {
+		i, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return new(big.Int)
+		}
+		if sign == "-" {
+			i.Neg(i)
+		}
+		return i
+	}
+	// seconds and fractions in microseconds
+	secondMicros := func(sign string, seconds string, fraction string) (*big.Int, error) {
+		digits := (fraction + "000000000")[:9]
+		if strings.Trim(digits[6:], "0") != "" {
+			return nil, fmt.Errorf("interval %s has fractions under microseconds: %w", v, ErrUnconvertibleRecord)
+		}
+		us := n("", seconds)
+		us.Mul(us, big.NewInt(1000000)).Add(us, n("", digits[:6]))
+		if sign == "-" {
+			us.Neg(us)
+		}
+		return us, nil
+	}
+	hourMinuteMicros := func(hours *big.Int, minutes *big.Int) *big.Int {
+		us := new(big.Int).Mul(hours, big.NewInt(60))
+		us.Add(us, minutes).Mul(us, big.NewInt(60*1000000))
+		return us
+	}
+
+	if m := intervalCanonicalPattern.FindStringSubmatch(v); m != nil {
+		// signs apply to the year-month part and the time part as a whole, e.g. -1-2 is -14 months
+		months := n("", m[2])
+		months.Mul(months, big.NewInt(12)).Add(months, n("", m[3]))
+		if m[1] == "-" {
+			months.Neg(months)
+		}
+		us, err := secondMicros("", m[8], m[9])
+		if err != nil {
+			return nil, err
+		}
+		us.Add(us, hourMinuteMicros(n("", m[6]), n("", m[7])))
+		if m[5] == "-" {
+			us.Neg(us)
+		}
+		return &interval{months: months, days: n("", m[4]), micros: us}, nil
+	}
+
+	if m := intervalIsoPattern.FindStringSubmatch(v); m != nil && !strings.HasSuffix(v, "P") && !strings.HasSuffix(v, "T") {
+		months := n("", m[2])
+		months.Mul(months, big.NewInt(12)).Add(months, n("", m[3]))
+		days := n("", m[4])
+		days.Mul(days, big.NewInt(7)).Add(days, n("", m[5]))
+		us, err := secondMicros(m[8], m[9], m[10])
+		if err != nil {
+			return nil, err
+		}
+		us.Add(us, hourMinuteMicros(n("", m[6]), n("", m[7])))
+		// a leading sign negates all the parts, e.g. -P1D
+		if m[1] == "-" {
+			months.Neg(months)
+			days.Neg(days)
+			us.Neg(us)
+		}
+		return &interval{months: months, days: days, micros: us}, nil
+	}
+
+	return nil, fmt.Errorf("invalid interval %s: %w", v, ErrUnconvertibleRecord)
+}
+
+// parseInterval converts an interval text to months, days and milliseconds of parquet intervals. Negative parts and
+// fractions under milliseconds are rejected instead of being lost because parquet intervals are unsigned milliseconds.
+func parseInterval(v string) (map[string]interface{}, error) {
+	i, err := parseIntervalParts(v)
+	if err != nil {
+		return nil, err
+	}
+
+	ms, sub := new(big.Int).QuoRem(i.micros, big.NewInt(1000), new(big.Int))
+	if sub.Sign() != 0 {
+		return nil, fmt.Errorf("interval %s has fractions under milliseconds: %w", v, ErrUnconvertibleRecord)
+	}
+	parts := []struct {
+		name  string
+		value *big.Int
+	}{{"months", i.months}, {"days", i.days}, {"milliseconds", ms}}
+
+	values := make(map[string]interface{}, len(parts))
+	for _, p := range parts {
+		if p.value.Sign() < 0 {
+			return nil, fmt.Errorf("interval %s has negative %s: %w", v, p.name, ErrUnconvertibleRecord)
+		}
+		if !p.value.IsUint64() || p.value.Uint64() > math.MaxUint32 {
+			return nil, fmt.Errorf("interval %s has too many %s: %w", v, p.name, ErrUnconvertibleRecord)
+		}
+		values[p.name] = p.value.Uint64()
+	}
+
+	return values, nil
+}
+
+// formatInterval converts an interval text to BigQuery's canonical format, e.g. "P-1Y2M" to "-0-10 0 0:0:0".
+// Each part keeps its sign and microseconds.
+func formatInterval(v string) (string, error) {
+	i, err := parseIntervalParts(v)
+	if err != nil {
+		return "", err
+	}
+
+	sign := func(x *big.Int) string {
+		if x.Sign() < 0 {
+			return "-"
+		}
+		return ""
+	}
+	div := func(x *big.Int, y int64) (*big.Int, *big.Int) {
+		return new(big.Int).QuoRem(x, big.NewInt(y), new(big.Int))
+	}
+
+	years, months := div(new(big.Int).Abs(i.months), 12)
+	seconds, micros := div(new(big.Int).Abs(i.micros), 1000000)
+	minutes, seconds := div(seconds, 60)
+	hours, minutes := div(minutes, 60)
+
+	s := fmt.Sprintf("%s%s-%s %s %s%s:%s:%s", sign(i.months), years, months, i.days, sign(i.micros), hours, minutes, seconds)
+	if micros.Sign() != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%06d", micros.Int64()), "0")
+	}
+
+	return s, nil
+}
+
+// parseGeography validates a WKT text of geography. GeoJSON texts are converted to WKT.
+func parseGeography(v string) (string, error) {
+	if wktPattern.MatchString(v) {
+		return v, nil
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(v), &m); err != nil {
+		return "", fmt.Errorf("invalid geography %s: %w", v, ErrUnconvertibleRecord)
+	}
+
+	return geoJSONToWKT(m)
+}
+
+// geoJSONToWKT converts a GeoJSON geometry object to WKT.
+func geoJSONToWKT(m map[string]interface{}) (string, error) {
+	typ, _ := m["type"].(string)
+	if typ == "GeometryCollection" {
+		geometries, ok := m["geometries"].([]interface{})
+		if !ok {
+			return "", fmt.Errorf("invalid GeoJSON %v: %w", m, ErrUnconvertibleRecord)
+		}
+		if len(geometries) == 0 {
+			return "GEOMETRYCOLLECTION EMPTY", nil
+		}
+		wkts := make([]string, len(geometries))
+		for i, g := range geometries {
+			gm, ok := g.(map[string]interface{})
+			if !ok {
+				return "", fmt.Errorf("invalid GeoJSON %v: %w", m, ErrUnconvertibleRecord)
+			}
+			wkt, err := geoJSONToWKT(gm)
+			if err != nil {
+				return "", err
+			}
+			wkts[i] = wkt
+		}
+		return "GEOMETRYCOLLECTION (" + strings.Join(wkts, ", ") + ")", nil
+	}
+
+	// depth of nested coordinate arrays for each geometry type
+	depths := map[string]int{
+		"Point":           0,
+		"LineString":      1,
+		"MultiPoint":      1,
+		"Polygon":         2,
+		"MultiLineString": 2,
+		"MultiPolygon":    3,
+	}
+	depth, ok := depths[typ]
+	if !ok {
+		return "", fmt.Errorf("unsupported GeoJSON type %v: %w", m["type"], ErrUnconvertibleRecord)
+	}
+
+	coordinates, ok := m["coordinates"].([]interface{})
+	if !ok {
+		return "", fmt.Errorf("invalid GeoJSON coordinates %v: %w", m["coordinates"], ErrUnconvertibleRecord)
+	}
+	if len(coordinates) == 0 {
+		return strings.ToUpper(typ) + " EMPTY", nil
+	}
+	wkt, err := wktCoordinates(coordinates, depth)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToUpper(typ) + " " + wkt, nil
+}
+
+// wktCoordinates formats nested coordinate arrays, which a position is at the given depth.
+func wktCoordinates(v []interface{}, depth int) (string, error) {
+	if depth == 0 {
+		ns := make([]string, len(v))
+		for i, e := range v {
+			switch ee := e.(type) {
+			case float64:
+				ns[i] = strconv.FormatFloat(ee, 'f', -1, 64)
+			case json.Number:
+				ns[i] = ee.String()
+			default:
+				return "", fmt.Errorf("invalid GeoJSON position %v: %w", v, ErrUnconvertibleRecord)
+			}
+		}
+		return "(" + strings.Join(ns, " ") + ")", nil
+	}
+
+	parts := make([]string, len(v))
+	for i, e := range v {
+		ee, ok := e.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("invalid GeoJSON coordinates %v: %w", v, ErrUnconvertibleRecord)
+		}
+		part, err := wktCoordinates(ee, depth-1)
+		if err != nil {
+			return "", err
+		}
+		if depth == 1 {
+			// positions in a list aren't parenthesized, e.g. LINESTRING (0 0, 1 1)
+			part = strings.Trim(part, "()")
+		}
+		parts[i] = part
+	}
+
+	return "(" + strings.Join(parts, ", ") + ")", nil
+}
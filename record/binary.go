@@ -0,0 +1,217 @@
+package record
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+const (
+	BinaryEncodingRaw    = "raw"
+	BinaryEncodingBase64 = "base64"
+	BinaryEncodingHex    = "hex"
+)
+
+// binaryValue is raw bytes of a binary column. It's marshaled to a JSON string of code points 0-255 like the JSON
+// encoding of Avro bytes, because JSON strings can't have arbitrary bytes.
+type binaryValue []byte
+
+func (b binaryValue) MarshalJSON() ([]byte, error) {
+	rs := make([]rune, len(b))
+	for i, c := range b {
+		rs[i] = rune(c)
+	}
+
+	return json.Marshal(string(rs))
+}
+
+// binaryDecodeFunc decodes a string value of the binary column at the path.
+type binaryDecodeFunc func(v, path string) ([]byte, error)
+
+// binaryEncodings are encodings of string values of binary columns keyed by field paths like "nested.payload".
+// Elements of lists and values of maps have the same paths as their fields. Values are raw UTF-8 by default.
+type binaryEncodings map[string]string
+
+// newBinaryEncodings validates encodings, and paths which must be of binary columns of the schema.
+func newBinaryEncodings(encodings map[string]string, s *schema.IntermediateSchema) (binaryEncodings, error) {
+	paths := make(map[string]bool)
+	if s != nil {
+		for _, f := range s.ArrowSchema.Fields() {
+			collectBinaryPaths(f.Type, f.Name, paths)
+		}
+	}
+
+	for p, e := range encodings {
+		switch e {
+		case BinaryEncodingRaw, BinaryEncodingBase64, BinaryEncodingHex:
+		default:
+			return nil, fmt.Errorf("unsupported binary encoding %s of %s: %w", e, p, ErrUnsupportedRecord)
+		}
+		if !paths[p] {
+			return nil, fmt.Errorf("%s is not a binary column: %w", p, ErrUnsupportedRecord)
+		}
+	}
+
+	return encodings, nil
+}
+
+func collectBinaryPaths(t arrow.DataType, path string, paths map[string]bool) {
+	switch tt := t.(type) {
+	case *arrow.StructType:
+		for _, f := range tt.Fields() {
+			collectBinaryPaths(f.Type, path+"."+f.Name, paths)
+		}
+	case *arrow.ListType:
+		collectBinaryPaths(tt.Elem(), path, paths)
+	case *schema.MapType:
+		collectBinaryPaths(tt.ValueType(), path, paths)
+	default:
+		if isBinaryType(t) {
+			paths[path] = true
+		}
+	}
+}
+
+// decode decodes a string value by the encoding of the path.
+func (e binaryEncodings) decode(v, path string) ([]byte, error) {
+	switch e[path] {
+	case BinaryEncodingBase64:
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 %s: %w", v, ErrUnconvertibleRecord)
+		}
+		return b, nil
+
+	case BinaryEncodingHex:
+		b, err := hex.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex %s: %w", v, ErrUnconvertibleRecord)
+		}
+		return b, nil
+	}
+
+	return []byte(v), nil
+}
+
+// decodeCodePoints decodes a string of code points 0-255, e.g. a default value of Avro bytes.
+func decodeCodePoints(v, _ string) ([]byte, error) {
+	b := make([]byte, 0, len(v))
+	for _, r := range v {
+		if r > 0xff {
+			return nil, fmt.Errorf("invalid bytes %q: %w", v, ErrUnconvertibleRecord)
+		}
+		b = append(b, byte(r))
+	}
+
+	return b, nil
+}
+
+// convertBinaryRecord converts values of binary columns to binaryValue by the schema. Raw bytes decoded from binary
+// formats are kept as they are, and strings are decoded by the encodings. Bytes of string columns are converted to
+// strings instead of base64 texts.
+func convertBinaryRecord(m map[string]interface{}, s *schema.IntermediateSchema, decode binaryDecodeFunc) error {
+	return convertBinaryStruct(m, s.ArrowSchema.Fields(), "", decode)
+}
+
+func convertBinaryStruct(m map[string]interface{}, fields []arrow.Field, prefix string, decode binaryDecodeFunc) error {
+	for _, f := range fields {
+		v, ok := m[f.Name]
+		if !ok || v == nil {
+			continue
+		}
+
+		vv, err := convertBinary(v, f.Type, prefix+f.Name, decode)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		m[f.Name] = vv
+	}
+
+	return nil
+}
+
+func convertBinary(v interface{}, t arrow.DataType, path string, decode binaryDecodeFunc) (interface{}, error) {
+	switch vv := v.(type) {
+	case []byte:
+		if _, ok := t.(*schema.UUIDType); ok || isBinaryType(t) {
+			return binaryValue(vv), nil
+		}
+		if _, ok := t.(*schema.IntervalType); ok {
+			return durationValue(vv)
+		}
+		if t.ID() == arrow.STRING {
+			return string(vv), nil
+		}
+
+	case string:
+		if isBinaryType(t) {
+			b, err := decode(vv, path)
+			if err != nil {
+				return nil, err
+			}
+			return binaryValue(b), nil
+		}
+
+	case map[string]interface{}:
+		switch tt := t.(type) {
+		case *arrow.StructType:
+			return vv, convertBinaryStruct(vv, tt.Fields(), path+".", decode)
+		case *schema.MapType:
+			for k, e := range vv {
+				if e == nil {
+					continue
+				}
+				ee, err := convertBinary(e, tt.ValueType(), path, decode)
+				if err != nil {
+					return nil, err
+				}
+				vv[k] = ee
+			}
+		}
+
+	case []interface{}:
+		if lt, ok := t.(*arrow.ListType); ok {
+			for i, e := range vv {
+				if e == nil {
+					continue
+				}
+				ee, err := convertBinary(e, lt.Elem(), path, decode)
+				if err != nil {
+					return nil, err
+				}
+				vv[i] = ee
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// durationValue converts an Avro duration, fixed(12) of months, days and milliseconds in little-endian uint32, to an
+// interval object.
+func durationValue(b []byte) (map[string]interface{}, error) {
+	if len(b) != 12 {
+		return nil, fmt.Errorf("invalid duration of %d bytes: %w", len(b), ErrUnconvertibleRecord)
+	}
+
+	return map[string]interface{}{
+		"months":       binary.LittleEndian.Uint32(b[0:4]),
+		"days":         binary.LittleEndian.Uint32(b[4:8]),
+		"milliseconds": binary.LittleEndian.Uint32(b[8:12]),
+	}, nil
+}
+
+// isBinaryType returns true if the type is bytes without logical types, BINARY or FIXED_SIZE_BINARY.
+func isBinaryType(t arrow.DataType) bool {
+	switch t.(type) {
+	case *arrow.BinaryType, *arrow.FixedSizeBinaryType:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,129 @@
+package record
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/reproio/columnify/schema"
+)
+
+func TestConvertBinaryRecord(t *testing.T) {
+	s, err := schema.NewSchemaFromAvroSchema([]byte(`
+{
+  "type": "record",
+  "name": "Binaries",
+  "fields": [
+    {"name": "raw", "type": "bytes"},
+    {"name": "base64", "type": "bytes"},
+    {"name": "hex", "type": ["null", {"type": "fixed", "name": "Hash", "size": 2}]},
+    {"name": "string", "type": "string"},
+    {"name": "nested", "type": {"type": "record", "name": "Nested", "fields": [
+      {"name": "values", "type": {"type": "array", "items": "bytes"}}
+    ]}},
+    {"name": "map", "type": {"type": "map", "values": "bytes"}}
+  ]
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodings, err := newBinaryEncodings(map[string]string{
+		"base64":        BinaryEncodingBase64,
+		"hex":           BinaryEncodingHex,
+		"nested.values": BinaryEncodingBase64,
+	}, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		input    map[string]interface{}
+		expected string
+		err      error
+	}{
+		// strings are decoded by the encodings
+		{
+			input: map[string]interface{}{
+				"raw":    "é",
+				"base64": "/wA=",
+				"hex":    "ff00",
+				"string": "foo",
+				"nested": map[string]interface{}{"values": []interface{}{"AQ==", nil}},
+				"map":    map[string]interface{}{"k": "v"},
+			},
+			expected: `{"base64":"ÿ\u0000","hex":"ÿ\u0000","map":{"k":"v"},"nested":{"values":["\u0001",null]},"raw":"Ã©","string":"foo"}`,
+		},
+		// raw bytes are kept, and converted to strings for string columns
+		{
+			input: map[string]interface{}{
+				"raw":    []byte{0xff},
+				"base64": []byte{0xfe},
+				"hex":    nil,
+				"string": []byte("foo"),
+				"nested": map[string]interface{}{"values": []interface{}{[]byte{0xe9}}},
+				"map":    map[string]interface{}{"k": []byte{0x00}},
+			},
+			expected: `{"base64":"þ","hex":null,"map":{"k":"\u0000"},"nested":{"values":["é"]},"raw":"ÿ","string":"foo"}`,
+		},
+		{
+			input: map[string]interface{}{"base64": "!"},
+			err:   ErrUnconvertibleRecord,
+		},
+		{
+			input: map[string]interface{}{"hex": "0"},
+			err:   ErrUnconvertibleRecord,
+		},
+	}
+
+	for _, c := range cases {
+		err := convertBinaryRecord(c.input, s, encodings.decode)
+		if !errors.Is(err, c.err) {
+			t.Errorf("expected: %v, but actual: %v\n", c.err, err)
+		}
+		if c.err != nil {
+			continue
+		}
+
+		actual, err := json.Marshal(c.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(actual) != c.expected {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, string(actual))
+		}
+	}
+}
+
+func TestNewBinaryEncodings(t *testing.T) {
+	s, err := schema.NewSchemaFromAvroSchema([]byte(`
+{
+  "type": "record",
+  "name": "Binaries",
+  "fields": [
+    {"name": "bytes", "type": "bytes"},
+    {"name": "string", "type": "string"}
+  ]
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		encodings map[string]string
+		err       error
+	}{
+		{encodings: map[string]string{"bytes": BinaryEncodingRaw}, err: nil},
+		{encodings: map[string]string{"bytes": "base32"}, err: ErrUnsupportedRecord},
+		{encodings: map[string]string{"string": BinaryEncodingHex}, err: ErrUnsupportedRecord},
+		{encodings: map[string]string{"unknown": BinaryEncodingHex}, err: ErrUnsupportedRecord},
+	}
+
+	for _, c := range cases {
+		_, err := newBinaryEncodings(c.encodings, s)
+		if !errors.Is(err, c.err) {
+			t.Errorf("expected: %v, but actual: %v\n", c.err, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package record
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/reproio/columnify/schema"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	bsonLengthSize   = 4
+	bsonMinDocLength = 5
+	// bsonMaxDocLength is the maximum document size of MongoDB, so corrupt lengths don't allocate huge buffers
+	bsonMaxDocLength = 16 * 1024 * 1024
+)
+
+// bsonInnerDecoder decodes a sequence of BSON documents like mongodump .bson files.
+type bsonInnerDecoder struct {
+	r      io.Reader
+	schema *schema.IntermediateSchema
+}
+
+func newBsonInnerDecoder(r io.Reader, s *schema.IntermediateSchema) *bsonInnerDecoder {
+	return &bsonInnerDecoder{
+		r:      r,
+		schema: s,
+	}
+}
+
+func (d *bsonInnerDecoder) Decode(r *map[string]interface{}) error {
+	// Each document starts with its int32 total length
+	header := make([]byte, bsonLengthSize)
+	if _, err := io.ReadFull(d.r, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("truncated document length: %w", ErrUnconvertibleRecord)
+		}
+		return err
+	}
+
+	length := int(int32(binary.LittleEndian.Uint32(header)))
+	if length < bsonMinDocLength || length > bsonMaxDocLength {
+		return fmt.Errorf("invalid document length %d: %w", length, ErrUnconvertibleRecord)
+	}
+
+	doc := make([]byte, length)
+	copy(doc, header)
+	if _, err := io.ReadFull(d.r, doc[bsonLengthSize:]); err != nil {
+		return fmt.Errorf("truncated document %v: %w", err, ErrUnconvertibleRecord)
+	}
+
+	var v bson.D
+	if err := bson.Unmarshal(doc, &v); err != nil {
+		return err
+	}
+
+	record, err := coerceNativeRecord(normalizeBson(v).(map[string]interface{}), d.schema)
+	if err != nil {
+		return err
+	}
+	*r = record
+
+	return nil
+}
+
+// normalizeBson converts BSON specific values to JSON marshalable ones.
+// Datetime and timestamp are converted to time.Time, binary to []byte.
+func normalizeBson(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case primitive.D:
+		m := make(map[string]interface{}, len(vv))
+		for _, e := range vv {
+			m[e.Key] = normalizeBson(e.Value)
+		}
+		return m
+	case primitive.M:
+		m := make(map[string]interface{}, len(vv))
+		for k, e := range vv {
+			m[k] = normalizeBson(e)
+		}
+		return m
+	case primitive.A:
+		a := make([]interface{}, len(vv))
+		for i, e := range vv {
+			a[i] = normalizeBson(e)
+		}
+		return a
+	case primitive.DateTime:
+		return vv.Time().UTC()
+	case primitive.Timestamp:
+		return time.Unix(int64(vv.T), 0).UTC()
+	case primitive.Binary:
+		return vv.Data
+	case primitive.ObjectID:
+		return vv.Hex()
+	case primitive.Decimal128:
+		return vv.String()
+	case primitive.Regex:
+		return vv.String()
+	case primitive.JavaScript:
+		return string(vv)
+	case primitive.Symbol:
+		return string(vv)
+	case primitive.DBPointer:
+		return vv.String()
+	case primitive.Null, primitive.Undefined, primitive.MinKey, primitive.MaxKey:
+		return nil
+	}
+
+	return v
+}
@@ -0,0 +1,123 @@
+package record
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBsonInnerDecoder_Decode(t *testing.T) {
+	tm := time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	oid, err := primitive.ObjectIDFromHex("5f0c1b5e8f1b2c3d4e5f6a7b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decimal, err := primitive.ParseDecimal128("1.50")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs := func(docs ...bson.D) []byte {
+		buf := &bytes.Buffer{}
+		for _, d := range docs {
+			b, err := bson.Marshal(d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf.Write(b)
+		}
+		return buf.Bytes()
+	}
+
+	cases := []struct {
+		input    []byte
+		expected []map[string]interface{}
+		isErr    bool
+	}{
+		// Primitives and logical types
+		{
+			input: docs(
+				bson.D{
+					{Key: "_id", Value: oid},
+					{Key: "int", Value: int64(1)},
+					{Key: "timestamp", Value: primitive.NewDateTimeFromTime(tm)},
+					{Key: "date", Value: primitive.NewDateTimeFromTime(tm)},
+					{Key: "bytes", Value: primitive.Binary{Data: []byte("foo")}},
+					{Key: "record", Value: bson.D{{Key: "timestamp", Value: primitive.NewDateTimeFromTime(tm)}}},
+					{Key: "array", Value: bson.A{primitive.NewDateTimeFromTime(tm), primitive.Null{}}},
+				},
+				bson.D{
+					{Key: "int", Value: int32(2)},
+					{Key: "decimal", Value: decimal},
+				},
+			),
+			expected: []map[string]interface{}{
+				{
+					"_id":       "5f0c1b5e8f1b2c3d4e5f6a7b",
+					"int":       int64(1),
+					"timestamp": int64(1577934245006),
+					"date":      int64(18263),
+					"bytes":     []byte("foo"),
+					"record":    map[string]interface{}{"timestamp": int64(1577934245006000)},
+					"array":     []interface{}{int64(1577934245006), nil},
+				},
+				{
+					"int":     int32(2),
+					"decimal": "1.50",
+				},
+			},
+			isErr: false,
+		},
+
+		// Truncated
+		{
+			input:    []byte{0x10, 0x00, 0x00, 0x00, 0x00},
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Invalid length
+		{
+			input:    []byte{0x01, 0x00, 0x00, 0x00},
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Length over 16 MiB
+		{
+			input:    []byte{0x01, 0x00, 0x00, 0x01, 0x00},
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		buf := bytes.NewReader(c.input)
+		d := newBsonInnerDecoder(buf, testNativeSchema)
+
+		actual := make([]map[string]interface{}, 0)
+		var err error
+		for {
+			var v map[string]interface{}
+			err = d.Decode(&v)
+			if err != nil {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		if (err != nil && err != io.EOF) != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package record
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/reproio/columnify/schema"
+)
+
+// cborInnerDecoder decodes a CBOR sequence (RFC 8742) of maps.
+type cborInnerDecoder struct {
+	d      *cbor.Decoder
+	schema *schema.IntermediateSchema
+}
+
+func newCborInnerDecoder(r io.Reader, s *schema.IntermediateSchema) (*cborInnerDecoder, error) {
+	dm, err := cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+	}.DecMode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &cborInnerDecoder{
+		d:      dm.NewDecoder(r),
+		schema: s,
+	}, nil
+}
+
+func (d *cborInnerDecoder) Decode(r *map[string]interface{}) error {
+	var v interface{}
+	if err := d.d.Decode(&v); err != nil {
+		return err
+	}
+
+	m, mapOk := normalizeCbor(v).(map[string]interface{})
+	if !mapOk {
+		return fmt.Errorf("invalid input %v: %w", v, ErrUnconvertibleRecord)
+	}
+
+	record, err := coerceNativeRecord(m, d.schema)
+	if err != nil {
+		return err
+	}
+	*r = record
+
+	return nil
+}
+
+// normalizeCbor converts CBOR specific values to JSON marshalable ones.
+// Standard date/time tags are already decoded as time.Time.
+func normalizeCbor(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, e := range vv {
+			vv[k] = normalizeCbor(e)
+		}
+	case []interface{}:
+		for i, e := range vv {
+			vv[i] = normalizeCbor(e)
+		}
+	case big.Int:
+		return &vv
+	case cbor.Tag:
+		return normalizeCbor(vv.Content)
+	case cbor.SimpleValue:
+		return uint8(vv)
+	}
+
+	return v
+}
@@ -0,0 +1,125 @@
+package record
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/reproio/columnify/schema"
+)
+
+var testNativeSchema = schema.NewIntermediateSchema(
+	arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "int", Type: arrow.PrimitiveTypes.Uint64},
+			{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_ms},
+			{Name: "date", Type: arrow.FixedWidthTypes.Date32},
+			{Name: "bytes", Type: arrow.BinaryTypes.Binary},
+			{Name: "record", Type: arrow.StructOf(
+				arrow.Field{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_us},
+			)},
+			{Name: "array", Type: arrow.ListOf(arrow.FixedWidthTypes.Timestamp_ms)},
+		}, nil),
+	"native")
+
+func TestCborInnerDecoder_Decode(t *testing.T) {
+	tm := time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.UTC)
+
+	cases := []struct {
+		input    []byte
+		expected []map[string]interface{}
+		isErr    bool
+	}{
+		// Primitives and logical types
+		{
+			input: func() []byte {
+				em, err := cbor.EncOptions{Time: cbor.TimeRFC3339Nano, TimeTag: cbor.EncTagRequired}.EncMode()
+				if err != nil {
+					t.Fatal(err)
+				}
+				buf := &bytes.Buffer{}
+				for _, v := range []map[string]interface{}{
+					{
+						"int":       1,
+						"timestamp": tm,
+						"date":      tm,
+						"bytes":     []byte("foo"),
+						"record":    map[string]interface{}{"timestamp": tm},
+						"array":     []interface{}{tm, nil},
+					},
+					{
+						"int":   -2,
+						"other": cbor.Tag{Number: 32, Content: "http://example.com"},
+					},
+				} {
+					b, err := em.Marshal(v)
+					if err != nil {
+						t.Fatal(err)
+					}
+					buf.Write(b)
+				}
+				return buf.Bytes()
+			}(),
+			expected: []map[string]interface{}{
+				{
+					"int":       uint64(1),
+					"timestamp": int64(1577934245006),
+					"date":      int64(18263),
+					"bytes":     []byte("foo"),
+					"record":    map[string]interface{}{"timestamp": int64(1577934245006000)},
+					"array":     []interface{}{int64(1577934245006), nil},
+				},
+				{
+					"int":   int64(-2),
+					"other": "http://example.com",
+				},
+			},
+			isErr: false,
+		},
+
+		// Not map
+		{
+			input:    []byte{0x01},
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Not CBOR
+		{
+			input:    []byte{0xff},
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		buf := bytes.NewReader(c.input)
+		d, err := newCborInnerDecoder(buf, testNativeSchema)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := make([]map[string]interface{}, 0)
+		for {
+			var v map[string]interface{}
+			err = d.Decode(&v)
+			if err != nil {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		if (err != nil && err != io.EOF) != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
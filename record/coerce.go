@@ -0,0 +1,445 @@
+package record
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+var (
+	dateLayouts = []string{
+		"2006-01-02",
+		"2006/01/02",
+	}
+
+	timeLayouts = []string{
+		"15:04:05.999999999",
+		"15:04",
+	}
+
+	timestampLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05.999999999Z07:00",
+		"2006-01-02 15:04:05.999999999 -0700",
+		"2006-01-02 15:04:05.999999999 MST", // BigQuery, e.g. 2006-01-02 15:04:05.999999 UTC
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02 15:04:05.999999999",
+		"02/Jan/2006:15:04:05 -0700", // Apache/nginx access log
+		time.RFC1123Z,
+		time.RFC1123,
+		time.Stamp, // RFC3164 syslog, without year
+	}
+
+	// uuidPattern matches UUID texts in the canonical form, or 32 hex digits without hyphens
+	uuidPattern = regexp.MustCompile(`^(?:[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{32})$`)
+)
+
+// coerceRecord converts string values to typed ones by the schema.
+// Values not defined in the schema are kept as strings, and empty values are regarded as null.
+func coerceRecord(values map[string]string, s *schema.IntermediateSchema) (map[string]interface{}, error) {
+	return coerceRecordWithClock(values, s, time.Now)
+}
+
+// coerceRecordWithClock is coerceRecord completing timestamps without years by the clock.
+func coerceRecordWithClock(values map[string]string, s *schema.IntermediateSchema, now func() time.Time) (map[string]interface{}, error) {
+	record := make(map[string]interface{}, len(values))
+
+	for k, v := range values {
+		if v == "" {
+			record[k] = nil
+			continue
+		}
+
+		fields := s.ArrowSchema.FieldIndices(k)
+		if len(fields) == 0 {
+			record[k] = v
+			continue
+		}
+
+		vv, err := coerceStringWithClock(v, s.ArrowSchema.Field(fields[0]).Type, now)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", k, err)
+		}
+		record[k] = vv
+	}
+
+	return record, nil
+}
+
+// coerceString converts a string value to the representation of given arrow type.
+// Temporal values are accepted as both integers of the unit and formatted strings.
+func coerceString(v string, t arrow.DataType) (interface{}, error) {
+	return coerceStringWithClock(v, t, time.Now)
+}
+
+// coerceStringWithClock is coerceString completing timestamps without years by the clock.
+func coerceStringWithClock(v string, t arrow.DataType, now func() time.Time) (interface{}, error) {
+	switch tt := t.(type) {
+	case *schema.GeographyType:
+		return parseGeography(v)
+	case *schema.IntervalType:
+		// Avro durations are fixed(12) which are code points in the JSON encoding. They always have control or
+		// non-ASCII characters unless they are hundreds of millions of months, so printable texts are intervals.
+		if strings.IndexFunc(v, func(r rune) bool { return r < 0x20 || r > 0x7e }) >= 0 {
+			b, err := decodeCodePoints(v, "")
+			if err != nil {
+				return nil, err
+			}
+			return durationValue(b)
+		}
+		return parseInterval(v)
+	case *schema.IntervalStringType:
+		return formatInterval(v)
+	case *schema.UUIDType:
+		return parseUUID(v)
+	case *schema.EnumType:
+		return enumSymbol(v, tt)
+	}
+
+	switch t.ID() {
+	case arrow.BOOL:
+		return strconv.ParseBool(v)
+
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64, arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64:
+		return strconv.ParseInt(v, 10, 64)
+
+	case arrow.FLOAT16, arrow.FLOAT32, arrow.FLOAT64:
+		return strconv.ParseFloat(v, 64)
+
+	case arrow.STRING, arrow.BINARY:
+		return v, nil
+
+	case arrow.DECIMAL:
+		return parseDecimal(v)
+
+	case arrow.DATE32:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i, nil
+		}
+		tm, err := parseTime(v, dateLayouts)
+		if err != nil {
+			return nil, err
+		}
+		return coerceTime(tm, t)
+
+	case arrow.TIME32, arrow.TIME64:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i, nil
+		}
+		tm, err := parseTime(v, timeLayouts)
+		if err != nil {
+			return nil, err
+		}
+		return coerceTime(tm, t)
+
+	case arrow.TIMESTAMP:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i, nil
+		}
+		tm, err := parseTime(v, timestampLayouts)
+		if err != nil {
+			return nil, err
+		}
+		if tm.Year() == 0 {
+			// e.g. RFC3164 syslog timestamps, which are regarded as in the current year
+			tm = tm.AddDate(now().Year(), 0, 0)
+		}
+		return coerceTime(tm, t)
+	}
+
+	return nil, fmt.Errorf("unable to convert %s to %v: %w", v, t, ErrUnconvertibleRecord)
+}
+
+// coerceNativeRecord converts native values decoded from binary document formats, like timestamps, by the schema.
+func coerceNativeRecord(m map[string]interface{}, s *schema.IntermediateSchema) (map[string]interface{}, error) {
+	return coerceNativeStruct(m, s.ArrowSchema.Fields())
+}
+
+func coerceNativeStruct(m map[string]interface{}, fields []arrow.Field) (map[string]interface{}, error) {
+	for _, f := range fields {
+		v, ok := m[f.Name]
+		if !ok || v == nil {
+			continue
+		}
+
+		vv, err := coerceNative(v, f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		m[f.Name] = vv
+	}
+
+	return m, nil
+}
+
+func coerceNative(v interface{}, t arrow.DataType) (interface{}, error) {
+	switch vv := v.(type) {
+	case time.Time:
+		return coerceTime(vv, t)
+
+	case map[string]interface{}:
+		if st, ok := t.(*arrow.StructType); ok {
+			return coerceNativeStruct(vv, st.Fields())
+		}
+
+	case []interface{}:
+		if lt, ok := t.(*arrow.ListType); ok {
+			for i, e := range vv {
+				if e == nil {
+					continue
+				}
+				ee, err := coerceNative(e, lt.Elem())
+				if err != nil {
+					return nil, err
+				}
+				vv[i] = ee
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// coerceTextRecord converts text values of temporal, decimal, interval, geography and uuid fields by the schema,
+// e.g. BigQuery exports have timestamps like "2006-01-02 15:04:05 UTC" and numerics as strings in JSON.
+// Symbols of enum fields are validated here too.
+func coerceTextRecord(m map[string]interface{}, s *schema.IntermediateSchema) (map[string]interface{}, error) {
+	return coerceTextStruct(m, s.ArrowSchema.Fields())
+}
+
+func coerceTextStruct(m map[string]interface{}, fields []arrow.Field) (map[string]interface{}, error) {
+	for _, f := range fields {
+		v, ok := m[f.Name]
+		if !ok || v == nil {
+			continue
+		}
+
+		vv, err := coerceText(v, f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		m[f.Name] = vv
+	}
+
+	return m, nil
+}
+
+func coerceText(v interface{}, t arrow.DataType) (interface{}, error) {
+	switch vv := v.(type) {
+	case string:
+		if isTextType(t) {
+			return coerceString(vv, t)
+		}
+
+	case map[string]interface{}:
+		switch tt := t.(type) {
+		case *arrow.StructType:
+			return coerceTextStruct(vv, tt.Fields())
+		case *schema.MapType:
+			for k, e := range vv {
+				if e == nil {
+					continue
+				}
+				ee, err := coerceText(e, tt.ValueType())
+				if err != nil {
+					return nil, err
+				}
+				vv[k] = ee
+			}
+		case *schema.GeographyType:
+			return geoJSONToWKT(vv)
+		}
+
+	case []interface{}:
+		if lt, ok := t.(*arrow.ListType); ok {
+			for i, e := range vv {
+				if e == nil {
+					continue
+				}
+				ee, err := coerceText(e, lt.Elem())
+				if err != nil {
+					return nil, err
+				}
+				vv[i] = ee
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// isTextType returns true if string values of the type are converted by coerceText.
+func isTextType(t arrow.DataType) bool {
+	switch t.(type) {
+	case *schema.GeographyType, *schema.IntervalStringType, *schema.UUIDType, *schema.EnumType:
+		return true
+	}
+
+	switch t.ID() {
+	case arrow.DATE32, arrow.TIME32, arrow.TIME64, arrow.TIMESTAMP, arrow.DECIMAL, arrow.INTERVAL:
+		return true
+	}
+
+	return false
+}
+
+// coerceTime converts a time to the representation of given arrow type.
+func coerceTime(tm time.Time, t arrow.DataType) (interface{}, error) {
+	switch t.ID() {
+	case arrow.DATE32:
+		days := tm.Unix() / (24 * 60 * 60)
+		if tm.Unix() < 0 && tm.Unix()%(24*60*60) != 0 {
+			days--
+		}
+		return days, nil
+
+	case arrow.TIME32, arrow.TIME64:
+		d := time.Duration(tm.Hour())*time.Hour + time.Duration(tm.Minute())*time.Minute +
+			time.Duration(tm.Second())*time.Second + time.Duration(tm.Nanosecond())
+		return durationToUnit(d, t), nil
+
+	case arrow.TIMESTAMP:
+		return timestampToUnit(tm, t.(*arrow.TimestampType))
+
+	case arrow.STRING:
+		return tm.Format(time.RFC3339Nano), nil
+	}
+
+	return nil, fmt.Errorf("unable to convert time %v to %v: %w", tm, t, ErrUnconvertibleRecord)
+}
+
+// coerceEpoch converts an integer timestamp of the unit to the representation of given arrow type.
+// The integer is kept as is for non-temporal types.
+func coerceEpoch(v int64, unit time.Duration, t arrow.DataType) (interface{}, error) {
+	if !isTemporalType(t) {
+		return v, nil
+	}
+
+	// seconds and the rest, because nanoseconds of the integer can overflow
+	perSecond := int64(time.Second / unit)
+	return coerceTime(time.Unix(v/perSecond, v%perSecond*int64(unit)).UTC(), t)
+}
+
+func isTemporalType(t arrow.DataType) bool {
+	switch t.ID() {
+	case arrow.DATE32, arrow.TIME32, arrow.TIME64, arrow.TIMESTAMP:
+		return true
+	}
+
+	return false
+}
+
+// schemaFieldType returns the type of the top-level field.
+func schemaFieldType(s *schema.IntermediateSchema, name string) (arrow.DataType, bool) {
+	fields := s.ArrowSchema.FieldIndices(name)
+	if len(fields) == 0 {
+		return nil, false
+	}
+
+	return s.ArrowSchema.Field(fields[0]).Type, true
+}
+
+// isMapField returns true if the top-level field is a map.
+func isMapField(s *schema.IntermediateSchema, name string) bool {
+	t, ok := schemaFieldType(s, name)
+	if !ok {
+		return false
+	}
+	_, ok = t.(*schema.MapType)
+
+	return ok
+}
+
+// parseUUID converts a UUID text like 123e4567-e89b-12d3-a456-426614174000 to the 16 bytes.
+func parseUUID(v string) (binaryValue, error) {
+	if !uuidPattern.MatchString(v) {
+		return nil, fmt.Errorf("invalid uuid %s: %w", v, ErrUnconvertibleRecord)
+	}
+
+	b, err := hex.DecodeString(strings.ReplaceAll(v, "-", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid uuid %s: %w", v, ErrUnconvertibleRecord)
+	}
+
+	return b, nil
+}
+
+// enumSymbol validates a symbol of the enum. Unknown symbols are replaced with the default symbol if it's declared.
+func enumSymbol(v string, t *schema.EnumType) (string, error) {
+	for _, s := range t.Symbols() {
+		if v == s {
+			return v, nil
+		}
+	}
+	if d := t.Default(); d != "" {
+		return d, nil
+	}
+
+	return "", fmt.Errorf("unknown symbol %s of %v: %w", v, t, ErrUnconvertibleRecord)
+}
+
+func parseTime(v string, layouts []string) (time.Time, error) {
+	for _, l := range layouts {
+		if tm, err := time.Parse(l, v); err == nil {
+			return tm, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unknown time format %s: %w", v, ErrUnconvertibleRecord)
+}
+
+// timestampToUnit converts a time to the epoch in the unit of given timestamp type. It's computed from seconds and
+// nanoseconds because nanoseconds since the epoch overflow out of years 1678-2262, and fails only if the unit overflows.
+func timestampToUnit(tm time.Time, t *arrow.TimestampType) (int64, error) {
+	var perSecond int64
+	switch t.Unit {
+	case arrow.Second:
+		perSecond = 1
+	case arrow.Millisecond:
+		perSecond = 1000
+	case arrow.Microsecond:
+		perSecond = 1000 * 1000
+	default:
+		perSecond = 1000 * 1000 * 1000
+	}
+
+	// nanoseconds are always positive, so fractions are floored
+	sec := tm.Unix()
+	frac := int64(tm.Nanosecond()) / (int64(time.Second) / perSecond)
+	if sec > (math.MaxInt64-frac)/perSecond || sec < math.MinInt64/perSecond {
+		return 0, fmt.Errorf("time %v overflows %v: %w", tm, t, ErrUnconvertibleRecord)
+	}
+
+	return sec*perSecond + frac, nil
+}
+
+// durationToUnit converts the duration to the number of the time unit used by given arrow type.
+func durationToUnit(d time.Duration, t arrow.DataType) int64 {
+	var unit arrow.TimeUnit
+	switch tt := t.(type) {
+	case *arrow.Time32Type:
+		unit = tt.Unit
+	case *arrow.Time64Type:
+		unit = tt.Unit
+	case *arrow.TimestampType:
+		unit = tt.Unit
+	}
+
+	switch unit {
+	case arrow.Second:
+		return int64(d / time.Second)
+	case arrow.Millisecond:
+		return int64(d / time.Millisecond)
+	case arrow.Microsecond:
+		return int64(d / time.Microsecond)
+	}
+
+	return int64(d)
+}
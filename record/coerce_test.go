@@ -0,0 +1,156 @@
+package record
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+func TestCoerceString(t *testing.T) {
+	cases := []struct {
+		input    string
+		dt       arrow.DataType
+		expected interface{}
+		isErr    bool
+	}{
+		{input: "true", dt: arrow.FixedWidthTypes.Boolean, expected: true},
+		{input: "-42", dt: arrow.PrimitiveTypes.Uint32, expected: int64(-42)},
+		{input: "42", dt: arrow.PrimitiveTypes.Uint64, expected: int64(42)},
+		{input: "1.5", dt: arrow.PrimitiveTypes.Float32, expected: float64(1.5)},
+		{input: "foo", dt: arrow.BinaryTypes.String, expected: "foo"},
+		{input: "foo", dt: arrow.BinaryTypes.Binary, expected: "foo"},
+		{input: "1", dt: arrow.FixedWidthTypes.Date32, expected: int64(1)},
+		{input: "1970-01-11", dt: arrow.FixedWidthTypes.Date32, expected: int64(10)},
+		{input: "00:00:01.5", dt: arrow.FixedWidthTypes.Time32ms, expected: int64(1500)},
+		{input: "01:00", dt: arrow.FixedWidthTypes.Time64us, expected: int64(3600000000)},
+		{input: "1000", dt: arrow.FixedWidthTypes.Timestamp_ms, expected: int64(1000)},
+		{input: "1970-01-01T00:00:01.5Z", dt: arrow.FixedWidthTypes.Timestamp_ms, expected: int64(1500)},
+		{input: "1970-01-01 09:00:01+09:00", dt: arrow.FixedWidthTypes.Timestamp_us, expected: int64(1000000)},
+		{input: "01/Jan/1970:00:00:01 +0000", dt: arrow.FixedWidthTypes.Timestamp_ms, expected: int64(1000)},
+		{input: "1970-01-01 00:00:01.5 UTC", dt: arrow.FixedWidthTypes.Timestamp_us, expected: int64(1500000)},
+		{input: "1970-01-01T00:00:01.5", dt: &arrow.TimestampType{Unit: arrow.Microsecond}, expected: int64(1500000)},
+		{input: "0001-01-01T00:00:00Z", dt: arrow.FixedWidthTypes.Timestamp_us, expected: int64(-62135596800000000)},
+		{input: "9999-12-31T23:59:59.999999Z", dt: arrow.FixedWidthTypes.Timestamp_us, expected: int64(253402300799999999)},
+		{input: "1969-12-31T23:59:59.9995Z", dt: arrow.FixedWidthTypes.Timestamp_ms, expected: int64(-1)},
+		{input: "-123.456", dt: &schema.Decimal256Type{Precision: 77, Scale: 38}, expected: json.Number("-123.456")},
+		{input: "1-2 3 4:5:6.789", dt: &schema.IntervalType{}, expected: map[string]interface{}{"months": uint64(14), "days": uint64(3), "milliseconds": uint64(14706789)}},
+		{input: "P1Y2M1W3DT4H5M6.789S", dt: &schema.IntervalType{}, expected: map[string]interface{}{"months": uint64(14), "days": uint64(10), "milliseconds": uint64(14706789)}},
+		{input: "\x01\x00\x00\x00\x02\x00\x00\x00\u00e8\x03\x00\x00", dt: &schema.IntervalType{}, expected: map[string]interface{}{"months": uint32(1), "days": uint32(2), "milliseconds": uint32(1000)}},
+		{input: "PT0.5S", dt: &schema.IntervalType{}, expected: map[string]interface{}{"months": uint64(0), "days": uint64(0), "milliseconds": uint64(500)}},
+		{input: "1-2 3 4:5:6.789", dt: &schema.IntervalStringType{}, expected: "1-2 3 4:5:6.789"},
+		{input: "-1-2 -3 -4:5:6.000001", dt: &schema.IntervalStringType{}, expected: "-1-2 -3 -4:5:6.000001"},
+		{input: "+0-14 +3 0:0:90", dt: &schema.IntervalStringType{}, expected: "1-2 3 0:1:30"},
+		{input: "P-1Y2M-1W3DT-4H5M-6.5S", dt: &schema.IntervalStringType{}, expected: "-0-10 -4 -3:55:6.5"},
+		{input: "-P1DT0.000001S", dt: &schema.IntervalStringType{}, expected: "0-0 -1 -0:0:0.000001"},
+		{input: "PT0S", dt: &schema.IntervalStringType{}, expected: "0-0 0 0:0:0"},
+		{input: "B", dt: schema.EnumOf("A", "B"), expected: "B"},
+		{input: "C", dt: schema.EnumWithDefaultOf("A", "A", "B"), expected: "A"},
+		{input: "123e4567-e89b-12d3-a456-426614174000", dt: &schema.UUIDType{}, expected: binaryValue("\x12\x3e\x45\x67\xe8\x9b\x12\xd3\xa4\x56\x42\x66\x14\x17\x40\x00")},
+		{input: "123E4567E89B12D3A456426614174000", dt: &schema.UUIDType{}, expected: binaryValue("\x12\x3e\x45\x67\xe8\x9b\x12\xd3\xa4\x56\x42\x66\x14\x17\x40\x00")},
+		{input: "POINT(1 2)", dt: &schema.GeographyType{}, expected: "POINT(1 2)"},
+		{input: `{"type": "LineString", "coordinates": [[0, 0], [1.5, 2]]}`, dt: &schema.GeographyType{}, expected: "LINESTRING (0 0, 1.5 2)"},
+		{input: `{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [0, 1], [0, 0]]]}`, dt: &schema.GeographyType{}, expected: "POLYGON ((0 0, 1 0, 0 1, 0 0))"},
+		{input: `{"type": "GeometryCollection", "geometries": [{"type": "Point", "coordinates": [1, 2]}, {"type": "MultiPoint", "coordinates": []}]}`, dt: &schema.GeographyType{}, expected: "GEOMETRYCOLLECTION (POINT (1 2), MULTIPOINT EMPTY)"},
+
+		{input: "yes", dt: arrow.FixedWidthTypes.Boolean, isErr: true},
+		{input: "1.5", dt: arrow.PrimitiveTypes.Uint32, isErr: true},
+		{input: "yesterday", dt: arrow.FixedWidthTypes.Date32, isErr: true},
+		{input: "noon", dt: arrow.FixedWidthTypes.Time32ms, isErr: true},
+		{input: "now", dt: arrow.FixedWidthTypes.Timestamp_ms, isErr: true},
+		{input: "1600-01-01T00:00:00Z", dt: arrow.FixedWidthTypes.Timestamp_ns, isErr: true},
+		{input: "foo", dt: arrow.ListOf(arrow.BinaryTypes.String), isErr: true},
+		{input: "1/3", dt: &schema.Decimal256Type{Precision: 77, Scale: 38}, isErr: true},
+		{input: "NaN", dt: &schema.Decimal256Type{Precision: 77, Scale: 38}, isErr: true},
+		{input: "-1-2 3 4:5:6", dt: &schema.IntervalType{}, isErr: true},
+		{input: "P", dt: &schema.IntervalType{}, isErr: true},
+		{input: "\x01\x00\x00\x00", dt: &schema.IntervalType{}, isErr: true},
+		{input: "\u0100\x00\x00\x00\x02\x00\x00\x00\x03\x00\x00\x00", dt: &schema.IntervalType{}, isErr: true},
+		{input: "PT", dt: &schema.IntervalType{}, isErr: true},
+		{input: "P-1D", dt: &schema.IntervalType{}, isErr: true},
+		{input: "PT0.0005S", dt: &schema.IntervalType{}, isErr: true},
+		{input: "PT0.0000005S", dt: &schema.IntervalStringType{}, isErr: true},
+		{input: "-P", dt: &schema.IntervalStringType{}, isErr: true},
+		{input: "1-2 3", dt: &schema.IntervalStringType{}, isErr: true},
+		{input: "C", dt: schema.EnumOf("A", "B"), isErr: true},
+		{input: "123e4567-e89b-12d3-a456", dt: &schema.UUIDType{}, isErr: true},
+		{input: "123e4567-e89b-12d3-a456-42661417400g", dt: &schema.UUIDType{}, isErr: true},
+		{input: "CIRCLE(1 2)", dt: &schema.GeographyType{}, isErr: true},
+		{input: `{"type": "Feature"}`, dt: &schema.GeographyType{}, isErr: true},
+	}
+
+	for _, c := range cases {
+		actual, err := coerceString(c.input, c.dt)
+
+		if err != nil != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !c.isErr && !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestCoerceStringWithClock_WithoutYear(t *testing.T) {
+	now := func() time.Time { return time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC) }
+
+	actual, err := coerceStringWithClock("Jan  2 03:04:05", arrow.FixedWidthTypes.Timestamp_ms, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+	if actual != expected {
+		t.Errorf("expected: %v, but actual: %v\n", expected, actual)
+	}
+}
+
+func TestCoerceTextRecord(t *testing.T) {
+	s := schema.NewIntermediateSchema(
+		arrow.NewSchema(
+			[]arrow.Field{
+				{Name: "name", Type: arrow.BinaryTypes.String},
+				{Name: "created", Type: arrow.FixedWidthTypes.Timestamp_us},
+				{Name: "location", Type: &schema.GeographyType{}},
+				{Name: "nested", Type: arrow.StructOf(arrow.Field{Name: "date", Type: arrow.FixedWidthTypes.Date32})},
+				{Name: "amounts", Type: arrow.ListOf(&schema.Decimal256Type{Precision: 77, Scale: 38})},
+				{Name: "durations", Type: schema.MapOf(&schema.IntervalType{})},
+			}, nil),
+		"text")
+
+	input := map[string]interface{}{
+		"name":      "1970-01-01",
+		"created":   "1970-01-01 00:00:01 UTC",
+		"location":  map[string]interface{}{"type": "Point", "coordinates": []interface{}{1.0, 2.0}},
+		"nested":    map[string]interface{}{"date": "1970-01-11"},
+		"amounts":   []interface{}{"1.5", nil, json.Number("2")},
+		"durations": map[string]interface{}{"a": "0-0 1 0:0:0", "b": nil},
+		"unknown":   "1970-01-01",
+	}
+	expected := map[string]interface{}{
+		"name":      "1970-01-01",
+		"created":   int64(1000000),
+		"location":  "POINT (1 2)",
+		"nested":    map[string]interface{}{"date": int64(10)},
+		"amounts":   []interface{}{json.Number("1.5"), nil, json.Number("2")},
+		"durations": map[string]interface{}{"a": map[string]interface{}{"months": uint64(0), "days": uint64(1), "milliseconds": uint64(0)}, "b": nil},
+		"unknown":   "1970-01-01",
+	}
+
+	actual, err := coerceTextRecord(input, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected: %v, but actual: %v\n", expected, actual)
+	}
+
+	if _, err := coerceTextRecord(map[string]interface{}{"created": "now"}, s); err == nil {
+		t.Errorf("expected: error, but actual: %v\n", err)
+	}
+}
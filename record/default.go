@@ -0,0 +1,90 @@
+package record
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+// fillDefaultRecord sets default values of the schema to missing fields, including fields of nested records.
+// Fields explicitly set to null are kept as null.
+func fillDefaultRecord(m map[string]interface{}, s *schema.IntermediateSchema) error {
+	return fillDefaultStruct(m, s.ArrowSchema.Fields())
+}
+
+func fillDefaultStruct(m map[string]interface{}, fields []arrow.Field) error {
+	for _, f := range fields {
+		v, ok := m[f.Name]
+		if !ok {
+			d, ok, err := fieldDefault(f)
+			if err != nil {
+				return err
+			}
+			if ok {
+				m[f.Name] = d
+			}
+			continue
+		}
+
+		if err := fillDefault(v, f.Type); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func fillDefault(v interface{}, t arrow.DataType) error {
+	switch tt := t.(type) {
+	case *arrow.StructType:
+		if m, ok := v.(map[string]interface{}); ok {
+			return fillDefaultStruct(m, tt.Fields())
+		}
+
+	case *arrow.ListType:
+		if vs, ok := v.([]interface{}); ok {
+			for _, e := range vs {
+				if err := fillDefault(e, tt.Elem()); err != nil {
+					return err
+				}
+			}
+		}
+
+	case *schema.MapType:
+		if m, ok := v.(map[string]interface{}); ok {
+			for _, e := range m {
+				if err := fillDefault(e, tt.ValueType()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// fieldDefault returns the default value of the field if it has. The value is decoded for each call not to share
+// maps and arrays between records. Bytes are given as strings of code points like Avro, and converted to raw bytes.
+func fieldDefault(f arrow.Field) (interface{}, bool, error) {
+	i := f.Metadata.FindKey(schema.DefaultMetadataKey)
+	if i < 0 {
+		return nil, false, nil
+	}
+
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader([]byte(f.Metadata.Values()[i])))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, false, fmt.Errorf("default of field %s: %v: %w", f.Name, err, ErrUnconvertibleRecord)
+	}
+
+	v, err := convertBinary(v, f.Type, f.Name, decodeCodePoints)
+	if err != nil {
+		return nil, false, fmt.Errorf("default of field %s: %w", f.Name, err)
+	}
+
+	return v, true, nil
+}
@@ -0,0 +1,81 @@
+package record
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/reproio/columnify/schema"
+)
+
+func TestFillDefaultRecord(t *testing.T) {
+	s, err := schema.NewSchemaFromAvroSchema([]byte(`
+{
+  "type": "record",
+  "name": "Defaults",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "count", "type": "int", "default": 0},
+    {"name": "tags", "type": {"type": "array", "items": "string"}, "default": ["a"]},
+    {"name": "comment", "type": ["null", "string"], "default": null},
+    {"name": "magic", "type": "bytes", "default": "\u00ff\u0000"},
+    {"name": "nested", "type": {"type": "record", "name": "Nested", "fields": [
+      {"name": "status", "type": "string", "default": "unknown"}
+    ]}, "default": {}},
+    {"name": "items", "type": {"type": "array", "items": {"type": "record", "name": "Item", "fields": [
+      {"name": "price", "type": "double", "default": 1.5}
+    ]}}}
+  ]
+}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		input    map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			input: map[string]interface{}{"id": 1},
+			expected: map[string]interface{}{
+				"id":      1,
+				"count":   json.Number("0"),
+				"tags":    []interface{}{"a"},
+				"comment": nil,
+				"magic":   binaryValue{0xff, 0x00},
+				"nested":  map[string]interface{}{"status": "unknown"},
+			},
+		},
+		// explicit nulls are kept
+		{
+			input: map[string]interface{}{
+				"id":      2,
+				"count":   nil,
+				"comment": "foo",
+				"magic":   nil,
+				"nested":  map[string]interface{}{},
+				"items":   []interface{}{map[string]interface{}{}, nil},
+			},
+			expected: map[string]interface{}{
+				"id":      2,
+				"count":   nil,
+				"tags":    []interface{}{"a"},
+				"comment": "foo",
+				"magic":   nil,
+				"nested":  map[string]interface{}{"status": "unknown"},
+				"items":   []interface{}{map[string]interface{}{"price": json.Number("1.5")}, nil},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		if err := fillDefaultRecord(c.input, s); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(c.input, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, c.input)
+		}
+	}
+}
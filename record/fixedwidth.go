@@ -0,0 +1,168 @@
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+// FixedWidthMetadataKey is the schema field property to specify a column, e.g.
+// {"name": "amount", "type": "double", "fixedwidth": {"start": 10, "length": 8, "scale": 2}}
+const FixedWidthMetadataKey = "fixedwidth"
+
+// fixedWidthColumn specifies a column position in a line by 0-origin character offset and length.
+type fixedWidthColumn struct {
+	Name   string `json:"name"`
+	Start  int    `json:"start"`
+	Length int    `json:"length"`
+	// Trim removes leading and trailing spaces. Non-string values are always trimmed.
+	Trim bool `json:"trim"`
+	// Scale is the number of implied decimal places, e.g. "012345" with scale 2 is 123.45
+	Scale int `json:"scale"`
+}
+
+type fixedWidthInnerDecoder struct {
+	s       *bufio.Scanner
+	columns []fixedWidthColumn
+	schema  *schema.IntermediateSchema
+	line    int
+}
+
+func newFixedWidthInnerDecoder(r io.Reader, s *schema.IntermediateSchema, specFile string) (*fixedWidthInnerDecoder, error) {
+	columns, err := getFixedWidthColumns(s, specFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fixedWidthInnerDecoder{
+		s:       bufio.NewScanner(r),
+		columns: columns,
+		schema:  s,
+	}, nil
+}
+
+// getFixedWidthColumns reads column specs from the sidecar file as a JSON array, or the schema field properties.
+func getFixedWidthColumns(s *schema.IntermediateSchema, specFile string) ([]fixedWidthColumn, error) {
+	var columns []fixedWidthColumn
+
+	if specFile != "" {
+		content, err := os.ReadFile(specFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(content, &columns); err != nil {
+			return nil, fmt.Errorf("invalid column spec %v: %w", err, ErrUnconvertibleRecord)
+		}
+	} else {
+		for _, f := range s.ArrowSchema.Fields() {
+			idx := f.Metadata.FindKey(FixedWidthMetadataKey)
+			if idx < 0 {
+				continue
+			}
+
+			var c fixedWidthColumn
+			if err := json.Unmarshal([]byte(f.Metadata.Values()[idx]), &c); err != nil {
+				return nil, fmt.Errorf("invalid column spec of %s %v: %w", f.Name, err, ErrUnconvertibleRecord)
+			}
+			c.Name = f.Name
+			columns = append(columns, c)
+		}
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no column spec is available: %w", ErrUnconvertibleRecord)
+	}
+	for _, c := range columns {
+		if c.Name == "" || c.Start < 0 || c.Length <= 0 || c.Scale < 0 {
+			return nil, fmt.Errorf("invalid column spec %+v: %w", c, ErrUnconvertibleRecord)
+		}
+	}
+
+	return columns, nil
+}
+
+func (d *fixedWidthInnerDecoder) Decode(r *map[string]interface{}) error {
+	if d.s.Scan() {
+		d.line++
+		line := []rune(d.s.Text())
+
+		values := make(map[string]string, len(d.columns))
+		for _, c := range d.columns {
+			v := sliceRunes(line, c.Start, c.Length)
+
+			if c.Trim || !isStringField(d.schema, c.Name) {
+				v = strings.TrimSpace(v)
+			}
+
+			if c.Scale > 0 && v != "" {
+				var err error
+				if v, err = applyImpliedScale(v, c.Scale); err != nil {
+					return fmt.Errorf("line %d field %s: %w", d.line, c.Name, err)
+				}
+			}
+
+			values[c.Name] = v
+		}
+
+		record, err := coerceRecord(values, d.schema)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", d.line, err)
+		}
+		*r = record
+	} else {
+		if err := d.s.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+
+	return d.s.Err()
+}
+
+// sliceRunes returns the substring, or shorter one if the line is shorter than the column end.
+func sliceRunes(line []rune, start int, length int) string {
+	if start >= len(line) {
+		return ""
+	}
+
+	end := start + length
+	if end > len(line) {
+		end = len(line)
+	}
+
+	return string(line[start:end])
+}
+
+func isStringField(s *schema.IntermediateSchema, name string) bool {
+	fields, ok := s.ArrowSchema.FieldsByName(name)
+	if !ok {
+		return true
+	}
+
+	id := fields[0].Type.ID()
+	return id == arrow.STRING || id == arrow.BINARY
+}
+
+// applyImpliedScale inserts the decimal point into digits with optional sign.
+func applyImpliedScale(v string, scale int) (string, error) {
+	sign := ""
+	if strings.HasPrefix(v, "-") || strings.HasPrefix(v, "+") {
+		sign, v = v[:1], v[1:]
+	}
+
+	if v == "" || strings.IndexFunc(v, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return "", fmt.Errorf("%s is not digits: %w", v, ErrUnconvertibleRecord)
+	}
+
+	if len(v) <= scale {
+		v = strings.Repeat("0", scale-len(v)+1) + v
+	}
+
+	return sign + v[:len(v)-scale] + "." + v[len(v)-scale:], nil
+}
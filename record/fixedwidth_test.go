@@ -0,0 +1,149 @@
+package record
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+func TestFixedWidthInnerDecoder_Decode(t *testing.T) {
+	specFile := filepath.Join(t.TempDir(), "spec.json")
+	err := os.WriteFile(specFile, []byte(`[
+  {"name": "id",     "start": 0, "length": 3},
+  {"name": "name",   "start": 3, "length": 6, "trim": true},
+  {"name": "amount", "start": 9, "length": 6, "scale": 2}
+]`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		schema   *schema.IntermediateSchema
+		input    []byte
+		specFile string
+		expected []map[string]interface{}
+		isErr    bool
+	}{
+		// Column spec file with trimming and implied decimal
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "id", Type: arrow.PrimitiveTypes.Uint32},
+						{Name: "name", Type: arrow.BinaryTypes.String},
+						{Name: "amount", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+					}, nil),
+				"fixed"),
+			input: []byte(`001 foo  -01234
+002ばーず   000005
+003 qux`),
+			specFile: specFile,
+			expected: []map[string]interface{}{
+				{"id": int64(1), "name": "foo", "amount": float64(-12.34)},
+				{"id": int64(2), "name": "ばーず", "amount": float64(0.05)},
+				{"id": int64(3), "name": "qux", "amount": nil},
+			},
+			isErr: false,
+		},
+
+		// Column specs in schema field metadata
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{
+							Name:     "code",
+							Type:     arrow.BinaryTypes.String,
+							Metadata: arrow.NewMetadata([]string{FixedWidthMetadataKey}, []string{`{"start": 0, "length": 4}`}),
+						},
+						{
+							Name:     "count",
+							Type:     arrow.PrimitiveTypes.Uint64,
+							Metadata: arrow.NewMetadata([]string{FixedWidthMetadataKey}, []string{`{"start": 4, "length": 3}`}),
+						},
+					}, nil),
+				"fixed"),
+			input: []byte(`AB   12
+CD  345`),
+			expected: []map[string]interface{}{
+				{"code": "AB  ", "count": int64(12)},
+				{"code": "CD  ", "count": int64(345)},
+			},
+			isErr: false,
+		},
+
+		// Non digits with implied decimal
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "amount", Type: arrow.PrimitiveTypes.Float64},
+					}, nil),
+				"fixed"),
+			input:    []byte(`001 foo  12.345`),
+			specFile: specFile,
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		buf := bytes.NewReader(c.input)
+		d, err := newFixedWidthInnerDecoder(buf, c.schema, c.specFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := make([]map[string]interface{}, 0)
+		for {
+			var v map[string]interface{}
+			err = d.Decode(&v)
+			if err != nil {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		if (err != nil && err != io.EOF) != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestNewFixedWidthInnerDecoder(t *testing.T) {
+	s := schema.NewIntermediateSchema(
+		arrow.NewSchema(
+			[]arrow.Field{
+				{
+					Name:     "broken",
+					Type:     arrow.BinaryTypes.String,
+					Metadata: arrow.NewMetadata([]string{FixedWidthMetadataKey}, []string{`{"start": 0, "length": 0}`}),
+				},
+			}, nil),
+		"fixed")
+	empty := schema.NewIntermediateSchema(arrow.NewSchema([]arrow.Field{}, nil), "empty")
+
+	for _, c := range []struct {
+		schema   *schema.IntermediateSchema
+		specFile string
+	}{
+		{schema: s, specFile: ""},
+		{schema: empty, specFile: ""},
+		{schema: empty, specFile: "not_found.json"},
+	} {
+		if _, err := newFixedWidthInnerDecoder(bytes.NewReader(nil), c.schema, c.specFile); err == nil {
+			t.Errorf("expected error occurs for %v, but actual it's nil", c)
+		}
+	}
+}
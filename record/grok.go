@@ -0,0 +1,160 @@
+package record
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	RegexPatternApacheCommon   = "apache_common"
+	RegexPatternApacheCombined = "apache_combined"
+	RegexPatternNginx          = "nginx"
+	RegexPatternSyslogRfc3164  = "syslog_rfc3164"
+	RegexPatternSyslogRfc5424  = "syslog_rfc5424"
+
+	grokMaxDepth = 16
+)
+
+var (
+	// grokReference matches %{NAME} or %{NAME:field}
+	grokReference = regexp.MustCompile(`%\{(\w+)(?::(\w+))?\}`)
+
+	// grokBasePatterns are building blocks referred as %{NAME}, similar to Logstash grok patterns.
+	grokBasePatterns = map[string]string{
+		"INT":               `[+-]?\d+`,
+		"POSINT":            `\d+`,
+		"NUMBER":            `[+-]?(?:\d+(?:\.\d*)?|\.\d+)(?:[eE][+-]?\d+)?`,
+		"WORD":              `\w+`,
+		"NOTSPACE":          `\S+`,
+		"SPACE":             `\s*`,
+		"DATA":              `.*?`,
+		"GREEDYDATA":        `.*`,
+		"QUOTEDSTRING":      `"(?:[^"\\]|\\.)*"`,
+		"USERNAME":          `[a-zA-Z0-9._-]+`,
+		"IPV4":              `(?:\d{1,3}\.){3}\d{1,3}`,
+		"IPV6":              `[0-9A-Fa-f]*:[0-9A-Fa-f:.]*`,
+		"IP":                `(?:%{IPV6}|%{IPV4})`,
+		"HOSTNAME":          `[0-9A-Za-z][0-9A-Za-z._-]*`,
+		"IPORHOST":          `(?:%{IP}|%{HOSTNAME})`,
+		"HTTPDATE":          `\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}`,
+		"TIMESTAMP_ISO8601": `\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:Z|[+-]\d{2}:?\d{2})?`,
+		"SYSLOGTIMESTAMP":   `\w{3} [ \d]\d \d{2}:\d{2}:\d{2}`,
+		"SYSLOG5424SD":      `(?:\[(?:[^\]\\]|\\.)*\])+`,
+	}
+
+	// builtinRegexPatterns are whole line patterns selectable by name.
+	builtinRegexPatterns = map[string]string{
+		RegexPatternApacheCommon: `^%{IPORHOST:remote_host} %{NOTSPACE:ident} %{NOTSPACE:remote_user} \[%{HTTPDATE:time}\] ` +
+			`"(?:%{WORD:method} %{NOTSPACE:path}(?: HTTP/%{NUMBER:http_version})?|%{DATA:raw_request})" ` +
+			`%{INT:status} (?:-|%{INT:size})$`,
+		RegexPatternApacheCombined: `^%{IPORHOST:remote_host} %{NOTSPACE:ident} %{NOTSPACE:remote_user} \[%{HTTPDATE:time}\] ` +
+			`"(?:%{WORD:method} %{NOTSPACE:path}(?: HTTP/%{NUMBER:http_version})?|%{DATA:raw_request})" ` +
+			`%{INT:status} (?:-|%{INT:size}) "%{DATA:referer}" "%{DATA:user_agent}"$`,
+		RegexPatternNginx: `^%{IPORHOST:remote_addr} - %{NOTSPACE:remote_user} \[%{HTTPDATE:time_local}\] ` +
+			`"(?:%{WORD:method} %{NOTSPACE:path}(?: HTTP/%{NUMBER:http_version})?|%{DATA:request})" ` +
+			`%{INT:status} %{INT:body_bytes_sent} "%{DATA:http_referer}" "%{DATA:http_user_agent}"$`,
+		RegexPatternSyslogRfc3164: `^(?:<%{POSINT:priority}>)?%{SYSLOGTIMESTAMP:timestamp} %{HOSTNAME:hostname} ` +
+			`%{DATA:program}(?:\[%{POSINT:pid}\])?: %{GREEDYDATA:message}$`,
+		RegexPatternSyslogRfc5424: `^<%{POSINT:priority}>%{POSINT:version} (?:-|%{TIMESTAMP_ISO8601:timestamp}) ` +
+			`(?:-|%{NOTSPACE:hostname}) (?:-|%{NOTSPACE:app_name}) (?:-|%{NOTSPACE:proc_id}) (?:-|%{NOTSPACE:msg_id}) ` +
+			`(?:-|%{SYSLOG5424SD:structured_data})(?: %{GREEDYDATA:message})?$`,
+	}
+)
+
+// compileGrok compiles a builtin pattern name, or a regular expression with grok references.
+// %{NAME:field} is expanded to a named capture group, and %{NAME} to a non-capturing group.
+// Additional pattern definitions are given as "NAME pattern" lines in patternsFile.
+func compileGrok(pattern string, patternsFile string) (*regexp.Regexp, error) {
+	patterns := make(map[string]string, len(grokBasePatterns))
+	for k, v := range grokBasePatterns {
+		patterns[k] = v
+	}
+
+	if patternsFile != "" {
+		defs, err := loadGrokPatterns(patternsFile)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range defs {
+			patterns[k] = v
+		}
+	}
+
+	if p, ok := builtinRegexPatterns[pattern]; ok {
+		pattern = p
+	} else if p, ok := patterns[pattern]; ok {
+		pattern = p
+	}
+
+	expanded, err := expandGrok(pattern, patterns, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %v: %w", pattern, err, ErrUnconvertibleRecord)
+	}
+
+	return re, nil
+}
+
+func expandGrok(pattern string, patterns map[string]string, depth int) (string, error) {
+	if depth > grokMaxDepth {
+		return "", fmt.Errorf("too deep or recursive pattern %s: %w", pattern, ErrUnconvertibleRecord)
+	}
+
+	var err error
+	expanded := grokReference.ReplaceAllStringFunc(pattern, func(ref string) string {
+		m := grokReference.FindStringSubmatch(ref)
+		name, field := m[1], m[2]
+
+		p, ok := patterns[name]
+		if !ok {
+			err = fmt.Errorf("undefined pattern %s: %w", name, ErrUnconvertibleRecord)
+			return ref
+		}
+
+		sub, serr := expandGrok(p, patterns, depth+1)
+		if serr != nil {
+			err = serr
+			return ref
+		}
+
+		if field == "" {
+			return "(?:" + sub + ")"
+		}
+		return "(?P<" + field + ">" + sub + ")"
+	})
+
+	return expanded, err
+}
+
+// loadGrokPatterns reads pattern definitions like "NAME pattern" per line. Blank lines and lines starting with # are ignored.
+func loadGrokPatterns(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	patterns := make(map[string]string)
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		kv := strings.SplitN(line, " ", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid pattern definition %s: %w", line, ErrUnconvertibleRecord)
+		}
+		patterns[kv[0]] = strings.TrimSpace(kv[1])
+	}
+
+	return patterns, s.Err()
+}
@@ -0,0 +1,121 @@
+package record
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompileGrok(t *testing.T) {
+	patternsFile := filepath.Join(t.TempDir(), "patterns")
+	err := os.WriteFile(patternsFile, []byte(`
+# custom patterns
+REQUEST_ID [0-9a-f]{8}
+APP_LINE %{REQUEST_ID:request_id} %{GREEDYDATA:body}
+LOOP %{LOOP}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		pattern  string
+		input    string
+		expected map[string]string
+		isErr    bool
+	}{
+		{
+			pattern: RegexPatternApacheCombined,
+			input:   `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08 [en] (Win98; I ;Nav)"`,
+			expected: map[string]string{
+				"remote_host":  "127.0.0.1",
+				"ident":        "-",
+				"remote_user":  "frank",
+				"time":         "10/Oct/2000:13:55:36 -0700",
+				"method":       "GET",
+				"path":         "/apache_pb.gif",
+				"http_version": "1.0",
+				"raw_request":  "",
+				"status":       "200",
+				"size":         "2326",
+				"referer":      "http://www.example.com/start.html",
+				"user_agent":   "Mozilla/4.08 [en] (Win98; I ;Nav)",
+			},
+			isErr: false,
+		},
+		{
+			pattern: RegexPatternNginx,
+			input:   `10.0.0.1 - - [10/Oct/2000:13:55:36 +0000] "GET / HTTP/2.0" 304 0 "-" "curl/7.68.0"`,
+			expected: map[string]string{
+				"remote_addr":     "10.0.0.1",
+				"remote_user":     "-",
+				"time_local":      "10/Oct/2000:13:55:36 +0000",
+				"method":          "GET",
+				"path":            "/",
+				"http_version":    "2.0",
+				"request":         "",
+				"status":          "304",
+				"body_bytes_sent": "0",
+				"http_referer":    "-",
+				"http_user_agent": "curl/7.68.0",
+			},
+			isErr: false,
+		},
+		{
+			pattern: RegexPatternSyslogRfc3164,
+			input:   `<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8`,
+			expected: map[string]string{
+				"priority":  "34",
+				"timestamp": "Oct 11 22:14:15",
+				"hostname":  "mymachine",
+				"program":   "su",
+				"pid":       "123",
+				"message":   "'su root' failed for lonvick on /dev/pts/8",
+			},
+			isErr: false,
+		},
+		{
+			pattern: "APP_LINE",
+			input:   `deadbeef hello world`,
+			expected: map[string]string{
+				"request_id": "deadbeef",
+				"body":       "hello world",
+			},
+			isErr: false,
+		},
+		{
+			pattern:  "%{LOOP}",
+			expected: nil,
+			isErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		re, err := compileGrok(c.pattern, patternsFile)
+
+		if err != nil != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		m := re.FindStringSubmatch(c.input)
+		if m == nil {
+			t.Errorf("expected %s matches %s", c.input, c.pattern)
+			continue
+		}
+		actual := make(map[string]string)
+		for i, name := range re.SubexpNames() {
+			if name != "" {
+				actual[name] = m[i]
+			}
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package record
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reproio/columnify/schema"
+)
+
+const (
+	// InfluxMeasurementKey is the column of measurement names for influx record type.
+	InfluxMeasurementKey = "measurement"
+	// InfluxTagsKey is the column of tags if it's a map in the schema, otherwise tags are mapped to columns by their keys.
+	InfluxTagsKey = "tags"
+	// InfluxTimestampKey is the column of timestamps for influx record type.
+	InfluxTimestampKey = "timestamp"
+)
+
+var influxPrecisions = map[string]time.Duration{
+	"":   time.Nanosecond,
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// influxInnerDecoder decodes InfluxDB line protocol, e.g.
+// cpu,host=server01,region=us-west usage_idle=92.5,cores=8i,active=true 1434055562000000000
+type influxInnerDecoder struct {
+	s         *bufio.Scanner
+	schema    *schema.IntermediateSchema
+	precision time.Duration
+	tagsAsMap bool
+	line      int
+}
+
+func newInfluxInnerDecoder(r io.Reader, s *schema.IntermediateSchema, precision string) (*influxInnerDecoder, error) {
+	p, ok := influxPrecisions[precision]
+	if !ok {
+		return nil, fmt.Errorf("unsupported precision %s: %w", precision, ErrUnconvertibleRecord)
+	}
+
+	return &influxInnerDecoder{
+		s:         bufio.NewScanner(r),
+		schema:    s,
+		precision: p,
+		tagsAsMap: isMapField(s, InfluxTagsKey),
+	}, nil
+}
+
+func (d *influxInnerDecoder) Decode(r *map[string]interface{}) error {
+	for d.s.Scan() {
+		d.line++
+
+		line := strings.TrimSpace(d.s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		record, err := d.parseLine(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", d.line, err)
+		}
+		*r = record
+
+		return nil
+	}
+
+	if err := d.s.Err(); err != nil {
+		return err
+	}
+
+	return io.EOF
+}
+
+func (d *influxInnerDecoder) parseLine(line string) (map[string]interface{}, error) {
+	sections := splitInfluxLine(line, ' ')
+	if len(sections) < 2 || len(sections) > 3 {
+		return nil, fmt.Errorf("invalid line protocol %s: %w", line, ErrUnconvertibleRecord)
+	}
+
+	record := make(map[string]interface{})
+
+	// measurement and tags
+	series := splitInfluxLine(sections[0], ',')
+	tags := make(map[string]string, len(series)-1)
+	for _, t := range series[1:] {
+		kv := splitInfluxLine(t, '=')
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid tag %s: %w", t, ErrUnconvertibleRecord)
+		}
+		tags[unescapeInflux(kv[0])] = unescapeInflux(kv[1])
+	}
+	if d.tagsAsMap {
+		m := make(map[string]interface{}, len(tags))
+		for k, v := range tags {
+			m[k] = v
+		}
+		record[InfluxTagsKey] = m
+	} else {
+		for _, k := range []string{InfluxMeasurementKey, InfluxTimestampKey} {
+			if _, ok := tags[k]; ok {
+				return nil, fmt.Errorf("tag %s collides with the column of the point, which requires %s column of a map: %w", k, InfluxTagsKey, ErrUnconvertibleRecord)
+			}
+		}
+
+		typed, err := coerceRecord(tags, d.schema)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range typed {
+			record[k] = v
+		}
+	}
+
+	// fields
+	tagColumns := make(map[string]struct{}, len(record))
+	for k := range record {
+		tagColumns[k] = struct{}{}
+	}
+	for _, f := range splitInfluxLine(sections[1], ',') {
+		kv := splitInfluxLine(f, '=')
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid field %s: %w", f, ErrUnconvertibleRecord)
+		}
+
+		k := unescapeInflux(kv[0])
+		if _, ok := tagColumns[k]; ok || k == InfluxMeasurementKey || k == InfluxTimestampKey {
+			return nil, fmt.Errorf("field %s collides with a tag or the column of the point: %w", k, ErrUnconvertibleRecord)
+		}
+		v, err := parseInfluxFieldValue(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", k, err)
+		}
+		if s, ok := v.(string); ok {
+			if t, ok := schemaFieldType(d.schema, k); ok && s != "" {
+				if v, err = coerceString(s, t); err != nil {
+					return nil, fmt.Errorf("field %s: %w", k, err)
+				}
+			}
+		}
+		record[k] = v
+	}
+
+	record[InfluxMeasurementKey] = unescapeInflux(series[0])
+
+	// timestamp
+	record[InfluxTimestampKey] = nil
+	if len(sections) == 3 {
+		ts, err := strconv.ParseInt(sections[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %s: %w", sections[2], ErrUnconvertibleRecord)
+		}
+
+		if t, ok := schemaFieldType(d.schema, InfluxTimestampKey); ok {
+			if record[InfluxTimestampKey], err = coerceEpoch(ts, d.precision, t); err != nil {
+				return nil, err
+			}
+		} else {
+			record[InfluxTimestampKey] = ts
+		}
+	}
+
+	return record, nil
+}
+
+// splitInfluxLine splits the text by the separator, except escaped ones and ones in double quoted strings.
+func splitInfluxLine(s string, sep byte) []string {
+	parts := make([]string, 0)
+
+	start := 0
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// unescapeInflux removes backslashes escaping commas, equal signs and spaces in names, tags and field keys.
+func unescapeInflux(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(`, ="\`, s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+// parseInfluxFieldValue converts a field value to float, integer, unsigned integer, string or boolean.
+func parseInfluxFieldValue(v string) (interface{}, error) {
+	switch {
+	case len(v) >= 2 && strings.HasPrefix(v, `"`) && strings.HasSuffix(v, `"`):
+		return unescapeInflux(v[1 : len(v)-1]), nil
+
+	case strings.HasSuffix(v, "i"):
+		return strconv.ParseInt(strings.TrimSuffix(v, "i"), 10, 64)
+
+	case strings.HasSuffix(v, "u"):
+		return strconv.ParseUint(strings.TrimSuffix(v, "u"), 10, 64)
+	}
+
+	switch v {
+	case "t", "T", "true", "True", "TRUE":
+		return true, nil
+	case "f", "F", "false", "False", "FALSE":
+		return false, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field value %s: %w", v, ErrUnconvertibleRecord)
+	}
+
+	return f, nil
+}
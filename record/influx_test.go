@@ -0,0 +1,187 @@
+package record
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+func TestInfluxInnerDecoder_Decode(t *testing.T) {
+	cases := []struct {
+		schema    *schema.IntermediateSchema
+		input     []byte
+		precision string
+		expected  []map[string]interface{}
+		isErr     bool
+	}{
+		// Tags as a map column
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "measurement", Type: arrow.BinaryTypes.String},
+						{Name: "tags", Type: schema.MapOf(arrow.BinaryTypes.String)},
+						{Name: "usage", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+						{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_ms, Nullable: true},
+					}, nil),
+				"influx"),
+			input: []byte(`# comment
+cpu,host=server01,region=us-west usage=92.5,cores=8i,count=3u,active=t 1434055562000000000
+
+cpu,host=server\ 02 usage=1e3,active=FALSE
+`),
+			expected: []map[string]interface{}{
+				{
+					"measurement": "cpu",
+					"tags":        map[string]interface{}{"host": "server01", "region": "us-west"},
+					"usage":       float64(92.5),
+					"cores":       int64(8),
+					"count":       uint64(3),
+					"active":      true,
+					"timestamp":   int64(1434055562000),
+				},
+				{
+					"measurement": "cpu",
+					"tags":        map[string]interface{}{"host": "server 02"},
+					"usage":       float64(1000),
+					"active":      false,
+					"timestamp":   nil,
+				},
+			},
+			isErr: false,
+		},
+
+		// Tags as columns, escapes in quoted strings, and precision
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "measurement", Type: arrow.BinaryTypes.String},
+						{Name: "code", Type: arrow.PrimitiveTypes.Uint32},
+						{Name: "message", Type: arrow.BinaryTypes.String},
+						{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_ms},
+					}, nil),
+				"influx"),
+			input:     []byte(`log\,s,code=404 message="not found, \"/foo bar\"" 1434055562`),
+			precision: "s",
+			expected: []map[string]interface{}{
+				{
+					"measurement": "log,s",
+					"code":        int64(404),
+					"message":     `not found, "/foo bar"`,
+					"timestamp":   int64(1434055562000),
+				},
+			},
+			isErr: false,
+		},
+
+		// Invalid field value
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema([]arrow.Field{}, nil),
+				"influx"),
+			input:    []byte(`cpu usage=foo`),
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Tags colliding with columns of points
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema([]arrow.Field{}, nil),
+				"influx"),
+			input:    []byte(`cpu,timestamp=yesterday usage=1`),
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Tags of the same names in a map column
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "tags", Type: schema.MapOf(arrow.BinaryTypes.String)},
+					}, nil),
+				"influx"),
+			input: []byte(`cpu,measurement=load,timestamp=yesterday usage=1`),
+			expected: []map[string]interface{}{
+				{
+					"measurement": "cpu",
+					"tags":        map[string]interface{}{"measurement": "load", "timestamp": "yesterday"},
+					"usage":       float64(1),
+					"timestamp":   nil,
+				},
+			},
+			isErr: false,
+		},
+
+		// Fields colliding with tags
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema([]arrow.Field{}, nil),
+				"influx"),
+			input:    []byte(`cpu,host=server01 host="server02"`),
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Fields colliding with columns of points
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema([]arrow.Field{}, nil),
+				"influx"),
+			input:    []byte(`cpu measurement="load"`),
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Missing fields
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema([]arrow.Field{}, nil),
+				"influx"),
+			input:    []byte(`cpu,host=server01`),
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		buf := bytes.NewReader(c.input)
+		d, err := newInfluxInnerDecoder(buf, c.schema, c.precision)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := make([]map[string]interface{}, 0)
+		for {
+			var v map[string]interface{}
+			err = d.Decode(&v)
+			if err != nil {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		if (err != nil && err != io.EOF) != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestNewInfluxInnerDecoder(t *testing.T) {
+	s := schema.NewIntermediateSchema(arrow.NewSchema([]arrow.Field{}, nil), "influx")
+
+	if _, err := newInfluxInnerDecoder(bytes.NewReader(nil), s, "h"); err == nil {
+		t.Errorf("expected error occurs, but actual it's nil")
+	}
+}
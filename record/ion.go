@@ -0,0 +1,100 @@
+package record
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/amazon-ion/ion-go/ion"
+	"github.com/reproio/columnify/schema"
+)
+
+// ionInnerDecoder decodes a stream of Amazon Ion structs, in both text and binary format.
+type ionInnerDecoder struct {
+	d      *ion.Decoder
+	schema *schema.IntermediateSchema
+}
+
+func newIonInnerDecoder(r io.Reader, s *schema.IntermediateSchema) *ionInnerDecoder {
+	return &ionInnerDecoder{
+		d:      ion.NewDecoder(ion.NewReader(r)),
+		schema: s,
+	}
+}
+
+func (d *ionInnerDecoder) Decode(r *map[string]interface{}) error {
+	v, err := d.d.Decode()
+	if err != nil {
+		if errors.Is(err, ion.ErrNoInput) {
+			return io.EOF
+		}
+		return err
+	}
+
+	m, mapOk := normalizeIon(v).(map[string]interface{})
+	if !mapOk {
+		return fmt.Errorf("invalid input %v: %w", v, ErrUnconvertibleRecord)
+	}
+
+	record, err := coerceNativeRecord(m, d.schema)
+	if err != nil {
+		return err
+	}
+	*r = record
+
+	return nil
+}
+
+// normalizeIon converts Ion specific values to JSON marshalable ones.
+// Timestamps are converted to time.Time, and blobs/clobs are []byte.
+func normalizeIon(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		for k, e := range vv {
+			vv[k] = normalizeIon(e)
+		}
+	case []interface{}:
+		for i, e := range vv {
+			vv[i] = normalizeIon(e)
+		}
+	case *string:
+		return *vv
+	case *float64:
+		return *vv
+	case *ion.SymbolToken:
+		if vv.Text == nil {
+			return nil
+		}
+		return *vv.Text
+	case *ion.Timestamp:
+		return vv.GetDateTime()
+	case *ion.Decimal:
+		return ionDecimalToNumber(vv)
+	}
+
+	return v
+}
+
+// ionDecimalToNumber formats an arbitrary precision decimal without exponent to keep it exact.
+func ionDecimalToNumber(d *ion.Decimal) json.Number {
+	coef, exp := d.CoEx()
+
+	r := new(big.Rat).SetInt(coef)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(exp))), nil)
+	if exp >= 0 {
+		r.Mul(r, new(big.Rat).SetInt(scale))
+		return json.Number(r.FloatString(0))
+	}
+	r.Quo(r, new(big.Rat).SetInt(scale))
+
+	return json.Number(r.FloatString(int(-exp)))
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,89 @@
+package record
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestIonInnerDecoder_Decode(t *testing.T) {
+	cases := []struct {
+		input    []byte
+		expected []map[string]interface{}
+		isErr    bool
+	}{
+		// Primitives and logical types
+		{
+			input: []byte(`
+{
+  int: 1,
+  timestamp: 2020-01-02T03:04:05.006Z,
+  date: 2020-01-02T,
+  bytes: {{Zm9v}},
+  record: {timestamp: 2020-01-02T12:04:05.006+09:00},
+  array: [2020-01-02T03:04:05.006Z, null],
+}
+// comments and symbols
+{int: 2, symbol: abc, decimal: 1.50, exponent: 15d-1, annotated: meter::3}`),
+			expected: []map[string]interface{}{
+				{
+					"int":       1,
+					"timestamp": int64(1577934245006),
+					"date":      int64(18263),
+					"bytes":     []byte("foo"),
+					"record":    map[string]interface{}{"timestamp": int64(1577934245006000)},
+					"array":     []interface{}{int64(1577934245006), nil},
+				},
+				{
+					"int":       2,
+					"symbol":    "abc",
+					"decimal":   json.Number("1.50"),
+					"exponent":  json.Number("1.5"),
+					"annotated": 3,
+				},
+			},
+			isErr: false,
+		},
+
+		// Not struct
+		{
+			input:    []byte(`[1, 2]`),
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Not Ion
+		{
+			input:    []byte(`{int: }`),
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		buf := bytes.NewReader(c.input)
+		d := newIonInnerDecoder(buf, testNativeSchema)
+
+		actual := make([]map[string]interface{}, 0)
+		var err error
+		for {
+			var v map[string]interface{}
+			err = d.Decode(&v)
+			if err != nil {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		if (err != nil && err != io.EOF) != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
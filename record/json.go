@@ -0,0 +1,229 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// jsonInnerDecoder decodes a stream of JSON values token by token.
+// It accepts concatenated (optionally pretty-printed) objects, top-level arrays of objects,
+// and documents wrapping records in an array pointed by JSON pointer, e.g. "/Records".
+type jsonInnerDecoder struct {
+	d       *json.Decoder
+	pointer []string
+
+	// depth is the number of containers opened to reach the pointed value
+	depth   int
+	inArray bool
+}
+
+func newJsonInnerDecoder(r io.Reader, pointer string) (*jsonInnerDecoder, error) {
+	p, err := parseJsonPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonInnerDecoder{
+		d:       json.NewDecoder(r),
+		pointer: p,
+	}, nil
+}
+
+// parseJsonPointer splits a JSON pointer (RFC 6901) to unescaped reference tokens.
+func parseJsonPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid json pointer %s: %w", pointer, ErrUnconvertibleRecord)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		t = strings.ReplaceAll(t, "~1", "/")
+		tokens[i] = strings.ReplaceAll(t, "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func (d *jsonInnerDecoder) Decode(r *map[string]interface{}) error {
+	for {
+		if d.inArray {
+			if d.d.More() {
+				var m map[string]interface{}
+				if err := d.d.Decode(&m); err != nil {
+					return err
+				}
+				*r = m
+
+				return nil
+			}
+
+			// consume the end of the array, and the rest of the enclosing values
+			if _, err := d.d.Token(); err != nil {
+				return err
+			}
+			d.inArray = false
+			if err := d.skipRest(); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		tok, err := d.d.Token()
+		if err != nil {
+			return err
+		}
+
+		tok, err = d.seek(tok)
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case json.Delim('['):
+			d.inArray = true
+
+		case json.Delim('{'):
+			m, err := d.decodeObject()
+			if err != nil {
+				return err
+			}
+			*r = m
+
+			return d.skipRest()
+
+		default:
+			return fmt.Errorf("invalid value %v: %w", tok, ErrUnconvertibleRecord)
+		}
+	}
+}
+
+// seek walks through the given top-level value along with the JSON pointer,
+// and returns the first token of the pointed value.
+func (d *jsonInnerDecoder) seek(tok json.Token) (json.Token, error) {
+	d.depth = 0
+
+	for _, p := range d.pointer {
+		found := false
+
+		switch tok {
+		case json.Delim('{'):
+			d.depth++
+			for d.d.More() {
+				key, err := d.d.Token()
+				if err != nil {
+					return nil, err
+				}
+				if key == p {
+					found = true
+					break
+				}
+				if err := d.skipValue(); err != nil {
+					return nil, err
+				}
+			}
+
+		case json.Delim('['):
+			d.depth++
+			idx, err := strconv.Atoi(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid array index %s in json pointer: %w", p, ErrUnconvertibleRecord)
+			}
+			for i := 0; d.d.More(); i++ {
+				if i == idx {
+					found = true
+					break
+				}
+				if err := d.skipValue(); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("json pointer /%s is not found: %w", strings.Join(d.pointer, "/"), ErrUnconvertibleRecord)
+		}
+
+		var err error
+		if tok, err = d.d.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	return tok, nil
+}
+
+// decodeObject decodes the body of an object whose '{' has been already consumed.
+func (d *jsonInnerDecoder) decodeObject() (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+
+	for d.d.More() {
+		key, err := d.d.Token()
+		if err != nil {
+			return nil, err
+		}
+		k, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid key %v: %w", key, ErrUnconvertibleRecord)
+		}
+
+		var v interface{}
+		if err := d.d.Decode(&v); err != nil {
+			return nil, err
+		}
+		m[k] = v
+	}
+
+	// consume '}'
+	if _, err := d.d.Token(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// skipValue reads and discards the next value without holding it in memory.
+func (d *jsonInnerDecoder) skipValue() error {
+	depth := 0
+	for {
+		tok, err := d.d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// skipRest discards the remaining values of the containers enclosing the pointed value.
+func (d *jsonInnerDecoder) skipRest() error {
+	for ; d.depth > 0; d.depth-- {
+		for d.d.More() {
+			// object keys are also skipped as a value
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+		}
+		if _, err := d.d.Token(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package record
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestJsonInnerDecoder_Decode(t *testing.T) {
+	cases := []struct {
+		input    []byte
+		pointer  string
+		expected []map[string]interface{}
+		isErr    bool
+	}{
+		// Concatenated pretty-printed objects
+		{
+			input: []byte(`{
+  "boolean": false,
+  "int": 1,
+  "string": "foo"
+}
+{
+  "boolean": true,
+  "int": 2,
+  "string": "bar"
+}`),
+			pointer: "",
+			expected: []map[string]interface{}{
+				{
+					"boolean": false,
+					"int":     float64(1),
+					"string":  "foo",
+				},
+				{
+					"boolean": true,
+					"int":     float64(2),
+					"string":  "bar",
+				},
+			},
+			isErr: false,
+		},
+
+		// Top-level array
+		{
+			input:   []byte(`[{"int": 1, "nested": {"string": "foo"}}, {"int": 2, "array": [1, 2]}]`),
+			pointer: "",
+			expected: []map[string]interface{}{
+				{
+					"int":    float64(1),
+					"nested": map[string]interface{}{"string": "foo"},
+				},
+				{
+					"int":   float64(2),
+					"array": []interface{}{float64(1), float64(2)},
+				},
+			},
+			isErr: false,
+		},
+
+		// Records wrapped by an object, with JSON pointer
+		{
+			input: []byte(`{"Version": {"major": 1}, "Records": [{"int": 1}, {"int": 2}], "Trailer": [{"int": 3}]}
+{"Records": [{"int": 4}]}`),
+			pointer: "/Records",
+			expected: []map[string]interface{}{
+				{"int": float64(1)},
+				{"int": float64(2)},
+				{"int": float64(4)},
+			},
+			isErr: false,
+		},
+
+		// Nested JSON pointer with array index and escaped token
+		{
+			input:   []byte(`{"data": [{"a/b": [{"int": 0}]}, {"a/b": [{"int": 1}]}]}`),
+			pointer: "/data/1/a~1b",
+			expected: []map[string]interface{}{
+				{"int": float64(1)},
+			},
+			isErr: false,
+		},
+
+		// JSON pointer pointing an object
+		{
+			input:   []byte(`{"record": {"int": 1}}`),
+			pointer: "/record",
+			expected: []map[string]interface{}{
+				{"int": float64(1)},
+			},
+			isErr: false,
+		},
+
+		// JSON pointer to the empty key, not the whole value
+		{
+			input:   []byte(`{"": [{"int": 1}], "Records": [{"int": 2}]}`),
+			pointer: "/",
+			expected: []map[string]interface{}{
+				{"int": float64(1)},
+			},
+			isErr: false,
+		},
+
+		// JSON pointer not found
+		{
+			input:    []byte(`{"Records": [{"int": 1}]}`),
+			pointer:  "/Events",
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Not an object
+		{
+			input:    []byte(`[1, 2]`),
+			pointer:  "",
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Not JSON
+		{
+			input:    []byte("not-valid-json"),
+			pointer:  "",
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		buf := bytes.NewReader(c.input)
+		d, err := newJsonInnerDecoder(buf, c.pointer)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := make([]map[string]interface{}, 0)
+		for {
+			var v map[string]interface{}
+			err = d.Decode(&v)
+			if err != nil {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		if (err != nil && err != io.EOF) != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestParseJsonPointer(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []string
+		isErr    bool
+	}{
+		{
+			input:    "",
+			expected: nil,
+			isErr:    false,
+		},
+		{
+			input:    "/",
+			expected: []string{""},
+			isErr:    false,
+		},
+		{
+			input:    "/Records",
+			expected: []string{"Records"},
+			isErr:    false,
+		},
+		{
+			input:    "/a~1b/c~0d/0",
+			expected: []string{"a/b", "c~d", "0"},
+			isErr:    false,
+		},
+		{
+			input:    "Records",
+			expected: nil,
+			isErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		actual, err := parseJsonPointer(c.input)
+
+		if err != nil != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package record
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reproio/columnify/schema"
+)
+
+const odsContentFile = "content.xml"
+
+var odsTimeValuePattern = regexp.MustCompile(`^PT(\d+)H(\d+)M(\d+(?:\.\d+)?)S$`)
+
+// odsInnerDecoder decodes rows of an OpenDocument spreadsheet by streaming its content.xml.
+type odsInnerDecoder struct {
+	d      *xml.Decoder
+	schema *schema.IntermediateSchema
+	header []string
+	row    int
+
+	// pending is the row repeated by table:number-rows-repeated
+	pending []string
+	repeat  int
+}
+
+func newOdsInnerDecoder(r io.Reader, s *schema.IntermediateSchema, sheet string) (*odsInnerDecoder, error) {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+	if err != nil {
+		return nil, err
+	}
+
+	var content *zip.File
+	for _, f := range zr.File {
+		if f.Name == odsContentFile {
+			content = f
+			break
+		}
+	}
+	if content == nil {
+		return nil, fmt.Errorf("%s is not found: %w", odsContentFile, ErrUnconvertibleRecord)
+	}
+
+	names, err := odsSheetNames(content)
+	if err != nil {
+		return nil, err
+	}
+	name, err := selectSheet(names, sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := content.Open()
+	if err != nil {
+		return nil, err
+	}
+	d := xml.NewDecoder(rc)
+	if err := seekOdsTable(d, name); err != nil {
+		return nil, err
+	}
+
+	return &odsInnerDecoder{
+		d:      d,
+		schema: s,
+	}, nil
+}
+
+func odsSheetNames(content *zip.File) ([]string, error) {
+	rc, err := content.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	names := make([]string, 0)
+	d := xml.NewDecoder(rc)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return names, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "table" {
+			names = append(names, odsAttr(start, "name"))
+			if err := d.Skip(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// seekOdsTable consumes tokens until the start of the named table.
+func seekOdsTable(d *xml.Decoder, name string) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "table" && odsAttr(start, "name") == name {
+			return nil
+		}
+	}
+}
+
+func odsAttr(start xml.StartElement, local string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+
+	return ""
+}
+
+func (d *odsInnerDecoder) Decode(r *map[string]interface{}) error {
+	for {
+		var cells []string
+		if d.repeat > 0 {
+			d.repeat--
+			d.row++
+			cells = d.pending
+		} else {
+			var repeat int
+			var err error
+			cells, repeat, err = d.readRow()
+			if err != nil {
+				return err
+			}
+			d.row++
+			if isBlankRow(cells) {
+				d.row += repeat - 1
+				continue
+			}
+			d.pending, d.repeat = cells, repeat-1
+		}
+
+		if d.header == nil {
+			d.header = trimCells(cells)
+			d.row += d.repeat
+			d.repeat = 0
+			continue
+		}
+
+		record, err := sheetRowToRecord(d.header, cells, d.schema, false)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", d.row, err)
+		}
+		*r = record
+
+		return nil
+	}
+}
+
+// readRow reads the next table:table-row, and returns cell values and the repeated count of the row.
+// It returns io.EOF at the end of the table.
+func (d *odsInnerDecoder) readRow() ([]string, int, error) {
+	for {
+		tok, err := d.d.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "table-row" {
+				continue
+			}
+
+			cells, err := d.readCells()
+			if err != nil {
+				return nil, 0, err
+			}
+			return cells, odsRepeated(t, "number-rows-repeated"), nil
+
+		case xml.EndElement:
+			if t.Name.Local == "table" {
+				return nil, 0, io.EOF
+			}
+		}
+	}
+}
+
+func (d *odsInnerDecoder) readCells() ([]string, error) {
+	cells := make([]string, 0)
+
+	// trailing empty cells are often repeated to the max number of columns, so they're appended lazily
+	empties := 0
+	for {
+		tok, err := d.d.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "table-cell" && t.Name.Local != "covered-table-cell" {
+				if err := d.d.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			v, err := d.readCell(t)
+			if err != nil {
+				return nil, err
+			}
+
+			repeat := odsRepeated(t, "number-columns-repeated")
+			if v == "" {
+				empties += repeat
+				continue
+			}
+			for ; empties > 0; empties-- {
+				cells = append(cells, "")
+			}
+			for i := 0; i < repeat; i++ {
+				cells = append(cells, v)
+			}
+
+		case xml.EndElement:
+			return cells, nil
+		}
+	}
+}
+
+// readCell returns the value of the cell by the value type, or its text contents.
+func (d *odsInnerDecoder) readCell(start xml.StartElement) (string, error) {
+	v := ""
+	switch odsAttr(start, "value-type") {
+	case "float", "percentage", "currency":
+		v = odsAttr(start, "value")
+	case "date":
+		v = odsAttr(start, "date-value")
+	case "time":
+		v = odsTimeValue(odsAttr(start, "time-value"))
+	case "boolean":
+		v = odsAttr(start, "boolean-value")
+	}
+
+	var text strings.Builder
+	paragraphs := 0
+	depth := 0
+	for {
+		tok, err := d.d.Token()
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "annotation":
+				if err := d.d.Skip(); err != nil {
+					return "", err
+				}
+				continue
+			case "p":
+				if paragraphs > 0 {
+					text.WriteString("\n")
+				}
+				paragraphs++
+			case "s":
+				text.WriteString(strings.Repeat(" ", odsRepeated(t, "c")))
+			case "tab":
+				text.WriteString("\t")
+			case "line-break":
+				text.WriteString("\n")
+			}
+			depth++
+
+		case xml.CharData:
+			if depth > 0 {
+				text.Write(t)
+			}
+
+		case xml.EndElement:
+			if depth == 0 {
+				if v != "" {
+					return v, nil
+				}
+				return text.String(), nil
+			}
+			depth--
+		}
+	}
+}
+
+func odsRepeated(start xml.StartElement, local string) int {
+	n, err := strconv.Atoi(odsAttr(start, local))
+	if err != nil || n < 1 {
+		return 1
+	}
+
+	return n
+}
+
+// odsTimeValue converts a duration like PT13H30M00S to a time of day like 13:30:00.
+func odsTimeValue(v string) string {
+	m := odsTimeValuePattern.FindStringSubmatch(v)
+	if m == nil {
+		return v
+	}
+
+	h, _ := strconv.Atoi(m[1])
+	min, _ := strconv.Atoi(m[2])
+	sec, _ := strconv.ParseFloat(m[3], 64)
+	d := time.Duration(h)*time.Hour + time.Duration(min)*time.Minute + time.Duration(math.Round(sec*float64(time.Second)))
+
+	return time.Time{}.Add(d).Format("15:04:05.999999999")
+}
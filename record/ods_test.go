@@ -0,0 +1,139 @@
+package record
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+func newOdsContent(t *testing.T, tables string) []byte {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+
+	c, err := w.Create(odsContentFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:spreadsheet>` + tables + `</office:spreadsheet></office:body></office:document-content>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestOdsInnerDecoder_Decode(t *testing.T) {
+	content := newOdsContent(t, `
+<table:table table:name="empty"/>
+<table:table table:name="data">
+  <table:table-column table:number-columns-repeated="1024"/>
+  <table:table-row table:number-rows-repeated="2"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+  <table:table-header-rows>
+    <table:table-row>
+      <table:table-cell office:value-type="string"><text:p>name</text:p></table:table-cell>
+      <table:table-cell office:value-type="string"><text:p>count</text:p></table:table-cell>
+      <table:table-cell office:value-type="string"><text:p>ok</text:p></table:table-cell>
+      <table:table-cell office:value-type="string"><text:p>at</text:p></table:table-cell>
+      <table:table-cell office:value-type="string"><text:p>date</text:p></table:table-cell>
+      <table:table-cell table:number-columns-repeated="1019"/>
+    </table:table-row>
+  </table:table-header-rows>
+  <table:table-row table:number-rows-repeated="2">
+    <table:table-cell office:value-type="string"><office:annotation><text:p>comment</text:p></office:annotation><text:p>foo<text:s text:c="2"/>bar</text:p><text:p>baz</text:p></table:table-cell>
+    <table:table-cell office:value-type="float" office:value="1"><text:p>1.00</text:p></table:table-cell>
+    <table:table-cell office:value-type="boolean" office:boolean-value="true"><text:p>TRUE</text:p></table:table-cell>
+    <table:table-cell office:value-type="time" office:time-value="PT13H30M00.5S"><text:p>13:30</text:p></table:table-cell>
+    <table:table-cell office:value-type="float" office:value="25570"><text:p>1970-01-02</text:p></table:table-cell>
+  </table:table-row>
+  <table:table-row>
+    <table:table-cell table:number-columns-repeated="2"/>
+    <table:table-cell office:value-type="boolean" office:boolean-value="false"><text:p>FALSE</text:p></table:table-cell>
+    <table:table-cell table:number-columns-repeated="1"/>
+    <table:table-cell office:value-type="date" office:date-value="1970-01-03"><text:p>01/03/70</text:p></table:table-cell>
+  </table:table-row>
+  <table:table-row table:number-rows-repeated="1048570"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+</table:table>`)
+
+	s := schema.NewIntermediateSchema(
+		arrow.NewSchema(
+			[]arrow.Field{
+				{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+				{Name: "count", Type: arrow.PrimitiveTypes.Uint32, Nullable: true},
+				{Name: "ok", Type: arrow.FixedWidthTypes.Boolean},
+				{Name: "at", Type: arrow.FixedWidthTypes.Time32ms, Nullable: true},
+				{Name: "date", Type: arrow.FixedWidthTypes.Date32},
+			}, nil),
+		"sheet")
+
+	cases := []struct {
+		sheet    string
+		expected []map[string]interface{}
+		isErr    bool
+	}{
+		{
+			sheet: "data",
+			expected: []map[string]interface{}{
+				{"name": "foo  bar\nbaz", "count": int64(1), "ok": true, "at": int64(48600500), "date": int64(1)},
+				{"name": "foo  bar\nbaz", "count": int64(1), "ok": true, "at": int64(48600500), "date": int64(1)},
+				{"name": nil, "count": nil, "ok": false, "at": nil, "date": int64(2)},
+			},
+			isErr: false,
+		},
+		{
+			sheet:    "0",
+			expected: []map[string]interface{}{},
+			isErr:    false,
+		},
+	}
+
+	for _, c := range cases {
+		d, err := newOdsInnerDecoder(bytes.NewReader(content), s, c.sheet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := make([]map[string]interface{}, 0)
+		for {
+			var v map[string]interface{}
+			err = d.Decode(&v)
+			if err != nil {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		if (err != nil && err != io.EOF) != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestNewOdsInnerDecoder(t *testing.T) {
+	s := schema.NewIntermediateSchema(arrow.NewSchema([]arrow.Field{}, nil), "empty")
+
+	for _, input := range [][]byte{
+		[]byte("not a zip"),
+		[]byte("PK\x05\x06\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"), // empty zip
+		newOdsContent(t, ``),
+	} {
+		if _, err := newOdsInnerDecoder(bytes.NewReader(input), s, ""); err == nil {
+			t.Errorf("expected error occurs for %v, but actual it's nil", input)
+		}
+	}
+}
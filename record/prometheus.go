@@ -0,0 +1,260 @@
+package record
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reproio/columnify/schema"
+)
+
+const (
+	// PrometheusNameKey is the column of metric names for prometheus record type.
+	PrometheusNameKey = "name"
+	// PrometheusLabelsKey is the column of labels if it's a map in the schema, otherwise labels are mapped to columns by their names.
+	PrometheusLabelsKey = "labels"
+	// PrometheusValueKey is the column of sample values for prometheus record type.
+	PrometheusValueKey = "value"
+	// PrometheusTimestampKey is the column of sample timestamps in milliseconds for prometheus record type.
+	PrometheusTimestampKey = "timestamp"
+	// PrometheusTypeKey is the column of metric types given by TYPE comments, e.g. counter
+	PrometheusTypeKey = "type"
+	// PrometheusHelpKey is the column of metric descriptions given by HELP comments.
+	PrometheusHelpKey = "help"
+)
+
+// prometheusReservedKeys are columns of samples, which labels can't be mapped to.
+var prometheusReservedKeys = []string{PrometheusNameKey, PrometheusValueKey, PrometheusTimestampKey, PrometheusTypeKey, PrometheusHelpKey}
+
+// prometheusSuffixes are appended to the metric name of histograms, summaries and counters for their samples.
+var prometheusSuffixes = []string{"_bucket", "_sum", "_count", "_total", "_created"}
+
+// prometheusInnerDecoder decodes Prometheus text exposition format, e.g.
+// http_requests_total{method="post",code="200"} 1027 1395066363000
+type prometheusInnerDecoder struct {
+	s           *bufio.Scanner
+	schema      *schema.IntermediateSchema
+	labelsAsMap bool
+	types       map[string]string
+	helps       map[string]string
+	line        int
+}
+
+func newPrometheusInnerDecoder(r io.Reader, s *schema.IntermediateSchema) *prometheusInnerDecoder {
+	return &prometheusInnerDecoder{
+		s:           bufio.NewScanner(r),
+		schema:      s,
+		labelsAsMap: isMapField(s, PrometheusLabelsKey),
+		types:       make(map[string]string),
+		helps:       make(map[string]string),
+	}
+}
+
+func (d *prometheusInnerDecoder) Decode(r *map[string]interface{}) error {
+	for d.s.Scan() {
+		d.line++
+
+		line := strings.TrimSpace(d.s.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			d.parseComment(line)
+			continue
+		}
+
+		record, err := d.parseSample(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", d.line, err)
+		}
+		*r = record
+
+		return nil
+	}
+
+	if err := d.s.Err(); err != nil {
+		return err
+	}
+
+	return io.EOF
+}
+
+// parseComment keeps metric types and descriptions. Other comments are ignored.
+func (d *prometheusInnerDecoder) parseComment(line string) {
+	tokens := strings.SplitN(strings.TrimSpace(strings.TrimPrefix(line, "#")), " ", 3)
+	if len(tokens) < 3 {
+		return
+	}
+
+	switch tokens[0] {
+	case "TYPE":
+		d.types[tokens[1]] = strings.TrimSpace(tokens[2])
+	case "HELP":
+		d.helps[tokens[1]] = unescapePrometheus(tokens[2])
+	}
+}
+
+func (d *prometheusInnerDecoder) parseSample(line string) (map[string]interface{}, error) {
+	name, labels, rest, err := parsePrometheusSeries(line)
+	if err != nil {
+		return nil, err
+	}
+
+	record := make(map[string]interface{})
+	if d.labelsAsMap {
+		m := make(map[string]interface{}, len(labels))
+		for k, v := range labels {
+			m[k] = v
+		}
+		record[PrometheusLabelsKey] = m
+	} else {
+		for _, k := range prometheusReservedKeys {
+			if _, ok := labels[k]; ok {
+				return nil, fmt.Errorf("label %s collides with the column of the sample, which requires %s column of a map: %w", k, PrometheusLabelsKey, ErrUnconvertibleRecord)
+			}
+		}
+
+		typed, err := coerceRecord(labels, d.schema)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range typed {
+			record[k] = v
+		}
+	}
+
+	record[PrometheusNameKey] = name
+	record[PrometheusTypeKey] = nil
+	record[PrometheusHelpKey] = nil
+	if family, ok := d.family(name); ok {
+		record[PrometheusTypeKey] = d.types[family]
+		if help, ok := d.helps[family]; ok {
+			record[PrometheusHelpKey] = help
+		}
+	}
+
+	tokens := strings.Fields(rest)
+	if len(tokens) < 1 || len(tokens) > 2 {
+		return nil, fmt.Errorf("invalid sample %s: %w", line, ErrUnconvertibleRecord)
+	}
+
+	value, err := strconv.ParseFloat(tokens[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %s: %w", tokens[0], ErrUnconvertibleRecord)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		// JSON doesn't have representations of them, but they can be parsed as parquet double values
+		record[PrometheusValueKey] = fmt.Sprint(value)
+	} else {
+		record[PrometheusValueKey] = value
+	}
+
+	record[PrometheusTimestampKey] = nil
+	if len(tokens) == 2 {
+		ts, err := strconv.ParseInt(tokens[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %s: %w", tokens[1], ErrUnconvertibleRecord)
+		}
+
+		if t, ok := schemaFieldType(d.schema, PrometheusTimestampKey); ok {
+			if record[PrometheusTimestampKey], err = coerceEpoch(ts, time.Millisecond, t); err != nil {
+				return nil, err
+			}
+		} else {
+			record[PrometheusTimestampKey] = ts
+		}
+	}
+
+	return record, nil
+}
+
+// family returns the metric name having TYPE comment for the sample name, e.g. foo for foo_bucket
+func (d *prometheusInnerDecoder) family(name string) (string, bool) {
+	if _, ok := d.types[name]; ok {
+		return name, true
+	}
+
+	for _, s := range prometheusSuffixes {
+		if base := strings.TrimSuffix(name, s); base != name {
+			if _, ok := d.types[base]; ok {
+				return base, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// parsePrometheusSeries parses the metric name and labels, and returns the rest of the line.
+func parsePrometheusSeries(line string) (string, map[string]string, string, error) {
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return "", nil, "", fmt.Errorf("invalid sample %s: %w", line, ErrUnconvertibleRecord)
+	}
+	name := line[:end]
+	rest := line[end:]
+
+	labels := make(map[string]string)
+	if !strings.HasPrefix(rest, "{") {
+		return name, labels, rest, nil
+	}
+
+	i := 1
+	for {
+		for i < len(rest) && (rest[i] == ' ' || rest[i] == ',') {
+			i++
+		}
+		if i >= len(rest) {
+			return "", nil, "", fmt.Errorf("unclosed labels %s: %w", line, ErrUnconvertibleRecord)
+		}
+		if rest[i] == '}' {
+			return name, labels, rest[i+1:], nil
+		}
+
+		eq := strings.IndexByte(rest[i:], '=')
+		if eq < 0 || i+eq+1 >= len(rest) || rest[i+eq+1] != '"' {
+			return "", nil, "", fmt.Errorf("invalid labels %s: %w", line, ErrUnconvertibleRecord)
+		}
+		key := strings.TrimSpace(rest[i : i+eq])
+
+		// find the closing quote of the value
+		start := i + eq + 2
+		j := start
+		for ; j < len(rest) && rest[j] != '"'; j++ {
+			if rest[j] == '\\' {
+				j++
+			}
+		}
+		if j >= len(rest) {
+			return "", nil, "", fmt.Errorf("unclosed label value %s: %w", line, ErrUnconvertibleRecord)
+		}
+
+		labels[key] = unescapePrometheus(rest[start:j])
+		i = j + 1
+	}
+}
+
+// unescapePrometheus converts \\, \" and \n escape sequences in label values and descriptions.
+func unescapePrometheus(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
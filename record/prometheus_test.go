@@ -0,0 +1,178 @@
+package record
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+func TestPrometheusInnerDecoder_Decode(t *testing.T) {
+	cases := []struct {
+		schema   *schema.IntermediateSchema
+		input    []byte
+		expected []map[string]interface{}
+		isErr    bool
+	}{
+		// Labels as columns with TYPE and HELP comments
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "name", Type: arrow.BinaryTypes.String},
+						{Name: "code", Type: arrow.PrimitiveTypes.Uint32, Nullable: true},
+						{Name: "value", Type: arrow.PrimitiveTypes.Float64},
+						{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_ms, Nullable: true},
+					}, nil),
+				"prometheus"),
+			input: []byte(`# HELP http_requests_total The total number\nof requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200",path="C:\\dir\"x\""} 1027 1395066363000
+
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5",} NaN
+rpc_duration_seconds_count 2693
+untyped +Inf
+`),
+			expected: []map[string]interface{}{
+				{
+					"name":      "http_requests_total",
+					"type":      "counter",
+					"help":      "The total number\nof requests.",
+					"method":    "post",
+					"code":      int64(200),
+					"path":      `C:\dir"x"`,
+					"value":     float64(1027),
+					"timestamp": int64(1395066363000),
+				},
+				{
+					"name":      "rpc_duration_seconds",
+					"type":      "summary",
+					"help":      nil,
+					"quantile":  "0.5",
+					"value":     "NaN",
+					"timestamp": nil,
+				},
+				{
+					"name":      "rpc_duration_seconds_count",
+					"type":      "summary",
+					"help":      nil,
+					"value":     float64(2693),
+					"timestamp": nil,
+				},
+				{
+					"name":      "untyped",
+					"type":      nil,
+					"help":      nil,
+					"value":     "+Inf",
+					"timestamp": nil,
+				},
+			},
+			isErr: false,
+		},
+
+		// Labels as a map column
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "name", Type: arrow.BinaryTypes.String},
+						{Name: "labels", Type: schema.MapOf(arrow.BinaryTypes.String)},
+						{Name: "value", Type: arrow.PrimitiveTypes.Float64},
+					}, nil),
+				"prometheus"),
+			input: []byte(`up{job="node", instance="localhost:9100"} 1 1395066363000`),
+			expected: []map[string]interface{}{
+				{
+					"name":      "up",
+					"type":      nil,
+					"help":      nil,
+					"labels":    map[string]interface{}{"job": "node", "instance": "localhost:9100"},
+					"value":     float64(1),
+					"timestamp": int64(1395066363000),
+				},
+			},
+			isErr: false,
+		},
+
+		// Labels colliding with columns of samples
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema([]arrow.Field{}, nil),
+				"prometheus"),
+			input:    []byte(`up{type="blackbox"} 1`),
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Labels of the same names in a map column
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "labels", Type: schema.MapOf(arrow.BinaryTypes.String)},
+					}, nil),
+				"prometheus"),
+			input: []byte(`up{name="node",help="x"} 1`),
+			expected: []map[string]interface{}{
+				{
+					"name":      "up",
+					"type":      nil,
+					"help":      nil,
+					"labels":    map[string]interface{}{"name": "node", "help": "x"},
+					"value":     float64(1),
+					"timestamp": nil,
+				},
+			},
+			isErr: false,
+		},
+
+		// Unclosed labels
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema([]arrow.Field{}, nil),
+				"prometheus"),
+			input:    []byte(`up{job="node" 1`),
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Invalid value
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema([]arrow.Field{}, nil),
+				"prometheus"),
+			input:    []byte(`up one`),
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		buf := bytes.NewReader(c.input)
+		d := newPrometheusInnerDecoder(buf, c.schema)
+
+		actual := make([]map[string]interface{}, 0)
+		var err error
+		for {
+			var v map[string]interface{}
+			err = d.Decode(&v)
+			if err != nil {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		if (err != nil && err != io.EOF) != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
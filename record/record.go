@@ -5,17 +5,32 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/reproio/columnify/schema"
 )
 
 const (
-	RecordTypeAvro    = "avro"
-	RecordTypeCsv     = "csv"
-	RecordTypeJsonl   = "jsonl"
-	RecordTypeLtsv    = "ltsv"
-	RecordTypeMsgpack = "msgpack"
-	RecordTypeTsv     = "tsv"
+	RecordTypeAvro             = "avro"
+	RecordTypeAvroSingleObject = "avro_single_object"
+	RecordTypeAvroConfluent    = "avro_confluent"
+	RecordTypeBson             = "bson"
+	RecordTypeCbor             = "cbor"
+	RecordTypeCsv              = "csv"
+	RecordTypeFixedWidth       = "fixedwidth"
+	RecordTypeInflux           = "influx"
+	RecordTypeIon              = "ion"
+	RecordTypeJson             = "json"
+	RecordTypeJsonl            = "jsonl"
+	RecordTypeLtsv             = "ltsv"
+	RecordTypeMsgpack          = "msgpack"
+	RecordTypeOds              = "ods"
+	RecordTypePrometheus       = "prometheus"
+	RecordTypeRegex            = "regex"
+	RecordTypeSqlite           = "sqlite"
+	RecordTypeTsv              = "tsv"
+	RecordTypeXlsx             = "xlsx"
+	RecordTypeXml              = "xml"
 )
 
 var (
@@ -23,6 +38,49 @@ var (
 	ErrUnconvertibleRecord = errors.New("input record is unable to convert")
 )
 
+// Config holds record type specific options.
+type Config struct {
+	// JsonPointer points the array of records in a json record, e.g. "/Records".
+	JsonPointer string
+
+	// AvroSchemaDir is a directory of .avsc files to resolve writer schemas of framed Avro records.
+	AvroSchemaDir string
+
+	// RegexPattern is a builtin pattern name, or a regular expression with grok references like %{INT:status}.
+	RegexPattern string
+	// RegexPatternsFile has additional grok pattern definitions like "NAME pattern" per line.
+	RegexPatternsFile string
+	// Now is the clock to complete timestamps without years like RFC3164 syslog ones for regex record type.
+	// time.Now is used if it's nil.
+	Now func() time.Time
+
+	// FixedWidthSpecFile is a JSON file of column specs for fixedwidth record type.
+	// Schema field properties are used if it's not given.
+	FixedWidthSpecFile string
+
+	// XmlRecordPath is the absolute path of record elements for xml record type, e.g. /feed/entry
+	XmlRecordPath string
+
+	// Sheet is the name or 0-origin index of the sheet for xlsx and ods record types. The first sheet is used by default.
+	Sheet string
+
+	// SqliteTable is the table to read for sqlite record type. It can be omitted if the database has only one table.
+	SqliteTable string
+
+	// SqliteQuery is the query to read records for sqlite record type, used instead of SqliteTable.
+	SqliteQuery string
+
+	// InfluxPrecision is the unit of timestamps for influx record type, [ns|us|ms|s]. The default is ns.
+	InfluxPrecision string
+
+	// FillDefaults sets default values of schema fields to fields missing in records.
+	FillDefaults bool
+
+	// BinaryEncodings are encodings of string values of binary columns keyed by field paths like "nested.payload",
+	// [raw|base64|hex]. The default is raw UTF-8 bytes. Raw bytes of binary formats like Avro are kept as they are.
+	BinaryEncodings map[string]string
+}
+
 // innerDecoder decodes data from given Reader to the intermediate representation.
 type innerDecoder interface {
 	// Decode reads input data via Reader and extract it to the argument.
@@ -32,20 +90,47 @@ type innerDecoder interface {
 
 // jsonStringConverter converts data with innerDecoder and returns JSON string value.
 type jsonStringConverter struct {
-	inner innerDecoder
+	inner           innerDecoder
+	schema          *schema.IntermediateSchema
+	fillDefaults    bool
+	binaryEncodings binaryEncodings
 }
 
-func NewJsonStringConverter(r io.Reader, s *schema.IntermediateSchema, recordType string) (*jsonStringConverter, error) {
+func NewJsonStringConverter(r io.Reader, s *schema.IntermediateSchema, recordType string, config Config) (*jsonStringConverter, error) {
 	var inner innerDecoder
 	var err error
 
 	switch recordType {
 	case RecordTypeAvro:
-		inner, err = newAvroInnerDecoder(r)
+		inner, err = newAvroInnerDecoder(r, s)
+
+	case RecordTypeAvroSingleObject:
+		inner, err = newAvroFramedInnerDecoder(r, s, avroFramingSingleObject, config.AvroSchemaDir)
+
+	case RecordTypeAvroConfluent:
+		inner, err = newAvroFramedInnerDecoder(r, s, avroFramingConfluent, config.AvroSchemaDir)
+
+	case RecordTypeBson:
+		inner = newBsonInnerDecoder(r, s)
+
+	case RecordTypeCbor:
+		inner, err = newCborInnerDecoder(r, s)
 
 	case RecordTypeCsv:
 		inner, err = newCsvInnerDecoder(r, s, CsvDelimiter)
 
+	case RecordTypeFixedWidth:
+		inner, err = newFixedWidthInnerDecoder(r, s, config.FixedWidthSpecFile)
+
+	case RecordTypeInflux:
+		inner, err = newInfluxInnerDecoder(r, s, config.InfluxPrecision)
+
+	case RecordTypeIon:
+		inner = newIonInnerDecoder(r, s)
+
+	case RecordTypeJson:
+		inner, err = newJsonInnerDecoder(r, config.JsonPointer)
+
 	case RecordTypeJsonl:
 		inner = newJsonlInnerDecoder(r)
 
@@ -55,16 +140,45 @@ func NewJsonStringConverter(r io.Reader, s *schema.IntermediateSchema, recordTyp
 	case RecordTypeMsgpack:
 		inner = newMsgpackInnerDecoder(r)
 
+	case RecordTypeOds:
+		inner, err = newOdsInnerDecoder(r, s, config.Sheet)
+
+	case RecordTypePrometheus:
+		inner = newPrometheusInnerDecoder(r, s)
+
+	case RecordTypeRegex:
+		inner, err = newRegexInnerDecoder(r, s, config.RegexPattern, config.RegexPatternsFile, config.Now)
+
+	case RecordTypeSqlite:
+		inner, err = newSqliteInnerDecoder(r, s, config.SqliteTable, config.SqliteQuery)
+
 	case RecordTypeTsv:
 		inner, err = newCsvInnerDecoder(r, s, TsvDelimiter)
 
+	case RecordTypeXlsx:
+		inner, err = newXlsxInnerDecoder(r, s, config.Sheet)
+
+	case RecordTypeXml:
+		inner, err = newXmlInnerDecoder(r, s, config.XmlRecordPath)
+
 	default:
 		return nil, fmt.Errorf("unsupported record type %s: %w", recordType, ErrUnsupportedRecord)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	encodings, err := newBinaryEncodings(config.BinaryEncodings, s)
+	if err != nil {
+		return nil, err
+	}
 
 	return &jsonStringConverter{
-		inner: inner,
-	}, err
+		inner:           inner,
+		schema:          s,
+		fillDefaults:    config.FillDefaults,
+		binaryEncodings: encodings,
+	}, nil
 }
 
 func (d *jsonStringConverter) Convert(v *string) error {
@@ -75,6 +189,22 @@ func (d *jsonStringConverter) Convert(v *string) error {
 		return err
 	}
 
+	if d.schema != nil {
+		if vv != nil {
+			if err := convertBinaryRecord(vv, d.schema, d.binaryEncodings.decode); err != nil {
+				return err
+			}
+		}
+		if d.fillDefaults && vv != nil {
+			if err := fillDefaultRecord(vv, d.schema); err != nil {
+				return err
+			}
+		}
+		if vv, err = coerceTextRecord(vv, d.schema); err != nil {
+			return err
+		}
+	}
+
 	data, err := json.Marshal(vv)
 	if err != nil {
 		return err
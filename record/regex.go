@@ -0,0 +1,79 @@
+package record
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/reproio/columnify/schema"
+)
+
+// regexInnerDecoder decodes each line by a regular expression, named capture groups become fields.
+type regexInnerDecoder struct {
+	s      *bufio.Scanner
+	re     *regexp.Regexp
+	schema *schema.IntermediateSchema
+	now    func() time.Time
+	line   int
+}
+
+// newRegexInnerDecoder creates a decoder, which completes timestamps without years by the clock, time.Now if it's nil.
+func newRegexInnerDecoder(r io.Reader, s *schema.IntermediateSchema, pattern string, patternsFile string, now func() time.Time) (*regexInnerDecoder, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("regex pattern is required: %w", ErrUnconvertibleRecord)
+	}
+
+	re, err := compileGrok(pattern, patternsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if now == nil {
+		now = time.Now
+	}
+
+	return &regexInnerDecoder{
+		s:      bufio.NewScanner(r),
+		re:     re,
+		schema: s,
+		now:    now,
+	}, nil
+}
+
+func (d *regexInnerDecoder) Decode(r *map[string]interface{}) error {
+	if d.s.Scan() {
+		d.line++
+
+		m := d.re.FindStringSubmatch(d.s.Text())
+		if m == nil {
+			return fmt.Errorf("line %d doesn't match the pattern: %w", d.line, ErrUnconvertibleRecord)
+		}
+
+		values := make(map[string]string)
+		for i, name := range d.re.SubexpNames() {
+			if name == "" {
+				continue
+			}
+			// The same name might appear in alternatives, so prefer the matched one
+			if v, ok := values[name]; ok && v != "" {
+				continue
+			}
+			values[name] = m[i]
+		}
+
+		record, err := coerceRecordWithClock(values, d.schema, d.now)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", d.line, err)
+		}
+		*r = record
+	} else {
+		if err := d.s.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+
+	return d.s.Err()
+}
@@ -0,0 +1,154 @@
+package record
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+func TestRegexInnerDecoder_Decode(t *testing.T) {
+	cases := []struct {
+		schema   *schema.IntermediateSchema
+		input    []byte
+		pattern  string
+		expected []map[string]interface{}
+		isErr    bool
+	}{
+		// Custom pattern with grok references
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "level", Type: arrow.BinaryTypes.String},
+						{Name: "code", Type: arrow.PrimitiveTypes.Uint32},
+						{Name: "elapsed", Type: arrow.PrimitiveTypes.Float64},
+						{Name: "ok", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+					}, nil),
+				"custom"),
+			input: []byte(`INFO code=200 elapsed=0.5 ok=true
+WARN code=503 elapsed=1.25`),
+			pattern: `^%{WORD:level} code=%{INT:code} elapsed=%{NUMBER:elapsed}(?: ok=(?P<ok>\w+))?$`,
+			expected: []map[string]interface{}{
+				{"level": "INFO", "code": int64(200), "elapsed": float64(0.5), "ok": true},
+				{"level": "WARN", "code": int64(503), "elapsed": float64(1.25), "ok": nil},
+			},
+			isErr: false,
+		},
+
+		// RFC5424 syslog
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "priority", Type: arrow.PrimitiveTypes.Uint32},
+						{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_ms},
+					}, nil),
+				"syslog"),
+			input:   []byte(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3"] An application event`),
+			pattern: RegexPatternSyslogRfc5424,
+			expected: []map[string]interface{}{
+				{
+					"priority":        int64(165),
+					"version":         "1",
+					"timestamp":       int64(1065910455003),
+					"hostname":        "mymachine.example.com",
+					"app_name":        "evntslog",
+					"proc_id":         nil,
+					"msg_id":          "ID47",
+					"structured_data": `[exampleSDID@32473 iut="3"]`,
+					"message":         "An application event",
+				},
+			},
+			isErr: false,
+		},
+
+		// RFC3164 syslog without years, in the year of the clock
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_ms},
+					}, nil),
+				"syslog"),
+			input:   []byte(`<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed`),
+			pattern: RegexPatternSyslogRfc3164,
+			expected: []map[string]interface{}{
+				{
+					"priority":  "34",
+					"timestamp": time.Date(2020, 10, 11, 22, 14, 15, 0, time.UTC).UnixNano() / int64(time.Millisecond),
+					"hostname":  "mymachine",
+					"program":   "su",
+					"pid":       "123",
+					"message":   "'su root' failed",
+				},
+			},
+			isErr: false,
+		},
+
+		// Not matched
+		{
+			schema:   schema.NewIntermediateSchema(arrow.NewSchema([]arrow.Field{}, nil), "empty"),
+			input:    []byte(`not a log`),
+			pattern:  RegexPatternApacheCombined,
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Unconvertible value
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "code", Type: arrow.PrimitiveTypes.Uint32},
+					}, nil),
+				"custom"),
+			input:    []byte(`code=abc`),
+			pattern:  `code=(?P<code>\w+)`,
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+	}
+
+	now := func() time.Time { return time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC) }
+	for _, c := range cases {
+		buf := bytes.NewReader(c.input)
+		d, err := newRegexInnerDecoder(buf, c.schema, c.pattern, "", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := make([]map[string]interface{}, 0)
+		for {
+			var v map[string]interface{}
+			err = d.Decode(&v)
+			if err != nil {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		if (err != nil && err != io.EOF) != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestNewRegexInnerDecoder(t *testing.T) {
+	s := schema.NewIntermediateSchema(arrow.NewSchema([]arrow.Field{}, nil), "empty")
+
+	for _, p := range []string{"", "%{UNDEFINED:field}", "(unclosed"} {
+		if _, err := newRegexInnerDecoder(bytes.NewReader(nil), s, p, "", nil); err == nil {
+			t.Errorf("expected error occurs for %s, but actual it's nil", p)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package record
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+// sqliteInnerDecoder reads rows of the table or the query result one by one.
+type sqliteInnerDecoder struct {
+	db      *sql.DB
+	rows    *sql.Rows
+	columns []string
+	schema  *schema.IntermediateSchema
+
+	// tmp is the path of the database copied from a non-file input, removed at the end
+	tmp string
+}
+
+func newSqliteInnerDecoder(r io.Reader, s *schema.IntermediateSchema, table string, query string) (*sqliteInnerDecoder, error) {
+	path, tmp, err := sqliteDatabasePath(r)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &sqliteInnerDecoder{
+		schema: s,
+		tmp:    tmp,
+	}
+	if err := d.open(path, table, query); err != nil {
+		d.close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// sqliteDatabasePath returns the path of the input file, or copies the input to a temporary file
+// because SQLite databases can't be read as streams.
+func sqliteDatabasePath(r io.Reader) (string, string, error) {
+	if f, ok := r.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+			return f.Name(), "", nil
+		}
+	}
+
+	f, err := os.CreateTemp("", "columnify-*.sqlite")
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		os.Remove(f.Name())
+		return "", "", err
+	}
+
+	return f.Name(), f.Name(), nil
+}
+
+func (d *sqliteInnerDecoder) open(path string, table string, query string) error {
+	var err error
+	if d.db, err = schema.OpenSqlite(path); err != nil {
+		return err
+	}
+
+	q, _, err := schema.SqliteSelectQuery(d.db, table, query)
+	if err != nil {
+		return err
+	}
+
+	if d.rows, err = d.db.Query(q); err != nil {
+		return err
+	}
+	d.columns, err = d.rows.Columns()
+
+	return err
+}
+
+func (d *sqliteInnerDecoder) close() error {
+	var err error
+	if d.rows != nil {
+		err = d.rows.Close()
+	}
+	if d.db != nil {
+		if cerr := d.db.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if d.tmp != "" {
+		if rerr := os.Remove(d.tmp); err == nil {
+			err = rerr
+		}
+		d.tmp = ""
+	}
+
+	return err
+}
+
+func (d *sqliteInnerDecoder) Decode(r *map[string]interface{}) error {
+	if !d.rows.Next() {
+		if err := d.rows.Err(); err != nil {
+			return err
+		}
+		if err := d.close(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+
+	values := make([]interface{}, len(d.columns))
+	ptrs := make([]interface{}, len(d.columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := d.rows.Scan(ptrs...); err != nil {
+		return err
+	}
+
+	record := make(map[string]interface{}, len(d.columns))
+	for i, c := range d.columns {
+		fields := d.schema.ArrowSchema.FieldIndices(c)
+		if len(fields) == 0 {
+			record[c] = values[i]
+			continue
+		}
+
+		v, err := coerceSqliteValue(values[i], d.schema.ArrowSchema.Field(fields[0]).Type)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", c, err)
+		}
+		record[c] = v
+	}
+	*r = record
+
+	return nil
+}
+
+// coerceSqliteValue converts a value stored with dynamic typing to the representation of given arrow type.
+func coerceSqliteValue(v interface{}, t arrow.DataType) (interface{}, error) {
+	switch vv := v.(type) {
+	case time.Time:
+		return coerceTime(vv, t)
+
+	case []byte:
+		if t.ID() == arrow.BINARY {
+			return vv, nil
+		}
+		return coerceSqliteValue(string(vv), t)
+
+	case string:
+		if t.ID() == arrow.STRING || t.ID() == arrow.BINARY {
+			return vv, nil
+		}
+		if vv == "" {
+			return nil, nil
+		}
+		return coerceString(vv, t)
+
+	case int64:
+		switch t.ID() {
+		case arrow.BOOL:
+			return vv != 0, nil
+		case arrow.STRING:
+			return fmt.Sprint(vv), nil
+		}
+
+	case float64:
+		switch t.ID() {
+		case arrow.BOOL:
+			return vv != 0, nil
+		case arrow.STRING:
+			return fmt.Sprint(vv), nil
+		}
+	}
+
+	return v, nil
+}
@@ -0,0 +1,144 @@
+package record
+
+import (
+	"bytes"
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+func TestSqliteInnerDecoder_Decode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sqlite")
+	db, err := sql.Open(schema.SqliteDriverName, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`CREATE TABLE events (id INTEGER, name TEXT, payload BLOB, ok BOOLEAN, day DATE, at DATETIME, code TEXT)`,
+		`INSERT INTO events VALUES (1, 'foo', X'0001', 1, '1970-01-02', '1970-01-01 00:00:01', '10')`,
+		`INSERT INTO events VALUES (2, NULL, NULL, 0, NULL, 2000, '')`,
+		`CREATE TABLE others (id INTEGER)`,
+	} {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := schema.NewIntermediateSchema(
+		arrow.NewSchema(
+			[]arrow.Field{
+				{Name: "id", Type: arrow.PrimitiveTypes.Uint32},
+				{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+				{Name: "payload", Type: arrow.BinaryTypes.Binary, Nullable: true},
+				{Name: "ok", Type: arrow.FixedWidthTypes.Boolean},
+				{Name: "day", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+				{Name: "at", Type: arrow.FixedWidthTypes.Timestamp_ms},
+				{Name: "code", Type: arrow.PrimitiveTypes.Uint32, Nullable: true},
+			}, nil),
+		"events")
+
+	expected := []map[string]interface{}{
+		{"id": int64(1), "name": "foo", "payload": []byte{0, 1}, "ok": true, "day": int64(1), "at": int64(1000), "code": int64(10)},
+		{"id": int64(2), "name": nil, "payload": nil, "ok": false, "day": nil, "at": int64(2000), "code": nil},
+	}
+
+	cases := []struct {
+		input    func() io.Reader
+		table    string
+		query    string
+		expected []map[string]interface{}
+		isErr    bool
+	}{
+		// Database file
+		{
+			input: func() io.Reader {
+				f, err := os.Open(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { f.Close() })
+				return f
+			},
+			table:    "events",
+			expected: expected,
+			isErr:    false,
+		},
+
+		// Database content from non-file stream
+		{
+			input:    func() io.Reader { return bytes.NewReader(content) },
+			table:    "events",
+			expected: expected,
+			isErr:    false,
+		},
+
+		// Query
+		{
+			input: func() io.Reader { return bytes.NewReader(content) },
+			query: "SELECT id, upper(name) AS name, 'extra' AS extra FROM events WHERE ok",
+			expected: []map[string]interface{}{
+				{"id": int64(1), "name": "FOO", "extra": "extra"},
+			},
+			isErr: false,
+		},
+	}
+
+	for _, c := range cases {
+		d, err := newSqliteInnerDecoder(c.input(), s, c.table, c.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := make([]map[string]interface{}, 0)
+		for {
+			var v map[string]interface{}
+			err = d.Decode(&v)
+			if err != nil {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		if (err != nil && err != io.EOF) != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestNewSqliteInnerDecoder(t *testing.T) {
+	s := schema.NewIntermediateSchema(arrow.NewSchema([]arrow.Field{}, nil), "empty")
+
+	cases := []struct {
+		input []byte
+		table string
+	}{
+		{input: []byte("not a database"), table: "events"},
+		{input: []byte{}, table: ""},
+		{input: []byte{}, table: "events"},
+	}
+
+	for _, c := range cases {
+		if _, err := newSqliteInnerDecoder(bytes.NewReader(c.input), s, c.table, ""); err == nil {
+			t.Errorf("expected error occurs for %v, but actual it's nil", c)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package record
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reproio/columnify/schema"
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	// excelEpoch is the day 0 of serial dates, with the compatibility of Lotus 1-2-3 leap year bug
+	excelEpoch     = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	excelEpoch1904 = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type xlsxInnerDecoder struct {
+	f        *excelize.File
+	rows     *excelize.Rows
+	schema   *schema.IntermediateSchema
+	header   []string
+	date1904 bool
+	row      int
+}
+
+func newXlsxInnerDecoder(r io.Reader, s *schema.IntermediateSchema, sheet string) (*xlsxInnerDecoder, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := selectSheet(f.GetSheetList(), sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	props, err := f.GetWorkbookProps()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := f.Rows(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxInnerDecoder{
+		f:        f,
+		rows:     rows,
+		schema:   s,
+		date1904: props.Date1904 != nil && *props.Date1904,
+	}, nil
+}
+
+// selectSheet finds the sheet by name first, and then by 0-origin index. The first sheet is used by default.
+func selectSheet(names []string, sheet string) (string, error) {
+	if len(names) == 0 {
+		return "", fmt.Errorf("no sheet: %w", ErrUnconvertibleRecord)
+	}
+
+	if sheet == "" {
+		return names[0], nil
+	}
+
+	for _, n := range names {
+		if n == sheet {
+			return n, nil
+		}
+	}
+
+	if i, err := strconv.Atoi(sheet); err == nil && i >= 0 && i < len(names) {
+		return names[i], nil
+	}
+
+	return "", fmt.Errorf("sheet %s is not found in %v: %w", sheet, names, ErrUnconvertibleRecord)
+}
+
+func (d *xlsxInnerDecoder) Decode(r *map[string]interface{}) error {
+	for d.rows.Next() {
+		d.row++
+
+		cells, err := d.rows.Columns(excelize.Options{RawCellValue: true})
+		if err != nil {
+			return err
+		}
+		if isBlankRow(cells) {
+			continue
+		}
+
+		if d.header == nil {
+			d.header = trimCells(cells)
+			continue
+		}
+
+		record, err := sheetRowToRecord(d.header, cells, d.schema, d.date1904)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", d.row, err)
+		}
+		*r = record
+
+		return nil
+	}
+
+	if err := d.rows.Error(); err != nil {
+		return err
+	}
+	if err := d.rows.Close(); err != nil {
+		return err
+	}
+	if err := d.f.Close(); err != nil {
+		return err
+	}
+
+	return io.EOF
+}
+
+func isBlankRow(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func trimCells(cells []string) []string {
+	trimmed := make([]string, len(cells))
+	for i, c := range cells {
+		trimmed[i] = strings.TrimSpace(c)
+	}
+
+	return trimmed
+}
+
+// sheetRowToRecord maps cells to schema fields by the header row, and converts them to the field types.
+// Numeric cells for temporal fields are regarded as serial dates.
+func sheetRowToRecord(header []string, cells []string, s *schema.IntermediateSchema, date1904 bool) (map[string]interface{}, error) {
+	record := make(map[string]interface{}, len(header))
+
+	for i, name := range header {
+		if name == "" {
+			continue
+		}
+
+		v := ""
+		if i < len(cells) {
+			v = cells[i]
+		}
+		if v == "" {
+			record[name] = nil
+			continue
+		}
+
+		fields := s.ArrowSchema.FieldIndices(name)
+		if len(fields) == 0 {
+			record[name] = v
+			continue
+		}
+		t := s.ArrowSchema.Field(fields[0]).Type
+
+		if isTemporalType(t) {
+			if serial, err := strconv.ParseFloat(v, 64); err == nil {
+				vv, err := coerceTime(excelSerialToTime(serial, date1904), t)
+				if err != nil {
+					return nil, fmt.Errorf("field %s: %w", name, err)
+				}
+				record[name] = vv
+				continue
+			}
+		}
+
+		vv, err := coerceString(v, t)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		record[name] = vv
+	}
+
+	return record, nil
+}
+
+// excelSerialToTime converts a serial date, the number of days with fraction of a day, rounded to milliseconds.
+func excelSerialToTime(serial float64, date1904 bool) time.Time {
+	epoch := excelEpoch
+	if date1904 {
+		epoch = excelEpoch1904
+	}
+
+	ms := math.Round(serial * 24 * 60 * 60 * 1000)
+
+	return epoch.Add(time.Duration(ms) * time.Millisecond)
+}
@@ -0,0 +1,151 @@
+package record
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestXlsxInnerDecoder_Decode(t *testing.T) {
+	f := excelize.NewFile()
+	if _, err := f.NewSheet("data"); err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]interface{}{
+		{},
+		{" name ", "extra", "count", "date", "updated"},
+		{"foo", "x", 1, 25570, 25569.5},
+		{},
+		{"bar", nil, nil, "1970-01-03", "1970-01-01T00:00:01Z"},
+	}
+	for i, r := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow("data", cell, &r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf := &bytes.Buffer{}
+	if err := f.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	s := schema.NewIntermediateSchema(
+		arrow.NewSchema(
+			[]arrow.Field{
+				{Name: "name", Type: arrow.BinaryTypes.String},
+				{Name: "count", Type: arrow.PrimitiveTypes.Uint32, Nullable: true},
+				{Name: "date", Type: arrow.FixedWidthTypes.Date32},
+				{Name: "updated", Type: arrow.FixedWidthTypes.Timestamp_ms},
+			}, nil),
+		"sheet")
+
+	cases := []struct {
+		sheet    string
+		expected []map[string]interface{}
+		isErr    bool
+	}{
+		{
+			sheet: "data",
+			expected: []map[string]interface{}{
+				{"name": "foo", "extra": "x", "count": int64(1), "date": int64(1), "updated": int64(43200000)},
+				{"name": "bar", "extra": nil, "count": nil, "date": int64(2), "updated": int64(1000)},
+			},
+			isErr: false,
+		},
+		{
+			sheet: "1",
+			expected: []map[string]interface{}{
+				{"name": "foo", "extra": "x", "count": int64(1), "date": int64(1), "updated": int64(43200000)},
+				{"name": "bar", "extra": nil, "count": nil, "date": int64(2), "updated": int64(1000)},
+			},
+			isErr: false,
+		},
+		{
+			sheet:    "",
+			expected: []map[string]interface{}{},
+			isErr:    false,
+		},
+	}
+
+	for _, c := range cases {
+		d, err := newXlsxInnerDecoder(bytes.NewReader(buf.Bytes()), s, c.sheet)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := make([]map[string]interface{}, 0)
+		for {
+			var v map[string]interface{}
+			err = d.Decode(&v)
+			if err != nil {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		if (err != nil && err != io.EOF) != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestSelectSheet(t *testing.T) {
+	names := []string{"first", "2", "third"}
+
+	cases := []struct {
+		sheet    string
+		expected string
+		isErr    bool
+	}{
+		{sheet: "", expected: "first", isErr: false},
+		{sheet: "third", expected: "third", isErr: false},
+		{sheet: "2", expected: "2", isErr: false},
+		{sheet: "0", expected: "first", isErr: false},
+		{sheet: "3", expected: "", isErr: true},
+		{sheet: "unknown", expected: "", isErr: true},
+	}
+
+	for _, c := range cases {
+		actual, err := selectSheet(names, c.sheet)
+
+		if err != nil != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if actual != c.expected {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestExcelSerialToTime(t *testing.T) {
+	cases := []struct {
+		serial   float64
+		date1904 bool
+		expected string
+	}{
+		{serial: 25569, date1904: false, expected: "1970-01-01T00:00:00Z"},
+		{serial: 44197.75, date1904: false, expected: "2021-01-01T18:00:00Z"},
+		{serial: 0.000011574, date1904: false, expected: "1899-12-30T00:00:01Z"},
+		{serial: 24107, date1904: true, expected: "1970-01-01T00:00:00Z"},
+	}
+
+	for _, c := range cases {
+		actual := excelSerialToTime(c.serial, c.date1904).Format("2006-01-02T15:04:05.999Z07:00")
+
+		if actual != c.expected {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
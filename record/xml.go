@@ -0,0 +1,251 @@
+package record
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+// XmlTextKey is the key for text contents of elements having attributes or child elements.
+const XmlTextKey = "_text"
+
+// xmlInnerDecoder decodes elements at the record path token by token,
+// so that it doesn't hold a whole document in memory.
+type xmlInnerDecoder struct {
+	d      *xml.Decoder
+	path   []string
+	schema *schema.IntermediateSchema
+
+	// current is the path of elements opened now
+	current []string
+}
+
+func newXmlInnerDecoder(r io.Reader, s *schema.IntermediateSchema, path string) (*xmlInnerDecoder, error) {
+	p, err := parseXmlPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xmlInnerDecoder{
+		d:      xml.NewDecoder(r),
+		path:   p,
+		schema: s,
+	}, nil
+}
+
+// parseXmlPath splits an absolute element path like "/feed/entry" to local names.
+func parseXmlPath(path string) ([]string, error) {
+	if !strings.HasPrefix(path, "/") || len(path) < 2 {
+		return nil, fmt.Errorf("invalid xml record path %s: %w", path, ErrUnconvertibleRecord)
+	}
+
+	names := strings.Split(path[1:], "/")
+	for _, n := range names {
+		if n == "" {
+			return nil, fmt.Errorf("invalid xml record path %s: %w", path, ErrUnconvertibleRecord)
+		}
+	}
+
+	return names, nil
+}
+
+func (d *xmlInnerDecoder) Decode(r *map[string]interface{}) error {
+	for {
+		tok, err := d.d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			d.current = append(d.current, t.Name.Local)
+			if !d.atRecordPath() {
+				continue
+			}
+
+			v, err := d.decodeElement(t)
+			d.current = d.current[:len(d.current)-1]
+			if err != nil {
+				return err
+			}
+
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				m = map[string]interface{}{XmlTextKey: v}
+			}
+			record, err := coerceXmlStruct(m, d.schema.ArrowSchema.Fields())
+			if err != nil {
+				return err
+			}
+			*r = record
+
+			return nil
+
+		case xml.EndElement:
+			if len(d.current) > 0 {
+				d.current = d.current[:len(d.current)-1]
+			}
+		}
+	}
+}
+
+func (d *xmlInnerDecoder) atRecordPath() bool {
+	if len(d.current) != len(d.path) {
+		return false
+	}
+
+	for i := range d.path {
+		if d.current[i] != d.path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// decodeElement consumes tokens until the end of the started element.
+// It returns a string for a simple element, or a map of attributes and child elements keyed by their local names.
+// Repeated child elements are collected to a list. It fails if attributes and child elements have the same local names,
+// or they're in different namespaces, because they can't be told apart in the map.
+func (d *xmlInnerDecoder) decodeElement(start xml.StartElement) (interface{}, error) {
+	m := make(map[string]interface{})
+	attrs := make(map[string]bool)
+	for _, a := range start.Attr {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+			continue
+		}
+		if attrs[a.Name.Local] {
+			return nil, fmt.Errorf("attributes %s of element %s collide in namespaces: %w", a.Name.Local, start.Name.Local, ErrUnconvertibleRecord)
+		}
+		attrs[a.Name.Local] = true
+		m[a.Name.Local] = a.Value
+	}
+	children := make(map[string]string) // namespaces of child elements keyed by local names
+
+	var text strings.Builder
+	for {
+		tok, err := d.d.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("unexpected end of element %s: %w", start.Name.Local, ErrUnconvertibleRecord)
+			}
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			v, err := d.decodeElement(t)
+			if err != nil {
+				return nil, err
+			}
+
+			name := t.Name.Local
+			if attrs[name] {
+				return nil, fmt.Errorf("attribute and child element %s of element %s collide: %w", name, start.Name.Local, ErrUnconvertibleRecord)
+			}
+			if space, ok := children[name]; ok && space != t.Name.Space {
+				return nil, fmt.Errorf("child elements %s of element %s collide in namespaces %s and %s: %w", name, start.Name.Local, space, t.Name.Space, ErrUnconvertibleRecord)
+			}
+			children[name] = t.Name.Space
+
+			switch prev := m[name].(type) {
+			case nil:
+				m[name] = v
+			case []interface{}:
+				m[name] = append(prev, v)
+			default:
+				m[name] = []interface{}{prev, v}
+			}
+
+		case xml.CharData:
+			text.Write(t)
+
+		case xml.EndElement:
+			s := strings.TrimSpace(text.String())
+			if len(m) == 0 {
+				return s, nil
+			}
+			if s != "" {
+				if _, ok := m[XmlTextKey]; ok {
+					return nil, fmt.Errorf("%s of element %s collides with its text: %w", XmlTextKey, start.Name.Local, ErrUnconvertibleRecord)
+				}
+				m[XmlTextKey] = s
+			}
+
+			return m, nil
+		}
+	}
+}
+
+// coerceXmlStruct converts element texts to typed values by the schema fields.
+// Single elements for list fields are wrapped, and empty texts are regarded as null.
+func coerceXmlStruct(m map[string]interface{}, fields []arrow.Field) (map[string]interface{}, error) {
+	for _, f := range fields {
+		v, ok := m[f.Name]
+		if !ok || v == nil {
+			continue
+		}
+
+		vv, err := coerceXml(v, f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		m[f.Name] = vv
+	}
+
+	for k, v := range m {
+		if s, ok := v.(string); ok && s == "" {
+			m[k] = nil
+		}
+	}
+
+	return m, nil
+}
+
+func coerceXml(v interface{}, t arrow.DataType) (interface{}, error) {
+	switch tt := t.(type) {
+	case *arrow.ListType:
+		l, ok := v.([]interface{})
+		if !ok {
+			l = []interface{}{v}
+		}
+		for i, e := range l {
+			ee, err := coerceXml(e, tt.Elem())
+			if err != nil {
+				return nil, err
+			}
+			l[i] = ee
+		}
+		return l, nil
+
+	case *arrow.StructType:
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			return coerceXmlStruct(vv, tt.Fields())
+		case string:
+			if vv == "" {
+				return nil, nil
+			}
+		}
+
+	default:
+		switch vv := v.(type) {
+		case string:
+			if vv == "" {
+				return nil, nil
+			}
+			return coerceString(vv, t)
+		case map[string]interface{}:
+			// e.g. <price currency="USD">1.5</price> for a primitive field
+			if text, ok := vv[XmlTextKey].(string); ok {
+				return coerceString(text, t)
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("unable to convert %v to %v: %w", v, t, ErrUnconvertibleRecord)
+}
@@ -0,0 +1,190 @@
+package record
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/reproio/columnify/schema"
+)
+
+func TestXmlInnerDecoder_Decode(t *testing.T) {
+	cases := []struct {
+		schema   *schema.IntermediateSchema
+		input    []byte
+		path     string
+		expected []map[string]interface{}
+		isErr    bool
+	}{
+		// Attributes, nested and repeated elements
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "id", Type: arrow.PrimitiveTypes.Uint32},
+						{Name: "title", Type: arrow.BinaryTypes.String},
+						{Name: "author", Type: arrow.StructOf(
+							arrow.Field{Name: "name", Type: arrow.BinaryTypes.String},
+							arrow.Field{Name: "age", Type: arrow.PrimitiveTypes.Uint32, Nullable: true},
+						)},
+						{Name: "tag", Type: arrow.ListOf(arrow.BinaryTypes.String)},
+						{Name: "price", Type: arrow.PrimitiveTypes.Float64},
+						{Name: "updated", Type: arrow.FixedWidthTypes.Timestamp_ms, Nullable: true},
+					}, nil),
+				"entry"),
+			input: []byte(`<?xml version="1.0"?>
+<feed>
+  <title>feed title</title>
+  <entry id="1">
+    <title>foo</title>
+    <author><name>alice</name><age>20</age></author>
+    <tag>a</tag>
+    <tag>b</tag>
+    <price currency="USD">1.5</price>
+    <updated>1970-01-01T00:00:01Z</updated>
+  </entry>
+  <other><entry id="0"/></other>
+  <entry id="2">
+    <title><![CDATA[<bar>]]></title>
+    <author><name>bob</name><age/></author>
+    <tag>c</tag>
+    <price>2</price>
+    <updated></updated>
+  </entry>
+</feed>`),
+			path: "/feed/entry",
+			expected: []map[string]interface{}{
+				{
+					"id":      int64(1),
+					"title":   "foo",
+					"author":  map[string]interface{}{"name": "alice", "age": int64(20)},
+					"tag":     []interface{}{"a", "b"},
+					"price":   float64(1.5),
+					"updated": int64(1000),
+				},
+				{
+					"id":      int64(2),
+					"title":   "<bar>",
+					"author":  map[string]interface{}{"name": "bob", "age": nil},
+					"tag":     []interface{}{"c"},
+					"price":   float64(2),
+					"updated": nil,
+				},
+			},
+			isErr: false,
+		},
+
+		// Elements in a namespace
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "id", Type: arrow.PrimitiveTypes.Uint32},
+						{Name: "tag", Type: arrow.ListOf(arrow.BinaryTypes.String)},
+					}, nil),
+				"entry"),
+			input:    []byte(`<feed xmlns:a="urn:a"><entry a:id="1"><a:tag>x</a:tag><a:tag>y</a:tag></entry></feed>`),
+			path:     "/feed/entry",
+			expected: []map[string]interface{}{{"id": int64(1), "tag": []interface{}{"x", "y"}}},
+			isErr:    false,
+		},
+
+		// Elements of the same local name in different namespaces
+		{
+			schema:   schema.NewIntermediateSchema(arrow.NewSchema([]arrow.Field{}, nil), "empty"),
+			input:    []byte(`<feed xmlns:a="urn:a" xmlns:b="urn:b"><entry><a:x>1</a:x><b:x>2</b:x></entry></feed>`),
+			path:     "/feed/entry",
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Attributes of the same local name in different namespaces
+		{
+			schema:   schema.NewIntermediateSchema(arrow.NewSchema([]arrow.Field{}, nil), "empty"),
+			input:    []byte(`<feed xmlns:a="urn:a" xmlns:b="urn:b"><entry a:x="1" b:x="2"/></feed>`),
+			path:     "/feed/entry",
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// An attribute and a child element of the same name
+		{
+			schema:   schema.NewIntermediateSchema(arrow.NewSchema([]arrow.Field{}, nil), "empty"),
+			input:    []byte(`<feed><entry id="1"><id>2</id></entry></feed>`),
+			path:     "/feed/entry",
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// A child element of the text key with a text
+		{
+			schema:   schema.NewIntermediateSchema(arrow.NewSchema([]arrow.Field{}, nil), "empty"),
+			input:    []byte(`<feed><entry>foo<_text>bar</_text></entry></feed>`),
+			path:     "/feed/entry",
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Unconvertible value
+		{
+			schema: schema.NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "id", Type: arrow.PrimitiveTypes.Uint32},
+					}, nil),
+				"entry"),
+			input:    []byte(`<feed><entry><id>abc</id></entry></feed>`),
+			path:     "/feed/entry",
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+
+		// Broken document
+		{
+			schema:   schema.NewIntermediateSchema(arrow.NewSchema([]arrow.Field{}, nil), "empty"),
+			input:    []byte(`<feed><entry><id>1</id>`),
+			path:     "/feed/entry",
+			expected: []map[string]interface{}{},
+			isErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		buf := bytes.NewReader(c.input)
+		d, err := newXmlInnerDecoder(buf, c.schema, c.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := make([]map[string]interface{}, 0)
+		for {
+			var v map[string]interface{}
+			err = d.Decode(&v)
+			if err != nil {
+				break
+			}
+			actual = append(actual, v)
+		}
+
+		if (err != nil && err != io.EOF) != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
+
+func TestNewXmlInnerDecoder(t *testing.T) {
+	s := schema.NewIntermediateSchema(arrow.NewSchema([]arrow.Field{}, nil), "empty")
+
+	for _, p := range []string{"", "/", "feed/entry", "/feed//entry"} {
+		if _, err := newXmlInnerDecoder(bytes.NewReader(nil), s, p); err == nil {
+			t.Errorf("expected error occurs for %s, but actual it's nil", p)
+		}
+	}
+}
@@ -2,12 +2,23 @@ package schema
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/apache/arrow/go/arrow"
 	"github.com/reproio/columnify/avro"
 )
 
+const (
+	// DefaultMetadataKey is the field metadata of the default value in JSON, e.g. 0 or {"name": "unknown"}.
+	// Default values are validated against field types, and used to fill missing fields of records.
+	DefaultMetadataKey = "default"
+	// AliasesMetadataKey is the field metadata of former names in JSON, e.g. ["user_name"], to resolve fields of
+	// records written with older schemas.
+	AliasesMetadataKey = "aliases"
+)
+
 var (
 	avroPrimitivesToArrow = map[avro.PrimitiveType]arrow.DataType{
 		avro.AvroPrimitiveType_Boolean: arrow.FixedWidthTypes.Boolean,
@@ -22,11 +33,16 @@ var (
 
 	avroLogicalTypeToArrow = map[string]arrow.DataType{
 		avro.AvroLogicalType_Date:            arrow.FixedWidthTypes.Date32,
-		avro.AvroLogicalType_Duration:        arrow.FixedWidthTypes.Duration_ms,
 		avro.AvroLogicalType_TimeMillis:      arrow.FixedWidthTypes.Time32ms,
 		avro.AvroLogicalType_TimeMicros:      arrow.FixedWidthTypes.Time64us,
 		avro.AvroLogicalType_TimestampMillis: arrow.FixedWidthTypes.Timestamp_ms,
 		avro.AvroLogicalType_TimestampMicros: arrow.FixedWidthTypes.Timestamp_us,
+		avro.AvroLogicalType_TimestampNanos:  arrow.FixedWidthTypes.Timestamp_ns,
+		// local timestamps are without time zones, which are written as timestamps not adjusted to UTC
+		avro.AvroLogicalType_LocalTimestampMillis: &arrow.TimestampType{Unit: arrow.Millisecond},
+		avro.AvroLogicalType_LocalTimestampMicros: &arrow.TimestampType{Unit: arrow.Microsecond},
+		avro.AvroLogicalType_LocalTimestampNanos:  &arrow.TimestampType{Unit: arrow.Nanosecond},
+		avro.AvroLogicalType_UUID:                 &UUIDType{},
 		// avro.AvroLogicalType_Decimal doesn't have direct mapping rule
 	}
 )
@@ -37,9 +53,17 @@ func NewSchemaFromAvroSchema(schemaContent []byte) (*IntermediateSchema, error)
 		return nil, fmt.Errorf("schema is wrong %v: %w", err, ErrInvalidSchema)
 	}
 
+	names, err := avro.NewNamedTypes(rt)
+	if errors.Is(err, avro.ErrInvalidAvroSchema) {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidSchema)
+	} else if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrUnconvertibleSchema)
+	}
+	namespace := avro.NamespaceOf(avro.FullName(rt.Name, rt.Namespace, ""))
+
 	fields := make([]arrow.Field, 0)
 	for _, f := range rt.Fields {
-		af, err := avroFieldToArrowField(f)
+		af, err := avroFieldToArrowField(f, names, namespace)
 		if err != nil {
 			return nil, err
 		}
@@ -49,22 +73,66 @@ func NewSchemaFromAvroSchema(schemaContent []byte) (*IntermediateSchema, error)
 	return NewIntermediateSchema(arrow.NewSchema(fields, nil), rt.Name), nil
 }
 
-func avroFieldToArrowField(f avro.RecordField) (*arrow.Field, error) {
+// avroFieldToArrowField converts a record field. Type names are resolved in the namespace of the record.
+func avroFieldToArrowField(f avro.RecordField, names *avro.NamedTypes, namespace string) (*arrow.Field, error) {
 	tpe, nullable := extractAvroTypeWithNullability(f.Type)
 
-	t, err := avroTypeToArrowType(tpe)
+	t, err := avroTypeToArrowType(tpe, names, namespace)
 	if err != nil {
 		return nil, err
 	}
 
+	props := make(map[string]string, len(f.Properties)+2)
+	for k, v := range f.Properties {
+		props[k] = v
+	}
+	if f.HasDefault {
+		d, err := json.Marshal(f.Default)
+		if err != nil {
+			return nil, err
+		}
+		props[DefaultMetadataKey] = string(d)
+	}
+	if v := elementNullableMetadata(avroElementNullability(tpe)); v != "" {
+		props[ElementNullableMetadataKey] = v
+	}
+	if len(f.Aliases) > 0 {
+		a, err := json.Marshal(f.Aliases)
+		if err != nil {
+			return nil, err
+		}
+		props[AliasesMetadataKey] = string(a)
+	}
+
 	return &arrow.Field{
 		Name:     f.Name,
 		Type:     t,
 		Nullable: nullable,
+		Metadata: avroPropertiesToArrowMetadata(props),
 	}, nil
 }
 
-func avroTypeToArrowType(t avro.AvroType) (arrow.DataType, error) {
+// avroPropertiesToArrowMetadata keeps additional field properties as arrow metadata.
+func avroPropertiesToArrowMetadata(props map[string]string) arrow.Metadata {
+	if len(props) == 0 {
+		return arrow.Metadata{}
+	}
+
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, props[k])
+	}
+
+	return arrow.NewMetadata(keys, values)
+}
+
+func avroTypeToArrowType(t avro.AvroType, names *avro.NamedTypes, namespace string) (arrow.DataType, error) {
 	if t.PrimitiveType != nil {
 		if t, ok := avroPrimitivesToArrow[*t.PrimitiveType]; !ok {
 			return nil, fmt.Errorf("unsupported primitive type %v: %w", t, ErrUnconvertibleSchema)
@@ -74,9 +142,10 @@ func avroTypeToArrowType(t avro.AvroType) (arrow.DataType, error) {
 	}
 
 	if t.RecordType != nil {
+		ns := avro.NamespaceOf(avro.FullName(t.RecordType.Name, t.RecordType.Namespace, namespace))
 		fields := make([]arrow.Field, 0, len(t.RecordType.Fields))
 		for _, f := range t.RecordType.Fields {
-			af, err := avroFieldToArrowField(f)
+			af, err := avroFieldToArrowField(f, names, ns)
 			if err != nil {
 				return nil, err
 			}
@@ -86,11 +155,13 @@ func avroTypeToArrowType(t avro.AvroType) (arrow.DataType, error) {
 	}
 
 	if t.EnumsType != nil {
-		return arrow.BinaryTypes.String, nil
+		return EnumWithDefaultOf(t.EnumsType.Default, t.EnumsType.Symbols...), nil
 	}
 
 	if t.ArrayType != nil {
-		itemType, err := avroTypeToArrowType(t.ArrayType.Items)
+		// nullability of items is kept in the field metadata
+		items, _ := extractAvroTypeWithNullability(t.ArrayType.Items)
+		itemType, err := avroTypeToArrowType(items, names, namespace)
 		if err != nil {
 			return nil, err
 		}
@@ -98,14 +169,23 @@ func avroTypeToArrowType(t avro.AvroType) (arrow.DataType, error) {
 	}
 
 	if t.MapsType != nil {
-		// TODO support map type
-		// NOTE arrow go module has not supported map typpe yet ???
-		return nil, fmt.Errorf("map type conversion is unsupported: %w", ErrUnconvertibleSchema)
+		valueType, err := avroTypeToArrowType(t.MapsType.Values, names, namespace)
+		if err != nil {
+			return nil, err
+		}
+		return MapOf(valueType), nil
 	}
 
 	// TODO support union type except ["null", "type"] nullable pattern
 
 	if t.FixedType != nil {
+		switch {
+		case t.FixedType.LogicalType == avro.AvroLogicalType_UUID && t.FixedType.Size == 16:
+			return &UUIDType{}, nil
+		case t.FixedType.LogicalType == avro.AvroLogicalType_Duration && t.FixedType.Size == 12:
+			// durations are months, days and milliseconds in little-endian uint32 same as parquet intervals
+			return &IntervalType{}, nil
+		}
 		return arrow.BinaryTypes.Binary, nil
 	}
 
@@ -117,16 +197,35 @@ func avroTypeToArrowType(t avro.AvroType) (arrow.DataType, error) {
 		}
 	}
 
-	// TODO defined types
+	if t.DefinedType != nil {
+		// recursive types are already rejected by the symbol table
+		dt, fullName, err := names.Lookup(string(*t.DefinedType), namespace)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", err, ErrUnconvertibleSchema)
+		}
+		return avroTypeToArrowType(dt, names, avro.NamespaceOf(fullName))
+	}
 
 	return nil, fmt.Errorf("unsupported type %v: %w", t, ErrUnconvertibleSchema)
 }
 
+// avroElementNullability returns nullability of items of each level of nested arrays, like ["null", "string"].
+func avroElementNullability(t avro.AvroType) []bool {
+	var levels []bool
+	for t.ArrayType != nil {
+		items, nullable := extractAvroTypeWithNullability(t.ArrayType.Items)
+		levels = append(levels, nullable)
+		t = items
+	}
+
+	return levels
+}
+
 // extractAvroTypeWithNullability extracts union type or others to avro type with nullable flag.
 func extractAvroTypeWithNullability(t avro.AvroType) (avro.AvroType, bool) {
 	if t.UnionType != nil {
 		// According to Avro spec, the "null" is usually listed first
-		if len(*t.UnionType) == 2 && *(*t.UnionType)[0].PrimitiveType == *avro.ToPrimitiveType(avro.AvroPrimitiveType_Null) {
+		if len(*t.UnionType) == 2 && (*t.UnionType)[0].PrimitiveType != nil && *(*t.UnionType)[0].PrimitiveType == *avro.ToPrimitiveType(avro.AvroPrimitiveType_Null) {
 			return (*t.UnionType)[1], true
 		}
 	}
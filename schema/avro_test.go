@@ -206,6 +206,15 @@ func TestNewArrowSchemaFromAvroSchema(t *testing.T) {
         "aliases": ["alias"],
         "symbols": ["ZERO", "ONE", "TWO"]
       }
+    },
+    {
+      "name": "enumWithDefault",
+      "type": {
+        "name": "enumWithDefault",
+        "type": "enum",
+        "symbols": ["UNKNOWN", "ONE"],
+        "default": "UNKNOWN"
+      }
     }
   ]
 }
@@ -214,7 +223,12 @@ func TestNewArrowSchemaFromAvroSchema(t *testing.T) {
 				[]arrow.Field{
 					{
 						Name:     "enum",
-						Type:     arrow.BinaryTypes.String,
+						Type:     EnumOf("ZERO", "ONE", "TWO"),
+						Nullable: false,
+					},
+					{
+						Name:     "enumWithDefault",
+						Type:     EnumWithDefaultOf("UNKNOWN", "UNKNOWN", "ONE"),
 						Nullable: false,
 					},
 				}, nil,
@@ -476,6 +490,39 @@ func TestNewArrowSchemaFromAvroSchema(t *testing.T) {
 			err: nil,
 		},
 
+		// Logical types of Avro 1.10 and later, and duration
+		{
+			avroSchema: `
+{
+  "type": "record",
+  "name": "LogicalTypes",
+  "fields" : [
+    {"name": "uuid", "type": {"type": "string", "logicalType": "uuid"}},
+    {"name": "uuid-fixed", "type": {"type": "fixed", "name": "UUID", "size": 16, "logicalType": "uuid"}},
+    {"name": "not-uuid-fixed", "type": {"type": "fixed", "name": "Hash", "size": 8, "logicalType": "uuid"}},
+    {"name": "local-timestamp-millis", "type": {"type": "long", "logicalType": "local-timestamp-millis"}},
+    {"name": "local-timestamp-micros", "type": {"type": "long", "logicalType": "local-timestamp-micros"}},
+    {"name": "timestamp-nanos", "type": {"type": "long", "logicalType": "timestamp-nanos"}},
+    {"name": "local-timestamp-nanos", "type": {"type": "long", "logicalType": "local-timestamp-nanos"}},
+    {"name": "duration", "type": {"type": "fixed", "name": "Duration", "size": 12, "logicalType": "duration"}}
+  ]
+}
+`,
+			expected: arrow.NewSchema(
+				[]arrow.Field{
+					{Name: "uuid", Type: &UUIDType{}},
+					{Name: "uuid-fixed", Type: &UUIDType{}},
+					{Name: "not-uuid-fixed", Type: arrow.BinaryTypes.Binary},
+					{Name: "local-timestamp-millis", Type: &arrow.TimestampType{Unit: arrow.Millisecond}},
+					{Name: "local-timestamp-micros", Type: &arrow.TimestampType{Unit: arrow.Microsecond}},
+					{Name: "timestamp-nanos", Type: arrow.FixedWidthTypes.Timestamp_ns},
+					{Name: "local-timestamp-nanos", Type: &arrow.TimestampType{Unit: arrow.Nanosecond}},
+					{Name: "duration", Type: &IntervalType{}},
+				}, nil,
+			),
+			err: nil,
+		},
+
 		// null primitive type
 		{
 			avroSchema: `
@@ -508,8 +555,16 @@ func TestNewArrowSchemaFromAvroSchema(t *testing.T) {
   ]
 }
 `,
-			expected: &arrow.Schema{},
-			err:      ErrUnconvertibleSchema,
+			expected: arrow.NewSchema(
+				[]arrow.Field{
+					{
+						Name:     "map",
+						Type:     MapOf(arrow.PrimitiveTypes.Uint64),
+						Nullable: false,
+					},
+				}, nil,
+			),
+			err: nil,
 		},
 
 		// decimal logical type
@@ -535,6 +590,76 @@ func TestNewArrowSchemaFromAvroSchema(t *testing.T) {
 			err:      ErrUnconvertibleSchema,
 		},
 
+		// Named type references
+		{
+			avroSchema: `
+{
+  "type": "record",
+  "name": "References",
+  "namespace": "com.example",
+  "fields" : [
+    {"name": "home", "type": {
+      "type": "record",
+      "name": "Address",
+      "fields": [{"name": "city", "type": "string"}]
+    }},
+    {"name": "office", "type": ["null", "Address"]},
+    {"name": "previous", "type": {"type": "array", "items": "com.example.Address"}},
+    {"name": "other", "type": {
+      "type": "record",
+      "name": "Other",
+      "namespace": "org.example",
+      "fields": [{"name": "address", "type": "com.example.Address"}]
+    }}
+  ]
+}
+`,
+			expected: arrow.NewSchema(
+				[]arrow.Field{
+					{
+						Name:     "home",
+						Type:     arrow.StructOf(arrow.Field{Name: "city", Type: arrow.BinaryTypes.String}),
+						Nullable: false,
+					},
+					{
+						Name:     "office",
+						Type:     arrow.StructOf(arrow.Field{Name: "city", Type: arrow.BinaryTypes.String}),
+						Nullable: true,
+					},
+					{
+						Name:     "previous",
+						Type:     arrow.ListOf(arrow.StructOf(arrow.Field{Name: "city", Type: arrow.BinaryTypes.String})),
+						Nullable: false,
+					},
+					{
+						Name: "other",
+						Type: arrow.StructOf(arrow.Field{
+							Name: "address",
+							Type: arrow.StructOf(arrow.Field{Name: "city", Type: arrow.BinaryTypes.String}),
+						}),
+						Nullable: false,
+					},
+				}, nil,
+			),
+			err: nil,
+		},
+
+		// Recursive type
+		{
+			avroSchema: `
+{
+  "type": "record",
+  "name": "LinkedList",
+  "fields" : [
+    {"name": "value", "type": "long"},
+    {"name": "next", "type": ["null", "LinkedList"]}
+  ]
+}
+`,
+			expected: &arrow.Schema{},
+			err:      ErrUnconvertibleSchema,
+		},
+
 		// Unsupported type
 		{
 			avroSchema: `
@@ -580,6 +705,42 @@ func TestNewArrowSchemaFromAvroSchema(t *testing.T) {
 			err:      ErrUnconvertibleSchema,
 		},
 
+		// Nullable items of arrays
+		{
+			avroSchema: `
+{
+  "type": "record",
+  "name": "NullableItems",
+  "fields" : [
+    {"name": "nullable_items", "type": {"type": "array", "items": ["null", "string"]}},
+    {"name": "nested", "type": ["null", {"type": "array", "items": {"type": "array", "items": ["null", "long"]}}]},
+    {"name": "required_items", "type": ["null", {"type": "array", "items": "string"}]}
+  ]
+}
+`,
+			expected: arrow.NewSchema(
+				[]arrow.Field{
+					{
+						Name:     "nullable_items",
+						Type:     arrow.ListOf(arrow.BinaryTypes.String),
+						Metadata: arrow.NewMetadata([]string{ElementNullableMetadataKey}, []string{"true"}),
+					},
+					{
+						Name:     "nested",
+						Type:     arrow.ListOf(arrow.ListOf(arrow.PrimitiveTypes.Uint64)),
+						Nullable: true,
+						Metadata: arrow.NewMetadata([]string{ElementNullableMetadataKey}, []string{"false,true"}),
+					},
+					{
+						Name:     "required_items",
+						Type:     arrow.ListOf(arrow.BinaryTypes.String),
+						Nullable: true,
+					},
+				}, nil,
+			),
+			err: nil,
+		},
+
 		// Unsupported type in array
 		{
 			avroSchema: `
@@ -601,6 +762,21 @@ func TestNewArrowSchemaFromAvroSchema(t *testing.T) {
 			err:      ErrUnconvertibleSchema,
 		},
 
+		// Invalid default
+		{
+			avroSchema: `
+{
+  "type": "record",
+  "name": "InvalidDefault",
+  "fields" : [
+    {"name": "int", "type": "int", "default": "zero"}
+  ]
+}
+`,
+			expected: &arrow.Schema{},
+			err:      ErrInvalidSchema,
+		},
+
 		// Invalid schema
 		{
 			avroSchema: "invalid schema",
@@ -18,10 +18,22 @@ var (
 		bigquery.DateFieldType:      arrow.FixedWidthTypes.Date32,
 		bigquery.TimeFieldType:      arrow.FixedWidthTypes.Time64us,
 		bigquery.TimestampFieldType: arrow.FixedWidthTypes.Timestamp_us,
-		// bigquery.DateTimeFieldType: Unsupported
+		// DATETIME is a civil time, so it's a local timestamp without time zone
+		bigquery.DateTimeFieldType:  &arrow.TimestampType{Unit: arrow.Microsecond},
+		bigquery.GeographyFieldType: &GeographyType{},
+		bigquery.JSONFieldType:      &JSONType{},
+		// INTERVAL can be negative and has microseconds, which parquet intervals can't hold
+		bigquery.IntervalFieldType: &IntervalStringType{},
 	}
 )
 
+const (
+	// bqBigNumericPrecision and bqBigNumericScale are the default of BIGNUMERIC, whose precision is 76.76 digits. The
+	// precision is rounded up to cover the whole range, which has 39 integer digits, so it's written in 33 bytes.
+	bqBigNumericPrecision = 77
+	bqBigNumericScale     = 38
+)
+
 func NewSchemaFromBigQuerySchema(schemaContent []byte) (*IntermediateSchema, error) {
 	s, err := bigquery.SchemaFromJSON(schemaContent)
 	if err != nil {
@@ -49,6 +61,14 @@ func bqFieldToArrowField(f *bigquery.FieldSchema) (*arrow.Field, error) {
 		}, nil
 	}
 
+	if f.Type == bigquery.BigNumericFieldType {
+		return &arrow.Field{
+			Name:     f.Name,
+			Type:     bqModeToList(f, bqBigNumericToArrow(f)),
+			Nullable: bqModeToNullable(f),
+		}, nil
+	}
+
 	if f.Type == bigquery.RecordFieldType {
 		subFields := make([]arrow.Field, 0, len(f.Schema))
 		for _, sub := range f.Schema {
@@ -70,6 +90,15 @@ func bqFieldToArrowField(f *bigquery.FieldSchema) (*arrow.Field, error) {
 	return nil, fmt.Errorf("unsupported field %v: %w", f, ErrUnconvertibleSchema)
 }
 
+// bqBigNumericToArrow returns the decimal of the parameterized precision and scale, or the default.
+func bqBigNumericToArrow(f *bigquery.FieldSchema) arrow.DataType {
+	if f.Precision == 0 {
+		return &Decimal256Type{Precision: bqBigNumericPrecision, Scale: bqBigNumericScale}
+	}
+
+	return &Decimal256Type{Precision: int32(f.Precision), Scale: int32(f.Scale)}
+}
+
 func bqModeToNullable(f *bigquery.FieldSchema) bool {
 	return !f.Required
 }
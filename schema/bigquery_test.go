@@ -435,7 +435,7 @@ func TestNewArrowSchemaFromBigquerySchema(t *testing.T) {
 			err: nil,
 		},
 
-		// Unsupported field
+		// DATETIME, GEOGRAPHY, JSON, BIGNUMERIC and INTERVAL
 		{
 			bqSchema: `
 [
@@ -443,31 +443,93 @@ func TestNewArrowSchemaFromBigquerySchema(t *testing.T) {
     "name": "datetime",
     "type": "DATETIME",
     "mode": "REQUIRED"
-  }
-]`,
-			expected: &arrow.Schema{},
-			err:      ErrUnconvertibleSchema,
-		},
-
-		// Unsupported field in record
-		{
-			bqSchema: `
-[
+  },
+  {
+    "name": "geography",
+    "type": "GEOGRAPHY",
+    "mode": "NULLABLE"
+  },
+  {
+    "name": "json",
+    "type": "JSON",
+    "mode": "NULLABLE"
+  },
+  {
+    "name": "bignumeric",
+    "type": "BIGNUMERIC",
+    "mode": "NULLABLE"
+  },
+  {
+    "name":      "price",
+    "type":      "BIGNUMERIC",
+    "mode":      "NULLABLE",
+    "precision": "40",
+    "scale":     "10"
+  },
+  {
+    "name": "interval",
+    "type": "INTERVAL",
+    "mode": "NULLABLE"
+  },
   {
     "name":   "record",
     "type":   "RECORD",
     "mode":   "REQUIRED",
     "fields": [
       {
-        "name": "boolean",
-		"type": "DATETIME",
-        "mode": "REQUIRED"
+        "name": "datetime",
+        "type": "DATETIME",
+        "mode": "REPEATED"
       }
     ]
   }
 ]`,
-			expected: &arrow.Schema{},
-			err:      ErrUnconvertibleSchema,
+			expected: arrow.NewSchema(
+				[]arrow.Field{
+					{
+						Name:     "datetime",
+						Type:     &arrow.TimestampType{Unit: arrow.Microsecond},
+						Nullable: false,
+					},
+					{
+						Name:     "geography",
+						Type:     &GeographyType{},
+						Nullable: true,
+					},
+					{
+						Name:     "json",
+						Type:     &JSONType{},
+						Nullable: true,
+					},
+					{
+						Name:     "bignumeric",
+						Type:     &Decimal256Type{Precision: 77, Scale: 38},
+						Nullable: true,
+					},
+					{
+						Name:     "price",
+						Type:     &Decimal256Type{Precision: 40, Scale: 10},
+						Nullable: true,
+					},
+					{
+						Name:     "interval",
+						Type:     &IntervalStringType{},
+						Nullable: true,
+					},
+					{
+						Name: "record",
+						Type: arrow.StructOf(
+							arrow.Field{
+								Name:     "datetime",
+								Type:     arrow.ListOf(&arrow.TimestampType{Unit: arrow.Microsecond}),
+								Nullable: true,
+							},
+						),
+						Nullable: false,
+					},
+				}, nil,
+			),
+			err: nil,
 		},
 
 		// Invalid schema JSON
@@ -0,0 +1,49 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow/go/arrow"
+)
+
+// ElementNullableMetadataKey is the field metadata of nullability of list elements, because list types of the arrow
+// go module don't have item fields yet. The value has one for each level of nested lists from the outermost,
+// e.g. "false,true" for lists of lists of nullable elements. Elements are required without it.
+const ElementNullableMetadataKey = "elementNullable"
+
+// elementNullableMetadata returns the metadata value of nullability of each level of nested lists, or an empty
+// string if all elements are required.
+func elementNullableMetadata(levels []bool) string {
+	values := make([]string, len(levels))
+	nullable := false
+	for i, l := range levels {
+		values[i] = strconv.FormatBool(l)
+		nullable = nullable || l
+	}
+	if !nullable {
+		return ""
+	}
+
+	return strings.Join(values, ",")
+}
+
+// listElementField returns the element field of the list field, which has the nullability of inner levels.
+func listElementField(f arrow.Field, lt *arrow.ListType, name string) arrow.Field {
+	item := arrow.Field{
+		Name: name,
+		Type: lt.Elem(),
+	}
+
+	i := f.Metadata.FindKey(ElementNullableMetadataKey)
+	if i < 0 {
+		return item
+	}
+	levels := strings.SplitN(f.Metadata.Values()[i], ",", 2)
+	item.Nullable, _ = strconv.ParseBool(levels[0])
+	if len(levels) > 1 {
+		item.Metadata = arrow.NewMetadata([]string{ElementNullableMetadataKey}, []string{levels[1]})
+	}
+
+	return item
+}
@@ -0,0 +1,96 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow/go/arrow"
+)
+
+// JSONType is a string of JSON documents, which is written with JSON logical type.
+// It and the types below are defined here because the arrow go module doesn't have them yet.
+type JSONType struct{}
+
+func (*JSONType) ID() arrow.Type { return arrow.STRING }
+func (*JSONType) Name() string   { return "json" }
+func (*JSONType) String() string { return "json" }
+
+// EnumType is a string of symbols, which is written with ENUM logical type.
+type EnumType struct {
+	symbols       []string
+	defaultSymbol string
+}
+
+// EnumOf returns the enum type of given symbols.
+func EnumOf(symbols ...string) *EnumType {
+	return &EnumType{
+		symbols: symbols,
+	}
+}
+
+// EnumWithDefaultOf returns the enum type of given symbols, whose unknown symbols are replaced with the default one.
+func EnumWithDefaultOf(defaultSymbol string, symbols ...string) *EnumType {
+	return &EnumType{
+		symbols:       symbols,
+		defaultSymbol: defaultSymbol,
+	}
+}
+
+func (*EnumType) ID() arrow.Type { return arrow.STRING }
+func (*EnumType) Name() string   { return "enum" }
+func (t *EnumType) String() string {
+	if t.defaultSymbol != "" {
+		return fmt.Sprintf("enum<%s; default=%s>", strings.Join(t.symbols, ", "), t.defaultSymbol)
+	}
+	return fmt.Sprintf("enum<%s>", strings.Join(t.symbols, ", "))
+}
+
+// Symbols returns the declared symbols.
+func (t *EnumType) Symbols() []string { return t.symbols }
+
+// Default returns the symbol for unknown symbols, which is empty if unknown symbols are rejected.
+func (t *EnumType) Default() string { return t.defaultSymbol }
+
+// UUIDType is a 16 bytes UUID, which is written as FIXED_LEN_BYTE_ARRAY(16) with UUID logical type.
+type UUIDType struct{}
+
+func (*UUIDType) ID() arrow.Type { return arrow.FIXED_SIZE_BINARY }
+func (*UUIDType) Name() string   { return "uuid" }
+func (*UUIDType) String() string { return "uuid" }
+
+// GeographyType is a geography in WKT, which is written as a string with GeoParquet metadata.
+// Edges between vertices are spherical like BigQuery GEOGRAPHY.
+type GeographyType struct{}
+
+func (*GeographyType) ID() arrow.Type { return arrow.STRING }
+func (*GeographyType) Name() string   { return "geography" }
+func (*GeographyType) String() string { return "geography" }
+
+// Decimal256Type is a decimal wider than arrow.Decimal128Type like BigQuery BIGNUMERIC,
+// which is written as FIXED_LEN_BYTE_ARRAY with DECIMAL logical type.
+type Decimal256Type struct {
+	Precision int32
+	Scale     int32
+}
+
+func (*Decimal256Type) ID() arrow.Type { return arrow.DECIMAL }
+func (*Decimal256Type) Name() string   { return "decimal256" }
+func (t *Decimal256Type) String() string {
+	return fmt.Sprintf("%s(%d, %d)", t.Name(), t.Precision, t.Scale)
+}
+
+// IntervalType is an interval of months, days and milliseconds, which is written as FIXED_LEN_BYTE_ARRAY(12)
+// with INTERVAL converted type.
+type IntervalType struct{}
+
+func (*IntervalType) ID() arrow.Type { return arrow.INTERVAL }
+func (*IntervalType) Name() string   { return "interval" }
+func (*IntervalType) String() string { return "interval" }
+
+// IntervalStringType is an interval of months, days and microseconds like BigQuery INTERVAL, which can be negative
+// unlike IntervalType. It's written as a string of BigQuery's canonical format, e.g. "1-2 -3 4:5:6.789".
+type IntervalStringType struct{}
+
+func (*IntervalStringType) ID() arrow.Type { return arrow.STRING }
+func (*IntervalStringType) Name() string   { return "interval_string" }
+func (*IntervalStringType) String() string { return "interval_string" }
@@ -0,0 +1,29 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/apache/arrow/go/arrow"
+)
+
+// MapType is a map with string keys, like Avro maps.
+// It's defined here because the arrow go module doesn't support map types yet.
+type MapType struct {
+	valueType arrow.DataType
+}
+
+// MapOf returns the map type of string keys and given typed values.
+func MapOf(valueType arrow.DataType) *MapType {
+	return &MapType{
+		valueType: valueType,
+	}
+}
+
+func (*MapType) ID() arrow.Type { return arrow.MAP }
+func (*MapType) Name() string   { return "map" }
+func (t *MapType) String() string {
+	return fmt.Sprintf("map<key: %v, value: %v>", arrow.BinaryTypes.String, t.valueType)
+}
+
+// ValueType returns the type of map values.
+func (t *MapType) ValueType() arrow.DataType { return t.valueType }
@@ -0,0 +1,162 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/ipc"
+)
+
+const (
+	// ArrowSchemaMetadataKey is the key of the serialized arrow schema in parquet key-value metadata.
+	ArrowSchemaMetadataKey = "ARROW:schema"
+	// AvroSchemaMetadataKey is the key of the original Avro schema, the same as parquet-avro.
+	AvroSchemaMetadataKey = "parquet.avro.schema"
+	// BigquerySchemaMetadataKey is the key of the original BigQuery schema.
+	BigquerySchemaMetadataKey = "bigquery.schema"
+	// GeoMetadataKey is the key of GeoParquet column metadata for geography columns.
+	GeoMetadataKey = "geo"
+)
+
+var schemaMetadataKeys = map[string]string{
+	SchemaTypeAvro:     AvroSchemaMetadataKey,
+	SchemaTypeBigquery: BigquerySchemaMetadataKey,
+}
+
+// NewSchemaMetadata returns the key-value metadata of the original schema as compacted JSON.
+// It returns an empty key for schema types without schema files like sqlite.
+func NewSchemaMetadata(content []byte, schemaType string) (string, string, error) {
+	key, ok := schemaMetadataKeys[schemaType]
+	if !ok {
+		return "", "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, content); err != nil {
+		return "", "", fmt.Errorf("%v: %w", err, ErrInvalidSchema)
+	}
+
+	return key, buf.String(), nil
+}
+
+// NewArrowSchemaMetadata serializes the schema as an Arrow IPC stream encoded in base64, which Arrow based readers
+// use to restore exact types. The types are adjusted to written parquet columns, e.g. integers are signed.
+func NewArrowSchemaMetadata(s IntermediateSchema) (string, error) {
+	fields := make([]arrow.Field, 0, len(s.ArrowSchema.Fields()))
+	for _, f := range s.ArrowSchema.Fields() {
+		t, err := parquetArrowType(f.Type)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, arrow.Field{Name: f.Name, Type: t, Nullable: f.Nullable})
+	}
+
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(arrow.NewSchema(fields, nil)))
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// NewGeoMetadata returns GeoParquet metadata of top-level geography columns, which are written as WKT on a sphere
+// like BigQuery GEOGRAPHY. It returns an empty string if the schema has no geography columns.
+func NewGeoMetadata(s IntermediateSchema) (string, error) {
+	type geoColumn struct {
+		Encoding      string   `json:"encoding"`
+		GeometryTypes []string `json:"geometry_types"`
+		Edges         string   `json:"edges"`
+	}
+	type geoMetadata struct {
+		Version       string               `json:"version"`
+		PrimaryColumn string               `json:"primary_column"`
+		Columns       map[string]geoColumn `json:"columns"`
+	}
+
+	m := geoMetadata{Version: "1.0.0", Columns: make(map[string]geoColumn)}
+	for _, f := range s.ArrowSchema.Fields() {
+		if _, ok := f.Type.(*GeographyType); !ok {
+			continue
+		}
+		if m.PrimaryColumn == "" {
+			m.PrimaryColumn = f.Name
+		}
+		m.Columns[f.Name] = geoColumn{Encoding: "WKT", GeometryTypes: []string{}, Edges: "spherical"}
+	}
+	if len(m.Columns) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// decimal128MaxPrecision is the maximum precision of arrow.Decimal128Type.
+const decimal128MaxPrecision = 38
+
+// parquetArrowType returns the arrow type that readers infer from the parquet column written for given type.
+func parquetArrowType(t arrow.DataType) (arrow.DataType, error) {
+	switch tt := t.(type) {
+	case *arrow.Uint32Type:
+		return arrow.PrimitiveTypes.Int32, nil
+
+	case *arrow.Uint64Type:
+		return arrow.PrimitiveTypes.Int64, nil
+
+	case *arrow.ListType:
+		elem, err := parquetArrowType(tt.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return arrow.ListOf(elem), nil
+
+	case *arrow.StructType:
+		fields := make([]arrow.Field, 0, len(tt.Fields()))
+		for _, f := range tt.Fields() {
+			ft, err := parquetArrowType(f.Type)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, arrow.Field{Name: f.Name, Type: ft, Nullable: f.Nullable})
+		}
+		return arrow.StructOf(fields...), nil
+
+	case *MapType:
+		// The arrow version doesn't have maps, and lists of key-value structs don't match MAP annotated columns
+		return nil, fmt.Errorf("map %v: %w", t, ErrUnconvertibleSchema)
+
+	case *Decimal256Type:
+		// The arrow version doesn't have 256 bits decimals, but narrower ones are read as 128 bits
+		if tt.Precision > decimal128MaxPrecision {
+			return nil, fmt.Errorf("%v over precision %d: %w", t, decimal128MaxPrecision, ErrUnconvertibleSchema)
+		}
+		return &arrow.Decimal128Type{Precision: tt.Precision, Scale: tt.Scale}, nil
+
+	case *JSONType, *EnumType:
+		return arrow.BinaryTypes.String, nil
+
+	case *UUIDType:
+		return &arrow.FixedSizeBinaryType{ByteWidth: 16}, nil
+
+	case *GeographyType, *IntervalStringType:
+		return arrow.BinaryTypes.String, nil
+
+	case *IntervalType:
+		return &arrow.FixedSizeBinaryType{ByteWidth: 12}, nil
+
+	case *arrow.Int8Type, *arrow.Int16Type, *arrow.Int32Type, *arrow.Int64Type, *arrow.Uint8Type, *arrow.Uint16Type,
+		*arrow.BooleanType, *arrow.Float32Type, *arrow.Float64Type, *arrow.BinaryType, *arrow.StringType,
+		*arrow.Date32Type, *arrow.Time32Type, *arrow.Time64Type, *arrow.TimestampType, *arrow.Decimal128Type:
+		return t, nil
+	}
+
+	return nil, fmt.Errorf("%v: %w", t, ErrUnconvertibleSchema)
+}
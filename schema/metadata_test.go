@@ -0,0 +1,156 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/ipc"
+)
+
+func TestNewSchemaMetadata(t *testing.T) {
+	cases := []struct {
+		content    []byte
+		schemaType string
+		key        string
+		value      string
+		isErr      bool
+	}{
+		{
+			content: []byte(`{
+  "type": "record",
+  "name": "Primitives",
+  "fields": [{"name": "boolean", "type": "boolean"}]
+}`),
+			schemaType: SchemaTypeAvro,
+			key:        AvroSchemaMetadataKey,
+			value:      `{"type":"record","name":"Primitives","fields":[{"name":"boolean","type":"boolean"}]}`,
+			isErr:      false,
+		},
+		{
+			content:    []byte(`[ {"name": "boolean", "type": "BOOLEAN", "mode": "REQUIRED"} ]`),
+			schemaType: SchemaTypeBigquery,
+			key:        BigquerySchemaMetadataKey,
+			value:      `[{"name":"boolean","type":"BOOLEAN","mode":"REQUIRED"}]`,
+			isErr:      false,
+		},
+		{
+			content:    nil,
+			schemaType: SchemaTypeSqlite,
+			key:        "",
+			value:      "",
+			isErr:      false,
+		},
+		{
+			content:    []byte(`{`),
+			schemaType: SchemaTypeAvro,
+			key:        "",
+			value:      "",
+			isErr:      true,
+		},
+	}
+
+	for _, c := range cases {
+		key, value, err := NewSchemaMetadata(c.content, c.schemaType)
+
+		if err != nil != c.isErr {
+			t.Errorf("expected: %v, but actual: %v\n", c.isErr, err)
+		}
+
+		if key != c.key || value != c.value {
+			t.Errorf("expected: %s=%s, but actual: %s=%s\n", c.key, c.value, key, value)
+		}
+	}
+}
+
+func TestNewArrowSchemaMetadata(t *testing.T) {
+	s := NewIntermediateSchema(
+		arrow.NewSchema(
+			[]arrow.Field{
+				{Name: "int", Type: arrow.PrimitiveTypes.Uint32},
+				{Name: "long", Type: arrow.PrimitiveTypes.Uint64, Nullable: true},
+				{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_ms},
+				{Name: "list", Type: arrow.ListOf(arrow.BinaryTypes.String)},
+				{Name: "bignumeric", Type: &Decimal256Type{Precision: 38, Scale: 9}},
+				{Name: "json", Type: &JSONType{}},
+				{Name: "uuid", Type: &UUIDType{}},
+			}, nil),
+		"metadata")
+	expected := arrow.NewSchema(
+		[]arrow.Field{
+			{Name: "int", Type: arrow.PrimitiveTypes.Int32},
+			{Name: "long", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+			{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_ms},
+			{Name: "list", Type: arrow.ListOf(arrow.BinaryTypes.String)},
+			{Name: "bignumeric", Type: &arrow.Decimal128Type{Precision: 38, Scale: 9}},
+			{Name: "json", Type: arrow.BinaryTypes.String},
+			{Name: "uuid", Type: &arrow.FixedSizeBinaryType{ByteWidth: 16}},
+		}, nil)
+
+	v, err := NewArrowSchemaMetadata(*s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := base64.StdEncoding.DecodeString(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ipc.NewReader(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+
+	if !r.Schema().Equal(expected) {
+		t.Errorf("expected: %v, but actual: %v\n", expected, r.Schema())
+	}
+
+	// the arrow version can't represent them as written columns
+	for _, dt := range []arrow.DataType{
+		arrow.Null,
+		MapOf(arrow.PrimitiveTypes.Uint64),
+		arrow.ListOf(MapOf(arrow.BinaryTypes.String)),
+		&Decimal256Type{Precision: 77, Scale: 38},
+	} {
+		unconvertible := NewIntermediateSchema(
+			arrow.NewSchema([]arrow.Field{{Name: "unconvertible", Type: dt}}, nil),
+			"unconvertible")
+		if _, err := NewArrowSchemaMetadata(*unconvertible); !errors.Is(err, ErrUnconvertibleSchema) {
+			t.Errorf("expected: %v, but actual: %v\n", ErrUnconvertibleSchema, err)
+		}
+	}
+}
+
+func TestNewGeoMetadata(t *testing.T) {
+	cases := []struct {
+		fields   []arrow.Field
+		expected string
+	}{
+		{
+			fields: []arrow.Field{
+				{Name: "id", Type: arrow.PrimitiveTypes.Uint64},
+				{Name: "location", Type: &GeographyType{}},
+				{Name: "area", Type: &GeographyType{}, Nullable: true},
+			},
+			expected: `{"version":"1.0.0","primary_column":"location","columns":{"area":{"encoding":"WKT","geometry_types":[],"edges":"spherical"},"location":{"encoding":"WKT","geometry_types":[],"edges":"spherical"}}}`,
+		},
+		{
+			fields:   []arrow.Field{{Name: "id", Type: arrow.PrimitiveTypes.Uint64}},
+			expected: "",
+		},
+	}
+
+	for _, c := range cases {
+		actual, err := NewGeoMetadata(*NewIntermediateSchema(arrow.NewSchema(c.fields, nil), "geo"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != c.expected {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
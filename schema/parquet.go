@@ -2,53 +2,47 @@ package schema
 
 import (
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/apache/arrow/go/arrow"
 	"github.com/xitongsys/parquet-go/common"
 	"github.com/xitongsys/parquet-go/parquet"
 	"github.com/xitongsys/parquet-go/schema"
-	"github.com/xitongsys/parquet-go/types"
 )
 
-var (
-	arrowToParquetPrimitiveType = map[arrow.DataType]string{
-		arrow.FixedWidthTypes.Boolean: "BOOLEAN",
-		arrow.PrimitiveTypes.Uint32:   "INT32",
-		arrow.PrimitiveTypes.Uint64:   "INT64",
-		arrow.PrimitiveTypes.Float32:  "FLOAT",
-		arrow.PrimitiveTypes.Float64:  "DOUBLE",
-		arrow.BinaryTypes.Binary:      "BYTE_ARRAY",
-		arrow.BinaryTypes.String:      "UTF8",
-	}
-	arrowToParquetConvertedType = map[arrow.DataType]struct {
-		t  *parquet.Type
-		ct *parquet.ConvertedType
-	}{
-		arrow.FixedWidthTypes.Date32: {
-			t:  parquet.TypePtr(parquet.Type_INT32),
-			ct: parquet.ConvertedTypePtr(parquet.ConvertedType_DATE),
-		},
-		arrow.FixedWidthTypes.Time32ms: {
-			t:  parquet.TypePtr(parquet.Type_INT32),
-			ct: parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MILLIS),
-		},
-		arrow.FixedWidthTypes.Time64us: {
-			t:  parquet.TypePtr(parquet.Type_INT64),
-			ct: parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MICROS),
-		},
-		arrow.FixedWidthTypes.Timestamp_ms: {
-			t:  parquet.TypePtr(parquet.Type_INT64),
-			ct: parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS),
-		},
-		arrow.FixedWidthTypes.Timestamp_us: {
-			t:  parquet.TypePtr(parquet.Type_INT64),
-			ct: parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS),
-		},
+// ParquetOptions changes how the schema is written for compatibility with readers.
+type ParquetOptions struct {
+	// LegacyTypes omits LogicalType annotations for old readers like Hive 2, and writes only ConvertedType.
+	// Types which converted types can't express like TIMESTAMP(NANOS) and UUID are written without annotations.
+	LegacyTypes bool
+	// LegacyLists writes lists as repeated fields, the two-level encoding of old writers.
+	// It can't distinguish null lists from empty ones, and can't have null elements.
+	LegacyLists bool
+	// Int96Timestamps writes all timestamps as INT96, which old Hive and Impala only understand.
+	Int96Timestamps bool
+	// Int96TimestampColumns are column paths of timestamps written as INT96 like "nested.created_at".
+	// Paths of groups select timestamps under them.
+	Int96TimestampColumns []string
+}
+
+// isInt96Timestamp returns true if timestamps of the column path are written as INT96.
+func (o ParquetOptions) isInt96Timestamp(path string) bool {
+	if o.Int96Timestamps {
+		return true
 	}
-)
+	for _, p := range o.Int96TimestampColumns {
+		if p == path || strings.HasPrefix(path, p+".") {
+			return true
+		}
+	}
+
+	return false
+}
 
 // NewSchemaHandlerFromArrow converts intermediate schema to parquet-go schema handler.
-func NewSchemaHandlerFromArrow(s IntermediateSchema) (*schema.SchemaHandler, error) {
+func NewSchemaHandlerFromArrow(s IntermediateSchema, o ParquetOptions) (*schema.SchemaHandler, error) {
 	elems := make([]*parquet.SchemaElement, 0)
 	tags := make([]*common.Tag, 0)
 
@@ -69,7 +63,7 @@ func NewSchemaHandlerFromArrow(s IntermediateSchema) (*schema.SchemaHandler, err
 
 	// fields under the record
 	for _, child := range s.ArrowSchema.Fields() {
-		e, tag, err := arrowFieldToParquetSchemaInfo(child)
+		e, tag, err := arrowFieldToParquetSchemaInfo(child, child.Name, o)
 		if err != nil {
 			return nil, err
 		}
@@ -86,20 +80,16 @@ func NewSchemaHandlerFromArrow(s IntermediateSchema) (*schema.SchemaHandler, err
 	return sh, nil
 }
 
-func arrowFieldToParquetSchemaInfo(f arrow.Field) ([]*parquet.SchemaElement, []*common.Tag, error) {
-	// primitive types
-	if tn, ok := arrowToParquetPrimitiveType[f.Type]; ok {
-		t, ct := types.TypeNameToParquetType(tn, "")
-		e := &parquet.SchemaElement{
-			Type:           t,
-			Name:           f.Name,
-			ConvertedType:  ct,
-			RepetitionType: arrowNullableToParquetRepetitionType(f.Nullable),
-		}
+// arrowFieldToParquetSchemaInfo converts a field to schema elements. path is the column path of the field like "nested.field".
+func arrowFieldToParquetSchemaInfo(f arrow.Field, path string, o ParquetOptions) ([]*parquet.SchemaElement, []*common.Tag, error) {
+	// primitive and logical types
+	if e, ok := arrowTypeToParquetElement(f.Type, path, o); ok {
+		e.Name = f.Name
+		e.RepetitionType = arrowNullableToParquetRepetitionType(f.Nullable)
 		tag := &common.Tag{
 			ExName: e.GetName(),
 			InName: common.HeadToUpper(e.GetName()),
-			Type:   tn,
+			Type:   e.GetType().String(),
 		}
 		return []*parquet.SchemaElement{e}, []*common.Tag{tag}, nil
 	}
@@ -127,7 +117,7 @@ func arrowFieldToParquetSchemaInfo(f arrow.Field) ([]*parquet.SchemaElement, []*
 
 			// fields under the record
 			for _, child := range st.Fields() {
-				e, tag, err := arrowFieldToParquetSchemaInfo(child)
+				e, tag, err := arrowFieldToParquetSchemaInfo(child, path+"."+child.Name, o)
 				if err != nil {
 					return nil, nil, err
 				}
@@ -142,43 +132,203 @@ func arrowFieldToParquetSchemaInfo(f arrow.Field) ([]*parquet.SchemaElement, []*
 	// list
 	if f.Type.ID() == arrow.LIST {
 		if lt, ok := f.Type.(*arrow.ListType); ok {
-			item := arrow.Field{
-				Name: f.Name,
-				Type: lt.Elem(),
+			if o.LegacyLists {
+				return arrowListToLegacyParquetSchemaInfo(f, lt, path, o)
+			}
+			return arrowListToParquetSchemaInfo(f, lt, path, o)
+		}
+	}
+
+	// map
+	if f.Type.ID() == arrow.MAP {
+		if mt, ok := f.Type.(*MapType); ok {
+			key := arrow.Field{
+				Name: "key",
+				Type: arrow.BinaryTypes.String,
+			}
+			value := arrow.Field{
+				Name: "value",
+				Type: mt.ValueType(),
 			}
 
-			elems, tags, err := arrowFieldToParquetSchemaInfo(item)
+			keyElems, keyTags, err := arrowFieldToParquetSchemaInfo(key, path+".key_value.key", o)
 			if err != nil {
 				return nil, nil, err
 			}
-			if len(elems) == 0 || len(tags) == 0 {
-				return nil, nil, fmt.Errorf("empty array %v: %w", lt, ErrUnconvertibleSchema)
+			valueElems, valueTags, err := arrowFieldToParquetSchemaInfo(value, mapValuePath(path), o)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			// map group
+			numMapChildren := int32(1)
+			mapElem := &parquet.SchemaElement{
+				Name:           f.Name,
+				NumChildren:    &numMapChildren,
+				ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_MAP),
+				RepetitionType: arrowNullableToParquetRepetitionType(f.Nullable),
+			}
+			if !o.LegacyTypes {
+				mapElem.LogicalType = &parquet.LogicalType{MAP: parquet.NewMapType()}
+			}
+			mapTag := &common.Tag{
+				ExName: mapElem.GetName(),
+				InName: common.HeadToUpper(mapElem.GetName()),
+				Type:   "", // empty string indicates group type
+			}
+
+			// repeated key value pairs under the map
+			numKeyValueChildren := int32(2)
+			keyValueElem := &parquet.SchemaElement{
+				Name:           "key_value",
+				NumChildren:    &numKeyValueChildren,
+				ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_MAP_KEY_VALUE),
+				RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+			}
+			keyValueTag := &common.Tag{
+				ExName: keyValueElem.GetName(),
+				InName: common.HeadToUpper(keyValueElem.GetName()),
+				Type:   "",
 			}
 
-			// Mark item type is repeated
-			elems[0].RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED)
+			elems := []*parquet.SchemaElement{mapElem, keyValueElem}
+			elems = append(elems, keyElems...)
+			elems = append(elems, valueElems...)
+			tags := []*common.Tag{mapTag, keyValueTag}
+			tags = append(tags, keyTags...)
+			tags = append(tags, valueTags...)
 
 			return elems, tags, nil
 		}
 	}
 
-	// logical types
-	if tns, ok := arrowToParquetConvertedType[f.Type]; ok {
-		e := &parquet.SchemaElement{
-			Type:           tns.t,
-			Name:           f.Name,
-			ConvertedType:  tns.ct,
-			RepetitionType: arrowNullableToParquetRepetitionType(f.Nullable),
+	return nil, nil, fmt.Errorf("unsupported arrow schema %v: %w", f, ErrUnconvertibleSchema)
+}
+
+// arrowListToParquetSchemaInfo converts a list to the three-level encoding, <name> (LIST) { repeated group list { element } }.
+// Elements are nullable by the metadata of the field regardless of the list, so required lists can have null elements.
+func arrowListToParquetSchemaInfo(f arrow.Field, lt *arrow.ListType, path string, o ParquetOptions) ([]*parquet.SchemaElement, []*common.Tag, error) {
+	item := listElementField(f, lt, "element")
+
+	itemElems, itemTags, err := arrowFieldToParquetSchemaInfo(item, listElementPath(path, o), o)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(itemElems) == 0 || len(itemTags) == 0 {
+		return nil, nil, fmt.Errorf("empty array %v: %w", lt, ErrUnconvertibleSchema)
+	}
+
+	// list group
+	numListChildren := int32(1)
+	listElem := &parquet.SchemaElement{
+		Name:           f.Name,
+		NumChildren:    &numListChildren,
+		ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_LIST),
+		RepetitionType: arrowNullableToParquetRepetitionType(f.Nullable),
+	}
+	if !o.LegacyTypes {
+		listElem.LogicalType = &parquet.LogicalType{LIST: parquet.NewListType()}
+	}
+	listTag := &common.Tag{
+		ExName: listElem.GetName(),
+		InName: common.HeadToUpper(listElem.GetName()),
+		Type:   "", // empty string indicates group type
+	}
+
+	// repeated group wrapping elements
+	numRepeatedChildren := int32(1)
+	repeatedElem := &parquet.SchemaElement{
+		Name:           "list",
+		NumChildren:    &numRepeatedChildren,
+		RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+	}
+	repeatedTag := &common.Tag{
+		ExName: repeatedElem.GetName(),
+		InName: common.HeadToUpper(repeatedElem.GetName()),
+		Type:   "",
+	}
+
+	elems := []*parquet.SchemaElement{listElem, repeatedElem}
+	elems = append(elems, itemElems...)
+	tags := []*common.Tag{listTag, repeatedTag}
+	tags = append(tags, itemTags...)
+
+	return elems, tags, nil
+}
+
+// arrowListToLegacyParquetSchemaInfo converts a list to a repeated field of the item type.
+func arrowListToLegacyParquetSchemaInfo(f arrow.Field, lt *arrow.ListType, path string, o ParquetOptions) ([]*parquet.SchemaElement, []*common.Tag, error) {
+	// elements of repeated fields can't be null
+	item := listElementField(f, lt, f.Name)
+	item.Nullable = false
+
+	elems, tags, err := arrowFieldToParquetSchemaInfo(item, listElementPath(path, o), o)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(elems) == 0 || len(tags) == 0 {
+		return nil, nil, fmt.Errorf("empty array %v: %w", lt, ErrUnconvertibleSchema)
+	}
+
+	// Mark item type is repeated
+	elems[0].RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED)
+
+	return elems, tags, nil
+}
+
+// listElementPath returns the column path of list elements.
+func listElementPath(path string, o ParquetOptions) string {
+	if o.LegacyLists {
+		return path
+	}
+
+	return path + ".list.element"
+}
+
+// mapValuePath returns the column path of map values.
+func mapValuePath(path string) string {
+	return path + ".key_value.value"
+}
+
+// Int96TimestampUnits returns units of timestamps written as INT96 keyed by column paths,
+// which the writer needs to convert epoch values. It fails if given columns have no timestamps.
+func Int96TimestampUnits(s IntermediateSchema, o ParquetOptions) (map[string]time.Duration, error) {
+	units := make(map[string]time.Duration)
+	for _, f := range s.ArrowSchema.Fields() {
+		collectInt96TimestampUnits(f.Type, f.Name, o, units)
+	}
+
+	for _, p := range o.Int96TimestampColumns {
+		found := false
+		for path := range units {
+			if p == path || strings.HasPrefix(path, p+".") {
+				found = true
+				break
+			}
 		}
-		tag := &common.Tag{
-			ExName: e.GetName(),
-			InName: common.HeadToUpper(e.GetName()),
-			Type:   tns.t.String(),
+		if !found {
+			return nil, fmt.Errorf("column %s has no timestamps: %w", p, ErrUnconvertibleSchema)
 		}
-		return []*parquet.SchemaElement{e}, []*common.Tag{tag}, nil
 	}
 
-	return nil, nil, fmt.Errorf("unsupported arrow schema %v: %w", f, ErrUnconvertibleSchema)
+	return units, nil
+}
+
+func collectInt96TimestampUnits(t arrow.DataType, path string, o ParquetOptions, units map[string]time.Duration) {
+	switch tt := t.(type) {
+	case *arrow.TimestampType:
+		if o.isInt96Timestamp(path) {
+			units[path] = arrowTimeUnitToDuration(tt.Unit)
+		}
+	case *arrow.StructType:
+		for _, f := range tt.Fields() {
+			collectInt96TimestampUnits(f.Type, path+"."+f.Name, o, units)
+		}
+	case *arrow.ListType:
+		collectInt96TimestampUnits(tt.Elem(), listElementPath(path, o), o, units)
+	case *MapType:
+		collectInt96TimestampUnits(tt.ValueType(), mapValuePath(path), o, units)
+	}
 }
 
 func arrowNullableToParquetRepetitionType(nullable bool) *parquet.FieldRepetitionType {
@@ -188,3 +338,158 @@ func arrowNullableToParquetRepetitionType(nullable bool) *parquet.FieldRepetitio
 		return parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED)
 	}
 }
+
+// arrowTypeToParquetElement returns the schema element of a leaf column with the physical type and annotations.
+// Both of ConvertedType and LogicalType are set if they can express the type.
+func arrowTypeToParquetElement(t arrow.DataType, path string, o ParquetOptions) (*parquet.SchemaElement, bool) {
+	var e *parquet.SchemaElement
+	switch tt := t.(type) {
+	case *arrow.BooleanType:
+		e = newParquetElement(parquet.Type_BOOLEAN, nil, nil)
+	case *arrow.Int8Type:
+		e = newParquetElement(parquet.Type_INT32, parquet.ConvertedTypePtr(parquet.ConvertedType_INT_8), newIntLogicalType(8, true))
+	case *arrow.Int16Type:
+		e = newParquetElement(parquet.Type_INT32, parquet.ConvertedTypePtr(parquet.ConvertedType_INT_16), newIntLogicalType(16, true))
+	case *arrow.Int32Type:
+		e = newParquetElement(parquet.Type_INT32, parquet.ConvertedTypePtr(parquet.ConvertedType_INT_32), newIntLogicalType(32, true))
+	case *arrow.Int64Type:
+		e = newParquetElement(parquet.Type_INT64, parquet.ConvertedTypePtr(parquet.ConvertedType_INT_64), newIntLogicalType(64, true))
+	case *arrow.Uint8Type:
+		e = newParquetElement(parquet.Type_INT32, parquet.ConvertedTypePtr(parquet.ConvertedType_UINT_8), newIntLogicalType(8, false))
+	case *arrow.Uint16Type:
+		e = newParquetElement(parquet.Type_INT32, parquet.ConvertedTypePtr(parquet.ConvertedType_UINT_16), newIntLogicalType(16, false))
+	case *arrow.Uint32Type:
+		// Avro int and BigQuery INTEGER are held as unsigned types but they're signed, so no annotations
+		e = newParquetElement(parquet.Type_INT32, nil, nil)
+	case *arrow.Uint64Type:
+		e = newParquetElement(parquet.Type_INT64, nil, nil)
+	case *arrow.Float32Type:
+		e = newParquetElement(parquet.Type_FLOAT, nil, nil)
+	case *arrow.Float64Type:
+		e = newParquetElement(parquet.Type_DOUBLE, nil, nil)
+	case *arrow.BinaryType:
+		e = newParquetElement(parquet.Type_BYTE_ARRAY, nil, nil)
+	case *arrow.StringType:
+		e = newParquetElement(parquet.Type_BYTE_ARRAY, parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8), &parquet.LogicalType{STRING: parquet.NewStringType()})
+	case *JSONType:
+		e = newParquetElement(parquet.Type_BYTE_ARRAY, parquet.ConvertedTypePtr(parquet.ConvertedType_JSON), &parquet.LogicalType{JSON: parquet.NewJsonType()})
+	case *EnumType:
+		e = newParquetElement(parquet.Type_BYTE_ARRAY, parquet.ConvertedTypePtr(parquet.ConvertedType_ENUM), &parquet.LogicalType{ENUM: parquet.NewEnumType()})
+	case *GeographyType, *IntervalStringType:
+		e = newParquetElement(parquet.Type_BYTE_ARRAY, parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8), &parquet.LogicalType{STRING: parquet.NewStringType()})
+	case *arrow.Decimal128Type:
+		e = newDecimalElement(tt.Precision, tt.Scale)
+	case *Decimal256Type:
+		e = newDecimalElement(tt.Precision, tt.Scale)
+	case *IntervalType:
+		// LogicalType doesn't have intervals
+		e = newParquetElement(parquet.Type_FIXED_LEN_BYTE_ARRAY, parquet.ConvertedTypePtr(parquet.ConvertedType_INTERVAL), nil)
+		e.TypeLength = int32ToPtr(12)
+	case *UUIDType:
+		e = newParquetElement(parquet.Type_FIXED_LEN_BYTE_ARRAY, nil, &parquet.LogicalType{UUID: parquet.NewUUIDType()})
+		e.TypeLength = int32ToPtr(16)
+	case *arrow.Date32Type:
+		e = newParquetElement(parquet.Type_INT32, parquet.ConvertedTypePtr(parquet.ConvertedType_DATE), &parquet.LogicalType{DATE: parquet.NewDateType()})
+	case *arrow.Time32Type:
+		if tt.Unit != arrow.Millisecond {
+			return nil, false
+		}
+		e = newParquetElement(parquet.Type_INT32, parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MILLIS), newTimeLogicalType(tt.Unit, true))
+	case *arrow.Time64Type:
+		var ct *parquet.ConvertedType
+		if tt.Unit == arrow.Microsecond {
+			ct = parquet.ConvertedTypePtr(parquet.ConvertedType_TIME_MICROS)
+		}
+		e = newParquetElement(parquet.Type_INT64, ct, newTimeLogicalType(tt.Unit, true))
+	case *arrow.TimestampType:
+		if o.isInt96Timestamp(path) {
+			// the deprecated encoding has no annotations
+			e = newParquetElement(parquet.Type_INT96, nil, nil)
+			break
+		}
+		if tt.Unit == arrow.Second {
+			return nil, false
+		}
+		var ct *parquet.ConvertedType
+		switch tt.Unit {
+		case arrow.Millisecond:
+			ct = parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS)
+		case arrow.Microsecond:
+			ct = parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS)
+		}
+		// timestamps without time zones are local, which converted types imply UTC can't express
+		adjustedToUTC := tt.TimeZone != ""
+		if !adjustedToUTC && !o.LegacyTypes {
+			ct = nil
+		}
+		e = newParquetElement(parquet.Type_INT64, ct, &parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{
+			IsAdjustedToUTC: adjustedToUTC,
+			Unit:            arrowTimeUnitToParquet(tt.Unit),
+		}})
+	default:
+		return nil, false
+	}
+
+	if o.LegacyTypes {
+		e.LogicalType = nil
+	}
+
+	return e, true
+}
+
+func newParquetElement(t parquet.Type, ct *parquet.ConvertedType, lt *parquet.LogicalType) *parquet.SchemaElement {
+	return &parquet.SchemaElement{
+		Type:          parquet.TypePtr(t),
+		ConvertedType: ct,
+		LogicalType:   lt,
+	}
+}
+
+// newDecimalElement returns a decimal in FIXED_LEN_BYTE_ARRAY of the minimum length for the precision.
+func newDecimalElement(precision, scale int32) *parquet.SchemaElement {
+	e := newParquetElement(parquet.Type_FIXED_LEN_BYTE_ARRAY, parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL), &parquet.LogicalType{
+		DECIMAL: &parquet.DecimalType{Scale: scale, Precision: precision},
+	})
+	e.TypeLength = int32ToPtr(int32(math.Ceil((float64(precision)*math.Log2(10) + 1) / 8)))
+	e.Precision = int32ToPtr(precision)
+	e.Scale = int32ToPtr(scale)
+
+	return e
+}
+
+func newIntLogicalType(bitWidth int8, signed bool) *parquet.LogicalType {
+	return &parquet.LogicalType{INTEGER: &parquet.IntType{BitWidth: bitWidth, IsSigned: signed}}
+}
+
+func newTimeLogicalType(unit arrow.TimeUnit, adjustedToUTC bool) *parquet.LogicalType {
+	return &parquet.LogicalType{TIME: &parquet.TimeType{
+		IsAdjustedToUTC: adjustedToUTC,
+		Unit:            arrowTimeUnitToParquet(unit),
+	}}
+}
+
+func arrowTimeUnitToParquet(unit arrow.TimeUnit) *parquet.TimeUnit {
+	switch unit {
+	case arrow.Millisecond:
+		return &parquet.TimeUnit{MILLIS: parquet.NewMilliSeconds()}
+	case arrow.Microsecond:
+		return &parquet.TimeUnit{MICROS: parquet.NewMicroSeconds()}
+	}
+
+	return &parquet.TimeUnit{NANOS: parquet.NewNanoSeconds()}
+}
+
+func arrowTimeUnitToDuration(unit arrow.TimeUnit) time.Duration {
+	switch unit {
+	case arrow.Second:
+		return time.Second
+	case arrow.Millisecond:
+		return time.Millisecond
+	case arrow.Microsecond:
+		return time.Microsecond
+	}
+
+	return time.Nanosecond
+}
+
+func int32ToPtr(v int32) *int32 { return &v }
@@ -1,19 +1,21 @@
 package schema
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/apache/arrow/go/arrow"
 	"github.com/xitongsys/parquet-go/parquet"
 	"github.com/xitongsys/parquet-go/schema"
 )
 
-func int32ToPtr(v int32) *int32 { return &v }
-
 func TestNewSchemaHandlerFromArrow(t *testing.T) {
 	cases := []struct {
 		intermediate *IntermediateSchema
+		options      ParquetOptions
 		expected     schema.SchemaHandler
 		err          error
 	}{
@@ -100,6 +102,7 @@ func TestNewSchemaHandlerFromArrow(t *testing.T) {
 						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
 						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
 						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8),
+						LogicalType:    &parquet.LogicalType{STRING: parquet.NewStringType()},
 						Name:           "string",
 					},
 				},
@@ -107,6 +110,190 @@ func TestNewSchemaHandlerFromArrow(t *testing.T) {
 			err: nil,
 		},
 
+		// logical types
+		{
+			intermediate: NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "int8", Type: arrow.PrimitiveTypes.Int8},
+						{Name: "uint16", Type: arrow.PrimitiveTypes.Uint16},
+						{Name: "date", Type: arrow.FixedWidthTypes.Date32},
+						{Name: "time_nanos", Type: arrow.FixedWidthTypes.Time64ns},
+						{Name: "timestamp_millis", Type: arrow.FixedWidthTypes.Timestamp_ms},
+						{Name: "local_timestamp_micros", Type: &arrow.TimestampType{Unit: arrow.Microsecond}},
+						{Name: "timestamp_nanos", Type: arrow.FixedWidthTypes.Timestamp_ns},
+						{Name: "json", Type: &JSONType{}},
+						{Name: "enum", Type: EnumOf("A", "B")},
+						{Name: "uuid", Type: &UUIDType{}},
+					}, nil),
+				"logicals"),
+			expected: schema.SchemaHandler{
+				SchemaElements: []*parquet.SchemaElement{
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "logicals",
+						NumChildren:    int32ToPtr(10),
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT32),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_INT_8),
+						LogicalType:    &parquet.LogicalType{INTEGER: &parquet.IntType{BitWidth: 8, IsSigned: true}},
+						Name:           "int8",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT32),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_UINT_16),
+						LogicalType:    &parquet.LogicalType{INTEGER: &parquet.IntType{BitWidth: 16, IsSigned: false}},
+						Name:           "uint16",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT32),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_DATE),
+						LogicalType:    &parquet.LogicalType{DATE: parquet.NewDateType()},
+						Name:           "date",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT64),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						LogicalType:    &parquet.LogicalType{TIME: &parquet.TimeType{IsAdjustedToUTC: true, Unit: &parquet.TimeUnit{NANOS: parquet.NewNanoSeconds()}}},
+						Name:           "time_nanos",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT64),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS),
+						LogicalType:    &parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{IsAdjustedToUTC: true, Unit: &parquet.TimeUnit{MILLIS: parquet.NewMilliSeconds()}}},
+						Name:           "timestamp_millis",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT64),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						LogicalType:    &parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{IsAdjustedToUTC: false, Unit: &parquet.TimeUnit{MICROS: parquet.NewMicroSeconds()}}},
+						Name:           "local_timestamp_micros",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT64),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						LogicalType:    &parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{IsAdjustedToUTC: true, Unit: &parquet.TimeUnit{NANOS: parquet.NewNanoSeconds()}}},
+						Name:           "timestamp_nanos",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_JSON),
+						LogicalType:    &parquet.LogicalType{JSON: parquet.NewJsonType()},
+						Name:           "json",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_ENUM),
+						LogicalType:    &parquet.LogicalType{ENUM: parquet.NewEnumType()},
+						Name:           "enum",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						TypeLength:     int32ToPtr(16),
+						LogicalType:    &parquet.LogicalType{UUID: parquet.NewUUIDType()},
+						Name:           "uuid",
+					},
+				},
+			},
+			err: nil,
+		},
+
+		// logical types only with converted types for old readers
+		{
+			intermediate: NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{Name: "int8", Type: arrow.PrimitiveTypes.Int8},
+						{Name: "uint16", Type: arrow.PrimitiveTypes.Uint16},
+						{Name: "date", Type: arrow.FixedWidthTypes.Date32},
+						{Name: "time_nanos", Type: arrow.FixedWidthTypes.Time64ns},
+						{Name: "timestamp_millis", Type: arrow.FixedWidthTypes.Timestamp_ms},
+						{Name: "local_timestamp_micros", Type: &arrow.TimestampType{Unit: arrow.Microsecond}},
+						{Name: "timestamp_nanos", Type: arrow.FixedWidthTypes.Timestamp_ns},
+						{Name: "json", Type: &JSONType{}},
+						{Name: "enum", Type: EnumOf("A", "B")},
+						{Name: "uuid", Type: &UUIDType{}},
+					}, nil),
+				"logicals"),
+			options: ParquetOptions{LegacyTypes: true},
+			expected: schema.SchemaHandler{
+				SchemaElements: []*parquet.SchemaElement{
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "logicals",
+						NumChildren:    int32ToPtr(10),
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT32),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_INT_8),
+						Name:           "int8",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT32),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_UINT_16),
+						Name:           "uint16",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT32),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_DATE),
+						Name:           "date",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT64),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "time_nanos",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT64),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS),
+						Name:           "timestamp_millis",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT64),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MICROS),
+						Name:           "local_timestamp_micros",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT64),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "timestamp_nanos",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_JSON),
+						Name:           "json",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_ENUM),
+						Name:           "enum",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						TypeLength:     int32ToPtr(16),
+						Name:           "uuid",
+					},
+				},
+			},
+			err: nil,
+		},
+
 		// Nested types
 		{
 			intermediate: NewIntermediateSchema(
@@ -233,6 +420,7 @@ func TestNewSchemaHandlerFromArrow(t *testing.T) {
 						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
 						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
 						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8),
+						LogicalType:    &parquet.LogicalType{STRING: parquet.NewStringType()},
 						Name:           "string",
 					},
 					{
@@ -274,6 +462,7 @@ func TestNewSchemaHandlerFromArrow(t *testing.T) {
 						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
 						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
 						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8),
+						LogicalType:    &parquet.LogicalType{STRING: parquet.NewStringType()},
 						Name:           "string",
 					},
 				},
@@ -408,11 +597,24 @@ func TestNewSchemaHandlerFromArrow(t *testing.T) {
 						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
 						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
 						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8),
+						LogicalType:    &parquet.LogicalType{STRING: parquet.NewStringType()},
 						Name:           "string",
 					},
 					{
-						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
 						Name:           "array",
+						NumChildren:    int32ToPtr(1),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_LIST),
+						LogicalType:    &parquet.LogicalType{LIST: parquet.NewListType()},
+					},
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+						Name:           "list",
+						NumChildren:    int32ToPtr(1),
+					},
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "element",
 						NumChildren:    int32ToPtr(7),
 					},
 					{
@@ -449,16 +651,344 @@ func TestNewSchemaHandlerFromArrow(t *testing.T) {
 						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
 						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
 						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8),
+						LogicalType:    &parquet.LogicalType{STRING: parquet.NewStringType()},
 						Name:           "string",
 					},
 				},
 			},
 			err: nil,
 		},
+
+		// optional list of required elements
+		{
+			intermediate: NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{
+							Name:     "tags",
+							Type:     arrow.ListOf(arrow.BinaryTypes.String),
+							Nullable: true,
+						},
+					}, nil),
+				"arrays"),
+			expected: schema.SchemaHandler{
+				SchemaElements: []*parquet.SchemaElement{
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "arrays",
+						NumChildren:    int32ToPtr(1),
+					},
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL),
+						Name:           "tags",
+						NumChildren:    int32ToPtr(1),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_LIST),
+						LogicalType:    &parquet.LogicalType{LIST: parquet.NewListType()},
+					},
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+						Name:           "list",
+						NumChildren:    int32ToPtr(1),
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8),
+						LogicalType:    &parquet.LogicalType{STRING: parquet.NewStringType()},
+						Name:           "element",
+					},
+				},
+			},
+			err: nil,
+		},
+
+		// required list of nullable elements
+		{
+			intermediate: NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{
+							Name:     "tags",
+							Type:     arrow.ListOf(arrow.BinaryTypes.String),
+							Nullable: false,
+							Metadata: arrow.NewMetadata([]string{ElementNullableMetadataKey}, []string{"true"}),
+						},
+					}, nil),
+				"arrays"),
+			expected: schema.SchemaHandler{
+				SchemaElements: []*parquet.SchemaElement{
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "arrays",
+						NumChildren:    int32ToPtr(1),
+					},
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "tags",
+						NumChildren:    int32ToPtr(1),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_LIST),
+						LogicalType:    &parquet.LogicalType{LIST: parquet.NewListType()},
+					},
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+						Name:           "list",
+						NumChildren:    int32ToPtr(1),
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8),
+						LogicalType:    &parquet.LogicalType{STRING: parquet.NewStringType()},
+						Name:           "element",
+					},
+				},
+			},
+			err: nil,
+		},
+
+		// list of lists of nullable elements
+		{
+			intermediate: NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{
+							Name:     "matrix",
+							Type:     arrow.ListOf(arrow.ListOf(arrow.PrimitiveTypes.Int64)),
+							Nullable: false,
+							Metadata: arrow.NewMetadata([]string{ElementNullableMetadataKey}, []string{"false,true"}),
+						},
+					}, nil),
+				"arrays"),
+			expected: schema.SchemaHandler{
+				SchemaElements: []*parquet.SchemaElement{
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "arrays",
+						NumChildren:    int32ToPtr(1),
+					},
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "matrix",
+						NumChildren:    int32ToPtr(1),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_LIST),
+						LogicalType:    &parquet.LogicalType{LIST: parquet.NewListType()},
+					},
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+						Name:           "list",
+						NumChildren:    int32ToPtr(1),
+					},
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "element",
+						NumChildren:    int32ToPtr(1),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_LIST),
+						LogicalType:    &parquet.LogicalType{LIST: parquet.NewListType()},
+					},
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+						Name:           "list",
+						NumChildren:    int32ToPtr(1),
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT64),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_INT_64),
+						LogicalType:    &parquet.LogicalType{INTEGER: &parquet.IntType{BitWidth: 64, IsSigned: true}},
+						Name:           "element",
+					},
+				},
+			},
+			err: nil,
+		},
+
+		// legacy two-level array
+		{
+			intermediate: NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{
+							Name:     "tags",
+							Type:     arrow.ListOf(arrow.BinaryTypes.String),
+							Nullable: true,
+						},
+					}, nil),
+				"arrays"),
+			options: ParquetOptions{LegacyLists: true},
+			expected: schema.SchemaHandler{
+				SchemaElements: []*parquet.SchemaElement{
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "arrays",
+						NumChildren:    int32ToPtr(1),
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8),
+						LogicalType:    &parquet.LogicalType{STRING: parquet.NewStringType()},
+						Name:           "tags",
+					},
+				},
+			},
+			err: nil,
+		},
+
+		// INT96 timestamps of given columns
+		{
+			intermediate: NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{
+							Name:     "created",
+							Type:     arrow.FixedWidthTypes.Timestamp_ms,
+							Nullable: false,
+						},
+						{
+							Name:     "updated",
+							Type:     arrow.FixedWidthTypes.Timestamp_us,
+							Nullable: false,
+						},
+					}, nil),
+				"timestamps"),
+			options: ParquetOptions{Int96TimestampColumns: []string{"updated"}},
+			expected: schema.SchemaHandler{
+				SchemaElements: []*parquet.SchemaElement{
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "timestamps",
+						NumChildren:    int32ToPtr(2),
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT64),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_TIMESTAMP_MILLIS),
+						LogicalType: &parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{
+							IsAdjustedToUTC: true,
+							Unit:            &parquet.TimeUnit{MILLIS: parquet.NewMilliSeconds()},
+						}},
+						Name: "created",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT96),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "updated",
+					},
+				},
+			},
+			err: nil,
+		},
+
+		// BigQuery types
+		{
+			intermediate: NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{
+							Name:     "geography",
+							Type:     &GeographyType{},
+							Nullable: false,
+						},
+						{
+							Name:     "bignumeric",
+							Type:     &Decimal256Type{Precision: 76, Scale: 38},
+							Nullable: false,
+						},
+						{
+							Name:     "interval",
+							Type:     &IntervalType{},
+							Nullable: true,
+						},
+					}, nil),
+				"bigquery"),
+			expected: schema.SchemaHandler{
+				SchemaElements: []*parquet.SchemaElement{
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "bigquery",
+						NumChildren:    int32ToPtr(3),
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8),
+						LogicalType:    &parquet.LogicalType{STRING: parquet.NewStringType()},
+						Name:           "geography",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY),
+						TypeLength:     int32ToPtr(32),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL),
+						LogicalType:    &parquet.LogicalType{DECIMAL: &parquet.DecimalType{Scale: 38, Precision: 76}},
+						Scale:          int32ToPtr(38),
+						Precision:      int32ToPtr(76),
+						Name:           "bignumeric",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_FIXED_LEN_BYTE_ARRAY),
+						TypeLength:     int32ToPtr(12),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_INTERVAL),
+						Name:           "interval",
+					},
+				},
+			},
+			err: nil,
+		},
+
+		// map type
+		{
+			intermediate: NewIntermediateSchema(
+				arrow.NewSchema(
+					[]arrow.Field{
+						{
+							Name:     "map",
+							Type:     MapOf(arrow.PrimitiveTypes.Uint64),
+							Nullable: true,
+						},
+					}, nil),
+				"maps"),
+			expected: schema.SchemaHandler{
+				SchemaElements: []*parquet.SchemaElement{
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "maps",
+						NumChildren:    int32ToPtr(1),
+					},
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL),
+						Name:           "map",
+						NumChildren:    int32ToPtr(1),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_MAP),
+						LogicalType:    &parquet.LogicalType{MAP: parquet.NewMapType()},
+					},
+					{
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REPEATED),
+						Name:           "key_value",
+						NumChildren:    int32ToPtr(2),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_MAP_KEY_VALUE),
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_BYTE_ARRAY),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						ConvertedType:  parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8),
+						LogicalType:    &parquet.LogicalType{STRING: parquet.NewStringType()},
+						Name:           "key",
+					},
+					{
+						Type:           parquet.TypePtr(parquet.Type_INT64),
+						RepetitionType: parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED),
+						Name:           "value",
+					},
+				},
+			},
+			err: nil,
+		},
 	}
 
 	for _, c := range cases {
-		actual, err := NewSchemaHandlerFromArrow(*c.intermediate)
+		actual, err := NewSchemaHandlerFromArrow(*c.intermediate, c.options)
 
 		if err != c.err {
 			t.Errorf("expected: %v, but actual: %v\n", c.err, err)
@@ -470,3 +1000,55 @@ func TestNewSchemaHandlerFromArrow(t *testing.T) {
 		}
 	}
 }
+
+func TestInt96TimestampUnits(t *testing.T) {
+	s := NewIntermediateSchema(
+		arrow.NewSchema(
+			[]arrow.Field{
+				{Name: "created", Type: arrow.FixedWidthTypes.Timestamp_ms},
+				{Name: "nested", Type: arrow.StructOf(arrow.Field{Name: "updated", Type: arrow.FixedWidthTypes.Timestamp_us})},
+				{Name: "history", Type: arrow.ListOf(arrow.FixedWidthTypes.Timestamp_ms)},
+				{Name: "id", Type: arrow.PrimitiveTypes.Uint64},
+			}, nil),
+		"timestamps")
+
+	cases := []struct {
+		options  ParquetOptions
+		expected map[string]time.Duration
+		err      error
+	}{
+		{
+			options: ParquetOptions{Int96Timestamps: true},
+			expected: map[string]time.Duration{
+				"created":              time.Millisecond,
+				"nested.updated":       time.Microsecond,
+				"history.list.element": time.Millisecond,
+			},
+		},
+		// group paths select timestamps under them
+		{
+			options:  ParquetOptions{Int96TimestampColumns: []string{"nested", "history"}, LegacyLists: true},
+			expected: map[string]time.Duration{"nested.updated": time.Microsecond, "history": time.Millisecond},
+		},
+		{
+			options:  ParquetOptions{},
+			expected: map[string]time.Duration{},
+		},
+		// no timestamps
+		{
+			options: ParquetOptions{Int96TimestampColumns: []string{"id"}},
+			err:     ErrUnconvertibleSchema,
+		},
+	}
+
+	for _, c := range cases {
+		actual, err := Int96TimestampUnits(*s, c.options)
+
+		if !errors.Is(err, c.err) {
+			t.Errorf("expected: %v, but actual: %v\n", c.err, err)
+		}
+		if err == nil && fmt.Sprint(actual) != fmt.Sprint(c.expected) {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
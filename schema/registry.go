@@ -0,0 +1,191 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RegistryVersionLatest = "latest"
+
+	registryContentType = "application/vnd.schemaregistry.v1+json"
+	registryTimeout     = 30 * time.Second
+)
+
+var (
+	ErrSchemaNotFound = errors.New("schema not found")
+	// ErrRegistryUnavailable is returned on transport errors and 5xx responses, which the cache of latest schemas covers.
+	ErrRegistryUnavailable = errors.New("schema registry is unavailable")
+)
+
+// registrySchemaResponse is a part of the response of Confluent-compatible schema registry REST API.
+// https://docs.confluent.io/platform/current/schema-registry/develop/api.html
+type registrySchemaResponse struct {
+	Subject    string `json:"subject"`
+	Id         int    `json:"id"`
+	Version    int    `json:"version"`
+	SchemaType string `json:"schemaType"`
+	Schema     string `json:"schema"`
+}
+
+// RegistryClient fetches schemas from a Confluent-compatible schema registry.
+// Fetched schemas are cached on disk if CacheDir is given, so that reruns can work offline.
+type RegistryClient struct {
+	URL      string
+	CacheDir string
+	client   *http.Client
+}
+
+func NewRegistryClient(registryURL string, cacheDir string) *RegistryClient {
+	return &RegistryClient{
+		URL:      strings.TrimSuffix(registryURL, "/"),
+		CacheDir: cacheDir,
+		client: &http.Client{
+			Timeout: registryTimeout,
+		},
+	}
+}
+
+// GetBySubject fetches the schema registered as the subject/version.
+// The latest version is always fetched from the registry, and the cache is used only when it's unavailable.
+func (c *RegistryClient) GetBySubject(subject string, version string) ([]byte, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("empty subject: %w", ErrInvalidSchema)
+	}
+	if version == "" {
+		version = RegistryVersionLatest
+	}
+	if version != RegistryVersionLatest {
+		if _, err := strconv.Atoi(version); err != nil {
+			return nil, fmt.Errorf("invalid version %s: %w", version, ErrInvalidSchema)
+		}
+	}
+
+	cachePath := c.cachePath("subjects", subject, version)
+	path := fmt.Sprintf("/subjects/%s/versions/%s", escapePathSegment(subject), version)
+
+	if version == RegistryVersionLatest {
+		content, err := c.fetch(path)
+		if err == nil {
+			return content, c.writeCache(cachePath, content)
+		}
+		if !errors.Is(err, ErrRegistryUnavailable) {
+			return nil, err
+		}
+		if cached, cerr := c.readCache(cachePath); cerr == nil {
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	return c.getImmutable(path, cachePath)
+}
+
+// GetById fetches the schema by the globally unique schema id.
+func (c *RegistryClient) GetById(id int) ([]byte, error) {
+	return c.getImmutable(fmt.Sprintf("/schemas/ids/%d", id), c.cachePath("ids", strconv.Itoa(id)))
+}
+
+// getImmutable looks up the cache first because the content never changes once registered.
+func (c *RegistryClient) getImmutable(path string, cachePath string) ([]byte, error) {
+	if cached, err := c.readCache(cachePath); err == nil {
+		return cached, nil
+	}
+
+	content, err := c.fetch(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return content, c.writeCache(cachePath, content)
+}
+
+func (c *RegistryClient) fetch(path string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, c.URL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", registryContentType)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrRegistryUnavailable)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrRegistryUnavailable)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", path, ErrSchemaNotFound)
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("schema registry responds %d for %s: %s: %w", resp.StatusCode, path, string(body), ErrRegistryUnavailable)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("schema registry responds %d for %s: %s", resp.StatusCode, path, string(body))
+	}
+
+	var r registrySchemaResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("unexpected response %v: %w", err, ErrInvalidSchema)
+	}
+	if r.SchemaType != "" && r.SchemaType != "AVRO" {
+		return nil, fmt.Errorf("schema type %s: %w", r.SchemaType, ErrUnsupportedSchema)
+	}
+
+	return []byte(r.Schema), nil
+}
+
+func (c *RegistryClient) cachePath(elems ...string) string {
+	if c.CacheDir == "" {
+		return ""
+	}
+
+	for i, e := range elems {
+		elems[i] = escapePathSegment(e)
+	}
+
+	return filepath.Join(c.CacheDir, filepath.Join(elems...)+".avsc")
+}
+
+// escapePathSegment escapes a path segment including dots of "." and "..", which url.PathEscape leaves as they are
+// but refer to the current and parent directories.
+func escapePathSegment(s string) string {
+	if s != "" && strings.Trim(s, ".") == "" {
+		return strings.ReplaceAll(s, ".", "%2E")
+	}
+
+	return url.PathEscape(s)
+}
+
+func (c *RegistryClient) readCache(path string) ([]byte, error) {
+	if path == "" {
+		return nil, ErrSchemaNotFound
+	}
+
+	return os.ReadFile(path)
+}
+
+func (c *RegistryClient) writeCache(path string, content []byte) error {
+	if path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,223 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+const testRegistrySchema = `{"type":"record","name":"Primitives","fields":[{"name":"int","type":"int"}]}`
+
+func newTestRegistryServer(t *testing.T, requests *int) *httptest.Server {
+	responses := map[string]registrySchemaResponse{
+		"/subjects/primitives-value/versions/1":      {Subject: "primitives-value", Id: 42, Version: 1, Schema: testRegistrySchema},
+		"/subjects/primitives-value/versions/latest": {Subject: "primitives-value", Id: 42, Version: 1, Schema: testRegistrySchema},
+		"/schemas/ids/42":                            {Schema: testRegistrySchema},
+		"/schemas/ids/43":                            {SchemaType: "PROTOBUF", Schema: "syntax = \"proto3\";"},
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40401,"message":"Subject not found."}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", registryContentType)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatal(err)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestRegistryClient_GetBySubject(t *testing.T) {
+	cases := []struct {
+		subject string
+		version string
+		isErr   error
+	}{
+		{
+			subject: "primitives-value",
+			version: "1",
+			isErr:   nil,
+		},
+		{
+			subject: "primitives-value",
+			version: "",
+			isErr:   nil,
+		},
+		{
+			subject: "unknown-value",
+			version: "latest",
+			isErr:   ErrSchemaNotFound,
+		},
+		{
+			subject: "primitives-value",
+			version: "first",
+			isErr:   ErrInvalidSchema,
+		},
+		{
+			subject: "",
+			version: "latest",
+			isErr:   ErrInvalidSchema,
+		},
+	}
+
+	var requests int
+	s := newTestRegistryServer(t, &requests)
+
+	for _, c := range cases {
+		client := NewRegistryClient(s.URL+"/", "")
+		actual, err := client.GetBySubject(c.subject, c.version)
+
+		if !errors.Is(err, c.isErr) {
+			t.Errorf("expected %v, but actual %v", c.isErr, err)
+		}
+
+		if c.isErr == nil && string(actual) != testRegistrySchema {
+			t.Errorf("expected %v, but actual %v", testRegistrySchema, string(actual))
+		}
+	}
+}
+
+func TestRegistryClient_GetById(t *testing.T) {
+	cases := []struct {
+		id    int
+		isErr error
+	}{
+		{
+			id:    42,
+			isErr: nil,
+		},
+		{
+			id:    43,
+			isErr: ErrUnsupportedSchema,
+		},
+		{
+			id:    44,
+			isErr: ErrSchemaNotFound,
+		},
+	}
+
+	var requests int
+	s := newTestRegistryServer(t, &requests)
+
+	for _, c := range cases {
+		client := NewRegistryClient(s.URL, "")
+		actual, err := client.GetById(c.id)
+
+		if !errors.Is(err, c.isErr) {
+			t.Errorf("expected %v, but actual %v", c.isErr, err)
+		}
+
+		if c.isErr == nil && string(actual) != testRegistrySchema {
+			t.Errorf("expected %v, but actual %v", testRegistrySchema, string(actual))
+		}
+	}
+}
+
+func TestRegistryClient_Cache(t *testing.T) {
+	var requests int
+	s := newTestRegistryServer(t, &requests)
+	cacheDir := t.TempDir()
+
+	online := NewRegistryClient(s.URL, cacheDir)
+	if _, err := online.GetById(42); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := online.GetBySubject("primitives-value", "latest"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Immutable ones don't request again
+	if _, err := online.GetById(42); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("expected %v, but actual %v", 2, requests)
+	}
+
+	// Offline reruns read cached ones
+	s.Close()
+	offline := NewRegistryClient(s.URL, cacheDir)
+	for _, get := range []func() ([]byte, error){
+		func() ([]byte, error) { return offline.GetById(42) },
+		func() ([]byte, error) { return offline.GetBySubject("primitives-value", "latest") },
+	} {
+		actual, err := get()
+		if err != nil {
+			t.Errorf("expected success, but actual %v", err)
+		}
+		if string(actual) != testRegistrySchema {
+			t.Errorf("expected %v, but actual %v", testRegistrySchema, string(actual))
+		}
+	}
+
+	if _, err := offline.GetBySubject("primitives-value", "1"); err == nil {
+		t.Errorf("expected error occurs, but actual it's nil")
+	}
+}
+
+func TestRegistryClient_Cache_Latest(t *testing.T) {
+	cases := []struct {
+		status int
+		isErr  bool
+	}{
+		{status: http.StatusServiceUnavailable, isErr: false},
+		// Errors except unavailability aren't covered by the cache
+		{status: http.StatusNotFound, isErr: true},
+		{status: http.StatusUnauthorized, isErr: true},
+	}
+
+	for _, c := range cases {
+		var requests int
+		cacheDir := t.TempDir()
+		if _, err := NewRegistryClient(newTestRegistryServer(t, &requests).URL, cacheDir).GetBySubject("primitives-value", "latest"); err != nil {
+			t.Fatal(err)
+		}
+
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(c.status)
+		}))
+		actual, err := NewRegistryClient(s.URL, cacheDir).GetBySubject("primitives-value", "latest")
+		s.Close()
+
+		if (err != nil) != c.isErr {
+			t.Errorf("expected %v, but actual %v", c.isErr, err)
+		}
+		if !c.isErr && string(actual) != testRegistrySchema {
+			t.Errorf("expected %v, but actual %v", testRegistrySchema, string(actual))
+		}
+	}
+}
+
+func TestRegistryClient_CachePath(t *testing.T) {
+	client := NewRegistryClient("http://localhost:8081", "cache")
+
+	cases := []struct {
+		subject  string
+		expected string
+	}{
+		{subject: "primitives-value", expected: filepath.Join("cache", "subjects", "primitives-value", "latest.avsc")},
+		{subject: "a/b", expected: filepath.Join("cache", "subjects", "a%2Fb", "latest.avsc")},
+		{subject: "..", expected: filepath.Join("cache", "subjects", "%2E%2E", "latest.avsc")},
+		{subject: ".", expected: filepath.Join("cache", "subjects", "%2E", "latest.avsc")},
+		{subject: "..a", expected: filepath.Join("cache", "subjects", "..a", "latest.avsc")},
+	}
+
+	for _, c := range cases {
+		actual := client.cachePath("subjects", c.subject, RegistryVersionLatest)
+		if actual != c.expected {
+			t.Errorf("expected: %v, but actual: %v\n", c.expected, actual)
+		}
+	}
+}
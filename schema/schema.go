@@ -10,6 +10,7 @@ import (
 const (
 	SchemaTypeAvro     = "avro"
 	SchemaTypeBigquery = "bigquery"
+	SchemaTypeSqlite   = "sqlite"
 )
 
 var (
@@ -0,0 +1,185 @@
+package schema
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/apache/arrow/go/arrow"
+
+	// pure Go SQLite driver, registered as "sqlite"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	SqliteDriverName = "sqlite"
+
+	sqliteQuerySchemaName = "Query"
+)
+
+// sqliteDeclaredTypesToArrow maps substrings of declared column types to arrow types, checked in order
+// like SQLite's type affinity rules. Temporal types are stored as texts or numbers in SQLite,
+// so they're checked before INT and the others.
+var sqliteDeclaredTypesToArrow = []struct {
+	substr string
+	t      arrow.DataType
+}{
+	{substr: "BOOL", t: arrow.FixedWidthTypes.Boolean},
+	{substr: "DATETIME", t: arrow.FixedWidthTypes.Timestamp_us},
+	{substr: "TIMESTAMP", t: arrow.FixedWidthTypes.Timestamp_us},
+	{substr: "DATE", t: arrow.FixedWidthTypes.Date32},
+	{substr: "TIME", t: arrow.FixedWidthTypes.Time64us},
+	{substr: "INT", t: arrow.PrimitiveTypes.Uint64},
+	{substr: "CHAR", t: arrow.BinaryTypes.String},
+	{substr: "CLOB", t: arrow.BinaryTypes.String},
+	{substr: "TEXT", t: arrow.BinaryTypes.String},
+	{substr: "BLOB", t: arrow.BinaryTypes.Binary},
+	{substr: "REAL", t: arrow.PrimitiveTypes.Float64},
+	{substr: "FLOA", t: arrow.PrimitiveTypes.Float64},
+	{substr: "DOUB", t: arrow.PrimitiveTypes.Float64},
+	{substr: "NUMERIC", t: arrow.PrimitiveTypes.Float64},
+	{substr: "DECIMAL", t: arrow.PrimitiveTypes.Float64},
+}
+
+// NewSchemaFromSqlite derives the schema from column declarations of the table or the query result.
+// Columns without declared types like expressions in the query are regarded as strings.
+func NewSchemaFromSqlite(path string, table string, query string) (*IntermediateSchema, error) {
+	db, err := OpenSqlite(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	q, table, err := SqliteSelectQuery(db, table, query)
+	if err != nil {
+		return nil, err
+	}
+
+	notNulls := make(map[string]bool)
+	if query == "" {
+		if notNulls, err = sqliteNotNullColumns(db, table); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := db.Query(q)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidSchema)
+	}
+	defer rows.Close()
+
+	cts, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]arrow.Field, 0, len(cts))
+	for _, ct := range cts {
+		fields = append(fields, arrow.Field{
+			Name:     ct.Name(),
+			Type:     sqliteDeclaredTypeToArrow(ct.DatabaseTypeName()),
+			Nullable: !notNulls[ct.Name()],
+		})
+	}
+
+	name := table
+	if query != "" {
+		name = sqliteQuerySchemaName
+	}
+
+	return NewIntermediateSchema(arrow.NewSchema(fields, nil), name), nil
+}
+
+// sqliteURIEscaper escapes characters which have meanings in SQLite URI filenames.
+var sqliteURIEscaper = strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23")
+
+// OpenSqlite opens the existing database in read-only mode. sql.Open creates missing files and opens them writable.
+func OpenSqlite(path string) (*sql.DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	return sql.Open(SqliteDriverName, "file:"+sqliteURIEscaper.Replace(path)+"?mode=ro")
+}
+
+// SqliteSelectQuery returns the query to read records, and the table name if the query is not given.
+// The table can be omitted if the database has only one table.
+func SqliteSelectQuery(db *sql.DB, table string, query string) (string, string, error) {
+	if query != "" {
+		return query, "", nil
+	}
+
+	if table == "" {
+		tables, err := sqliteTables(db)
+		if err != nil {
+			return "", "", err
+		}
+		if len(tables) != 1 {
+			return "", "", fmt.Errorf("table or query must be specified for tables %v: %w", tables, ErrInvalidSchema)
+		}
+		table = tables[0]
+	}
+
+	return fmt.Sprintf("SELECT * FROM %s", quoteSqliteIdentifier(table)), table, nil
+}
+
+func sqliteTables(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+
+	return tables, rows.Err()
+}
+
+func sqliteNotNullColumns(db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", quoteSqliteIdentifier(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notNulls := make(map[string]bool)
+	for rows.Next() {
+		var (
+			cid      int
+			name     string
+			declType string
+			notNull  bool
+			dflt     sql.NullString
+			pk       int
+		)
+		if err := rows.Scan(&cid, &name, &declType, &notNull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		notNulls[name] = notNull
+	}
+
+	return notNulls, rows.Err()
+}
+
+func sqliteDeclaredTypeToArrow(declType string) arrow.DataType {
+	upper := strings.ToUpper(declType)
+	for _, m := range sqliteDeclaredTypesToArrow {
+		if strings.Contains(upper, m.substr) {
+			return m.t
+		}
+	}
+
+	return arrow.BinaryTypes.String
+}
+
+func quoteSqliteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
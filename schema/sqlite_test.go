@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+)
+
+func newSqliteDatabase(t *testing.T, stmts ...string) string {
+	path := filepath.Join(t.TempDir(), "test.sqlite")
+
+	db, err := sql.Open(SqliteDriverName, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return path
+}
+
+func TestNewSchemaFromSqlite(t *testing.T) {
+	single := newSqliteDatabase(t,
+		`CREATE TABLE events (id INTEGER PRIMARY KEY NOT NULL, name VARCHAR(255) NOT NULL, payload BLOB, score DOUBLE PRECISION, ok BOOLEAN, day DATE, at DATETIME, memo)`,
+	)
+	multi := newSqliteDatabase(t,
+		`CREATE TABLE a (id INTEGER)`,
+		`CREATE TABLE b (id INTEGER, price NUMERIC(10, 2) NOT NULL)`,
+	)
+
+	cases := []struct {
+		path     string
+		table    string
+		query    string
+		expected *arrow.Schema
+		name     string
+		err      error
+	}{
+		// The only table
+		{
+			path:  single,
+			table: "",
+			query: "",
+			expected: arrow.NewSchema(
+				[]arrow.Field{
+					{Name: "id", Type: arrow.PrimitiveTypes.Uint64, Nullable: false},
+					{Name: "name", Type: arrow.BinaryTypes.String, Nullable: false},
+					{Name: "payload", Type: arrow.BinaryTypes.Binary, Nullable: true},
+					{Name: "score", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+					{Name: "ok", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+					{Name: "day", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+					{Name: "at", Type: arrow.FixedWidthTypes.Timestamp_us, Nullable: true},
+					{Name: "memo", Type: arrow.BinaryTypes.String, Nullable: true},
+				}, nil),
+			name: "events",
+			err:  nil,
+		},
+
+		// Specified table
+		{
+			path:  multi,
+			table: "b",
+			query: "",
+			expected: arrow.NewSchema(
+				[]arrow.Field{
+					{Name: "id", Type: arrow.PrimitiveTypes.Uint64, Nullable: true},
+					{Name: "price", Type: arrow.PrimitiveTypes.Float64, Nullable: false},
+				}, nil),
+			name: "b",
+			err:  nil,
+		},
+
+		// Query
+		{
+			path:  multi,
+			table: "",
+			query: "SELECT b.id, b.price * 2 AS doubled FROM b",
+			expected: arrow.NewSchema(
+				[]arrow.Field{
+					{Name: "id", Type: arrow.PrimitiveTypes.Uint64, Nullable: true},
+					{Name: "doubled", Type: arrow.BinaryTypes.String, Nullable: true},
+				}, nil),
+			name: "Query",
+			err:  nil,
+		},
+
+		// Ambiguous table
+		{
+			path:     multi,
+			table:    "",
+			query:    "",
+			expected: nil,
+			err:      ErrInvalidSchema,
+		},
+
+		// Unknown table
+		{
+			path:     multi,
+			table:    "c",
+			query:    "",
+			expected: nil,
+			err:      ErrInvalidSchema,
+		},
+	}
+
+	for _, c := range cases {
+		actual, err := NewSchemaFromSqlite(c.path, c.table, c.query)
+
+		if !errors.Is(err, c.err) {
+			t.Errorf("expected: %v, but actual: %v\n", c.err, err)
+			continue
+		}
+
+		if err == nil && (actual.ArrowSchema.String() != c.expected.String() || actual.Name != c.name) {
+			t.Errorf("expected: %v %v, but actual: %v %v\n", c.name, c.expected, actual.Name, actual.ArrowSchema)
+		}
+	}
+}
+
+func TestOpenSqlite(t *testing.T) {
+	path := newSqliteDatabase(t, `CREATE TABLE a (id INTEGER)`, `INSERT INTO a VALUES (1)`)
+
+	// URI characters in the path
+	escaped := filepath.Join(t.TempDir(), "test?#%.sqlite")
+	if err := os.Rename(path, escaped); err != nil {
+		t.Fatal(err)
+	}
+	db, err := OpenSqlite(escaped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var id int
+	if err := db.QueryRow("SELECT id FROM a").Scan(&id); err != nil || id != 1 {
+		t.Errorf("expected: %v, but actual: %v, %v\n", 1, id, err)
+	}
+
+	// read-only
+	if _, err := db.Exec("INSERT INTO a VALUES (2)"); err == nil {
+		t.Errorf("expected: %v, but actual: %v\n", "an error", err)
+	}
+
+	// missing files aren't created
+	missing := filepath.Join(t.TempDir(), "missing.sqlite")
+	if _, err := OpenSqlite(missing); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected: %v, but actual: %v\n", os.ErrNotExist, err)
+	}
+	if _, err := os.Stat(missing); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected: %v, but actual: %v\n", os.ErrNotExist, err)
+	}
+}